@@ -4,11 +4,43 @@ import (
 	"os"
 	"testing"
 
+	"completion_tracker/models"
+
 	"github.com/gobuffalo/suite/v4"
 )
 
 type ActionSuite struct {
 	*suite.Action
+
+	// user is signed in for every test.
+	user *models.User
+}
+
+// testPassword is the password of the users the tests create.
+const testPassword = "correct horse"
+
+// SetupTest clears the database, then puts back the built-in completion
+// types that the migrations seed and signs a user in.
+func (as *ActionSuite) SetupTest() {
+	as.Action.SetupTest()
+	as.NoError(models.SeedCompletionTypes(as.DB))
+
+	as.user = as.createUser("reader@example.com")
+	as.signIn(as.user)
+}
+
+// createUser signs up a user with testPassword.
+func (as *ActionSuite) createUser(email string) *models.User {
+	u := &models.User{Email: email, Password: testPassword, PasswordConfirmation: testPassword}
+	verrs, err := as.DB.ValidateAndCreate(u)
+	as.NoError(err)
+	as.False(verrs.HasAny())
+	return u
+}
+
+// signIn makes u the signed in user for the requests that follow.
+func (as *ActionSuite) signIn(u *models.User) {
+	as.Session.Set("current_user_id", u.ID.String())
 }
 
 func Test_ActionSuite(t *testing.T) {
@@ -0,0 +1,145 @@
+package actions
+
+import (
+    "encoding/json"
+    "errors"
+    "fmt"
+    "io"
+    "net/http"
+    "sort"
+    "strings"
+
+    "github.com/gobuffalo/buffalo"
+    "github.com/gobuffalo/buffalo/render"
+    "github.com/gobuffalo/validate/v3"
+)
+
+// apiPrefix is where version 1 of the JSON API is mounted. It takes the
+// same requests as the rest of the app, but only from API tokens, and
+// always answers in JSON.
+const apiPrefix = "/api/v1"
+
+// isAPI reports whether c is a request to the JSON API.
+func isAPI(c buffalo.Context) bool {
+    return strings.HasPrefix(c.Request().URL.Path, apiPrefix+"/")
+}
+
+// problem is an RFC 7807 problem details object, the body of every error
+// the JSON API returns. InvalidParams lists the fields that didn't
+// validate and why.
+type problem struct{
+    Type string `json:"type"`
+    Title string `json:"title"`
+    Status int `json:"status"`
+    Detail string `json:"detail,omitempty"`
+    Instance string `json:"instance,omitempty"`
+    InvalidParams []invalidParam `json:"invalid-params,omitempty"`
+}
+
+// invalidParam is one reason a field didn't validate.
+type invalidParam struct{
+    Name string `json:"name"`
+    Reason string `json:"reason"`
+}
+
+// newProblem is a problem with status for the current request, which
+// needs no type beyond its status.
+func newProblem(c buffalo.Context, status int, detail string) problem {
+    return problem{
+        Type: "about:blank",
+        Title: http.StatusText(status),
+        Status: status,
+        Detail: detail,
+        Instance: c.Request().URL.Path,
+    }
+}
+
+// problemFor turns an error a handler returned into a problem. Errors
+// raised with c.Error keep their status and message, and a body that
+// can't be decoded is a bad request. Anything else is an internal error
+// whose cause is logged rather than shown.
+func problemFor(c buffalo.Context, err error) problem {
+    var herr buffalo.HTTPError
+    if errors.As(err, &herr) && herr.Status < http.StatusInternalServerError {
+        detail := ""
+        if herr.Cause != nil {
+            detail = herr.Cause.Error()
+        }
+        return newProblem(c, herr.Status, detail)
+    }
+
+    var syntaxErr *json.SyntaxError
+    var typeErr *json.UnmarshalTypeError
+    if errors.As(err, &syntaxErr) || errors.As(err, &typeErr) {
+        return newProblem(c, http.StatusBadRequest, err.Error())
+    }
+
+    c.Logger().Error(err)
+    return newProblem(c, http.StatusInternalServerError, "")
+}
+
+// renderProblem renders p as application/problem+json.
+func renderProblem(c buffalo.Context, p problem) error {
+    if p.Status == http.StatusUnauthorized && c.Response().Header().Get("WWW-Authenticate") == "" {
+        c.Response().Header().Set("WWW-Authenticate", "Bearer")
+    }
+    return c.Render(p.Status, r.Func("application/problem+json", func(w io.Writer, _ render.Data) error {
+        return json.NewEncoder(w).Encode(p)
+    }))
+}
+
+// renderValidationErrors answers a JSON request whose data didn't
+// validate. The JSON API answers with a problem listing each field's
+// errors; elsewhere the errors are rendered as they are.
+func renderValidationErrors(c buffalo.Context, verrs *validate.Errors) error {
+    if !isAPI(c) {
+        return c.Render(http.StatusUnprocessableEntity, r.JSON(verrs))
+    }
+
+    p := newProblem(c, http.StatusUnprocessableEntity, "Some fields are not valid.")
+    fields := verrs.Keys()
+    sort.Strings(fields)
+    for _, field := range fields {
+        for _, reason := range verrs.Get(field) {
+            p.InvalidParams = append(p.InvalidParams, invalidParam{Name: field, Reason: reason})
+        }
+    }
+    return renderProblem(c, p)
+}
+
+// serveAPI makes every request to the JSON API ask for JSON, whatever its
+// Accept header says, and turns any error on the way into a problem. It
+// comes before every other middleware so their errors are caught too.
+// Other requests pass straight through.
+func serveAPI(next buffalo.Handler) buffalo.Handler {
+    return func(c buffalo.Context) error {
+        if !isAPI(c) {
+            return next(c)
+        }
+
+        c.Request().Header.Set("Accept", "application/json")
+        if err := next(c); err != nil {
+            return renderProblem(c, problemFor(c, err))
+        }
+        return nil
+    }
+}
+
+// apiNotFound answers a request for a path the JSON API doesn't have, so
+// it gets a problem rather than falling through to the public files. It
+// is mapped to every path under /api/v1 that no other route takes.
+func apiNotFound(c buffalo.Context) error {
+    return c.Error(http.StatusNotFound, fmt.Errorf("path not found: %s %s", c.Request().Method, c.Request().URL.Path))
+}
+
+// apiResource mounts the actions of res at path in the JSON API, leaving
+// out its HTML forms. A single one is named by param, as it is outside the
+// API.
+func apiResource(api *buffalo.App, path, param string, res buffalo.Resource) {
+    item := path + "/{" + param + "}"
+    api.GET(path, res.List)
+    api.POST(path, res.Create)
+    api.GET(item, res.Show)
+    api.PUT(item, res.Update)
+    api.DELETE(item, res.Destroy)
+}
@@ -0,0 +1,138 @@
+package actions
+
+import (
+  "net/http"
+  "time"
+
+  "completion_tracker/models"
+
+  "github.com/gofrs/uuid"
+)
+
+func (as *ActionSuite) Test_API_RequiresToken() {
+  as.createBook("Dune", 100, 400)
+
+  // The session as.user is signed in with doesn't count
+  res := as.JSON(apiPrefix + "/books").Get()
+  as.Equal(http.StatusUnauthorized, res.Code)
+  as.Equal("application/problem+json", res.Header().Get("Content-Type"))
+  as.Equal("Bearer", res.Header().Get("WWW-Authenticate"))
+
+  p := problem{}
+  res.Bind(&p)
+  as.Equal(http.StatusUnauthorized, p.Status)
+  as.Equal("Unauthorized", p.Title)
+  as.Contains(p.Instance, apiPrefix+"/books")
+
+  token := as.createAPIToken(as.user, "sync")
+  req := as.JSON(apiPrefix + "/books")
+  req.Headers["Authorization"] = "Bearer " + token.Token
+  res = req.Get()
+  as.Equal(http.StatusOK, res.Code)
+  as.Contains(res.Body.String(), "Dune")
+}
+
+func (as *ActionSuite) Test_API_AnswersInJSON() {
+  as.createBook("Dune", 100, 400)
+  token := as.createAPIToken(as.user, "sync")
+
+  req := as.XML(apiPrefix + "/books")
+  req.Headers["Authorization"] = "Bearer " + token.Token
+  res := req.Get()
+  as.Equal(http.StatusOK, res.Code)
+  as.Contains(res.Header().Get("Content-Type"), "application/json")
+
+  books := models.Completions{}
+  res.Bind(&books)
+  as.Len(books, 1)
+  as.NotEmpty(res.Header().Get("Link"))
+  as.Equal("1", res.Header().Get("X-Total-Count"))
+}
+
+func (as *ActionSuite) Test_API_Create() {
+  as.Session.Clear()
+  token := as.createAPIToken(as.user, "sync")
+
+  req := as.JSON(apiPrefix + "/books")
+  req.Headers["Authorization"] = "Bearer " + token.Token
+  res := req.Post(map[string]interface{}{
+    "name":         "Dune",
+    "completions":  100,
+    "target":       400,
+    "completed_at": time.Now(),
+  })
+  as.Equal(http.StatusCreated, res.Code)
+
+  book := &models.Completion{}
+  as.NoError(as.DB.Where("name = ?", "Dune").First(book))
+  as.Equal(models.CompletionTypeBook, book.Type)
+
+  req = as.JSON(apiPrefix+"/books/%s", book.ID)
+  req.Headers["Authorization"] = "Bearer " + token.Token
+  res = req.Put(map[string]interface{}{
+    "name":         "Dune Messiah",
+    "completions":  400,
+    "target":       400,
+    "completed_at": book.CompletedAt,
+  })
+  as.Equal(http.StatusOK, res.Code)
+  as.NoError(as.DB.Reload(book))
+  as.Equal("Dune Messiah", book.Name)
+}
+
+func (as *ActionSuite) Test_API_ValidationProblem() {
+  token := as.createAPIToken(as.user, "sync")
+
+  req := as.JSON(apiPrefix + "/books")
+  req.Headers["Authorization"] = "Bearer " + token.Token
+  res := req.Post(map[string]interface{}{"name": ""})
+  as.Equal(http.StatusUnprocessableEntity, res.Code)
+  as.Equal("application/problem+json", res.Header().Get("Content-Type"))
+
+  p := problem{}
+  res.Bind(&p)
+  as.Equal(http.StatusUnprocessableEntity, p.Status)
+  as.NotEmpty(p.InvalidParams)
+  names := []string{}
+  for _, param := range p.InvalidParams {
+    names = append(names, param.Name)
+    as.NotEmpty(param.Reason)
+  }
+  as.Contains(names, "name")
+
+  // The same request outside the API keeps the plain errors
+  res = as.JSON("/books").Post(map[string]interface{}{"name": ""})
+  as.Equal(http.StatusUnprocessableEntity, res.Code)
+  as.Contains(res.Header().Get("Content-Type"), "application/json")
+}
+
+func (as *ActionSuite) Test_API_NotFoundProblem() {
+  token := as.createAPIToken(as.user, "sync")
+
+  req := as.JSON(apiPrefix+"/books/%s", uuid.Must(uuid.NewV4()))
+  req.Headers["Authorization"] = "Bearer " + token.Token
+  res := req.Get()
+  as.Equal(http.StatusNotFound, res.Code)
+  as.Equal("application/problem+json", res.Header().Get("Content-Type"))
+
+  req = as.JSON(apiPrefix + "/nothing_here")
+  req.Headers["Authorization"] = "Bearer " + token.Token
+  res = req.Get()
+  as.Equal(http.StatusNotFound, res.Code)
+  as.Equal("application/problem+json", res.Header().Get("Content-Type"))
+
+  p := problem{}
+  res.Bind(&p)
+  as.Equal(http.StatusNotFound, p.Status)
+  as.Contains(p.Instance, apiPrefix+"/nothing_here")
+}
+
+func (as *ActionSuite) Test_API_BadBody() {
+  token := as.createAPIToken(as.user, "sync")
+
+  req := as.JSON(apiPrefix + "/books")
+  req.Headers["Authorization"] = "Bearer " + token.Token
+  res := req.Post(map[string]interface{}{"completions": "lots"})
+  as.Equal(http.StatusBadRequest, res.Code)
+  as.Equal("application/problem+json", res.Header().Get("Content-Type"))
+}
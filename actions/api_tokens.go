@@ -0,0 +1,145 @@
+package actions
+
+import (
+    "fmt"
+    "net/http"
+
+    "completion_tracker/models"
+
+    "github.com/gobuffalo/buffalo"
+    "github.com/gobuffalo/pop/v6"
+    "github.com/gobuffalo/x/responder"
+)
+
+// APITokensResource manages the signed in User's personal access tokens.
+// A token is sent as "Authorization: Bearer <token>" to use the JSON and
+// XML API from a script. Tokens can only be created, listed and revoked;
+// the token itself is shown once, when it is created.
+type APITokensResource struct{}
+
+// setAPITokens loads the signed in User's tokens for the token page, the
+// most recently created first.
+func setAPITokens(c buffalo.Context, tx *pop.Connection) (*models.APITokens, error) {
+    tokens := &models.APITokens{}
+    if err := tx.Where("user_id = ?", currentUser(c).ID).Order("created_at desc").All(tokens); err != nil {
+        return nil, err
+    }
+    c.Set("apiTokens", tokens)
+    return tokens, nil
+}
+
+// List gets the User's API tokens. This function is mapped to the path
+// GET /tokens
+func (v APITokensResource) List(c buffalo.Context) error {
+    // Get the DB connection from the context
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    tokens, err := setAPITokens(c, tx)
+    if err != nil {
+        return err
+    }
+
+    return responder.Wants("html", func(c buffalo.Context) error {
+        c.Set("apiToken", &models.APIToken{})
+        return c.Render(http.StatusOK, r.HTML("api_tokens/index.plush.html"))
+    }).Wants("json", func(c buffalo.Context) error {
+        return c.Render(200, r.JSON(tokens))
+    }).Wants("xml", func(c buffalo.Context) error {
+        return c.Render(200, r.XML(tokens))
+    }).Respond(c)
+}
+
+// Create issues the User a new API token. The response is the only place
+// the token can be read. This function is mapped to the path POST /tokens
+func (v APITokensResource) Create(c buffalo.Context) error {
+    // Allocate an empty APIToken
+    token := &models.APIToken{}
+
+    // Bind the token's name from the html form
+    if err := c.Bind(token); err != nil {
+        return err
+    }
+    token.UserID = currentUser(c).ID
+
+    // Get the DB connection from the context
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    // Validate the data from the html form
+    verrs, err := tx.ValidateAndCreate(token)
+    if err != nil {
+        return err
+    }
+
+    status := http.StatusCreated
+    if verrs.HasAny() {
+        status = http.StatusUnprocessableEntity
+    }
+
+    return responder.Wants("html", func(c buffalo.Context) error {
+        // The page is rendered rather than redirected to, so the new token
+        // never has to be kept in the session.
+        if verrs.HasAny() {
+            c.Set("errors", verrs)
+            c.Set("apiToken", token)
+        } else {
+            c.Set("newToken", token)
+            c.Set("apiToken", &models.APIToken{})
+        }
+        if _, err := setAPITokens(c, tx); err != nil {
+            return err
+        }
+
+        return c.Render(status, r.HTML("api_tokens/index.plush.html"))
+    }).Wants("json", func(c buffalo.Context) error {
+        if verrs.HasAny() {
+            return renderValidationErrors(c, verrs)
+        }
+        return c.Render(status, r.JSON(token))
+    }).Wants("xml", func(c buffalo.Context) error {
+        if verrs.HasAny() {
+            return c.Render(status, r.XML(verrs))
+        }
+        return c.Render(status, r.XML(token))
+    }).Respond(c)
+}
+
+// Destroy revokes one of the User's API tokens, which stops working at
+// once. This function is mapped to the path DELETE /tokens/{api_token_id}
+func (v APITokensResource) Destroy(c buffalo.Context) error {
+    // Get the DB connection from the context
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    // Allocate an empty APIToken
+    token := &models.APIToken{}
+
+    // To find the APIToken the parameter api_token_id is used, and it has
+    // to be one of the User's own.
+    if err := tx.Where("user_id = ?", currentUser(c).ID).Find(token, c.Param("api_token_id")); err != nil {
+        return c.Error(http.StatusNotFound, err)
+    }
+
+    if err := tx.Destroy(token); err != nil {
+        return err
+    }
+
+    return responder.Wants("html", func(c buffalo.Context) error {
+        // If there are no errors set a flash message
+        c.Flash().Add("success", T.Translate(c, "api_token.revoked.success"))
+
+        // Redirect to the token page
+        return c.Redirect(http.StatusSeeOther, "/tokens")
+    }).Wants("json", func(c buffalo.Context) error {
+        return c.Render(http.StatusOK, r.JSON(token))
+    }).Wants("xml", func(c buffalo.Context) error {
+        return c.Render(http.StatusOK, r.XML(token))
+    }).Respond(c)
+}
@@ -0,0 +1,105 @@
+package actions
+
+import (
+  "net/http"
+
+  "completion_tracker/models"
+)
+
+// createAPIToken issues u a token named name.
+func (as *ActionSuite) createAPIToken(u *models.User, name string) *models.APIToken {
+  token := &models.APIToken{UserID: u.ID, Name: name}
+  verrs, err := as.DB.ValidateAndCreate(token)
+  as.NoError(err)
+  as.False(verrs.HasAny())
+  return token
+}
+
+func (as *ActionSuite) Test_APITokensResource_List() {
+  as.createAPIToken(as.user, "backup script")
+  as.createAPIToken(as.createUser("stranger@example.com"), "their script")
+
+  res := as.HTML("/tokens").Get()
+  as.Equal(http.StatusOK, res.Code)
+  as.Contains(res.Body.String(), "backup script")
+  as.NotContains(res.Body.String(), "their script")
+
+  tokens := models.APITokens{}
+  jres := as.JSON("/tokens").Get()
+  as.Equal(http.StatusOK, jres.Code)
+  jres.Bind(&tokens)
+  as.Len(tokens, 1)
+  as.Empty(tokens[0].Token)
+  as.NotContains(jres.Body.String(), "token_hash")
+}
+
+func (as *ActionSuite) Test_APITokensResource_Create() {
+  res := as.HTML("/tokens").Post(map[string]interface{}{"Name": "backup script"})
+  as.Equal(http.StatusCreated, res.Code)
+  as.Contains(res.Body.String(), models.APITokenPrefix)
+
+  token := &models.APIToken{}
+  jres := as.JSON("/tokens").Post(map[string]interface{}{"name": "sync"})
+  as.Equal(http.StatusCreated, jres.Code)
+  jres.Bind(token)
+  as.Contains(token.Token, models.APITokenPrefix)
+
+  // Only a hash of the token is kept
+  stored := &models.APIToken{}
+  as.NoError(as.DB.Find(stored, token.ID))
+  as.Equal(as.user.ID, stored.UserID)
+  as.NotEmpty(stored.TokenHash)
+  as.NotContains(stored.TokenHash, token.Token)
+
+  jres = as.JSON("/tokens").Post(map[string]interface{}{"name": " "})
+  as.Equal(http.StatusUnprocessableEntity, jres.Code)
+}
+
+func (as *ActionSuite) Test_APITokensResource_Destroy() {
+  token := as.createAPIToken(as.user, "backup script")
+  theirs := as.createAPIToken(as.createUser("stranger@example.com"), "their script")
+
+  as.Equal(http.StatusNotFound, as.HTML("/tokens/%s", theirs.ID).Delete().Code)
+
+  res := as.HTML("/tokens/%s", token.ID).Delete()
+  as.Equal(http.StatusSeeOther, res.Code)
+  as.Equal("/tokens", res.Location())
+
+  // A revoked token no longer signs anyone in
+  as.Session.Clear()
+  req := as.JSON("/books")
+  req.Headers["Authorization"] = "Bearer " + token.Token
+  as.Equal(http.StatusUnauthorized, req.Get().Code)
+}
+
+func (as *ActionSuite) Test_BearerToken() {
+  token := as.createAPIToken(as.user, "backup script")
+  as.createBook("Dune", 206, 412)
+  as.Session.Clear()
+
+  req := as.JSON("/books")
+  req.Headers["Authorization"] = "Bearer " + token.Token
+  res := req.Get()
+  as.Equal(http.StatusOK, res.Code)
+  as.Contains(res.Body.String(), "Dune")
+
+  // Changes are made as the token's owner
+  req = as.JSON("/books")
+  req.Headers["Authorization"] = "Bearer " + token.Token
+  as.Equal(http.StatusCreated, req.Post(map[string]interface{}{"name": "Emma", "completed_at": "2025-08-17T09:00:00Z"}).Code)
+
+  book := &models.Completion{}
+  as.NoError(as.DB.Where("name = ?", "Emma").First(book))
+  as.Equal(as.user.ID, book.UserID.UUID)
+
+  as.NoError(as.DB.Reload(token))
+  as.True(token.LastUsedAt.Valid)
+
+  // A bad token is refused, rather than falling back to the session
+  as.signIn(as.user)
+  req = as.JSON("/books")
+  req.Headers["Authorization"] = "Bearer " + models.APITokenPrefix + "nope"
+  res = req.Get()
+  as.Equal(http.StatusUnauthorized, res.Code)
+  as.Contains(res.Header().Get("WWW-Authenticate"), "invalid_token")
+}
@@ -11,7 +11,6 @@ import (
 	"github.com/gobuffalo/buffalo"
 	"github.com/gobuffalo/buffalo-pop/v3/pop/popmw"
 	"github.com/gobuffalo/envy"
-	"github.com/gobuffalo/middleware/csrf"
 	"github.com/gobuffalo/middleware/forcessl"
 	"github.com/gobuffalo/middleware/i18n"
 	"github.com/gobuffalo/middleware/paramlogger"
@@ -54,25 +53,135 @@ func App() *buffalo.App {
 		// Log request parameters (filters apply).
 		app.Use(paramlogger.ParameterLogger)
 
+		// Answer every request to the JSON API in JSON, with errors as
+		// application/problem+json.
+		app.Use(serveAPI)
+
 		// Protect against CSRF attacks. https://www.owasp.org/index.php/Cross-Site_Request_Forgery_(CSRF)
+		// Requests made with an API token, or to the JSON API, are exempt.
 		// Remove to disable this.
-		app.Use(csrf.New)
+		app.Use(protectFromForgery)
 
 		// Wraps each request in a transaction.
 		//   c.Value("tx").(*pop.Connection)
 		// Remove to disable this.
 		app.Use(popmw.Transaction(models.DB))
+
+		// Load the completion types defined in the database, unless they
+		// were loaded recently.
+		app.Use(loadCompletionTypes)
 		// Setup and use translations:
 		app.Use(translations())
+		// Require a signed in user everywhere but the home page, the
+		// pages for signing up and in, and the public profiles.
+		app.Use(SetCurrentUser)
+		app.Use(Authorize)
+		app.Middleware.Skip(Authorize, HomeHandler, UsersNew, UsersCreate, AuthNew, AuthCreate, OIDCStart, OIDCCallback)
+		app.Middleware.Skip(Authorize, ProfilesResource{}.Show, ProfilesResource{}.ShowCompletion)
+		app.Middleware.Skip(Authorize, OpenAPIHandler, APIDocsHandler)
+		// Record who makes each request's changes in the revisions.
+		app.Use(setActor)
 
 		app.GET("/", HomeHandler)
 
+		app.GET("/signup", UsersNew)
+		app.POST("/users", UsersCreate)
+		app.GET("/signin", AuthNew)
+		app.POST("/signin", AuthCreate)
+		app.DELETE("/signout", AuthDestroy)
+		app.GET("/auth/oidc", OIDCStart).Name("oidcPath")
+		app.GET("/auth/oidc/callback", OIDCCallback).Name("oidcCallbackPath")
+
+		app.GET("/account", AccountEdit)
+		app.PUT("/account", AccountUpdate)
+
+		tokens := APITokensResource{}
+		app.GET("/tokens", tokens.List)
+		app.POST("/tokens", tokens.Create)
+		app.DELETE("/tokens/{api_token_id}", tokens.Destroy).Name("apiTokenPath")
+
 		app.Resource("/completions", CompletionsResource{})
+		app.Resource("/completions/{completion_id}/entries", ProgressEntriesResource{})
+		app.Resource("/completions/{completion_id}/runs", RunsResource{})
+		app.GET("/completions/{completion_id}/revisions", RevisionsResource{}.List)
 		app.Resource("/tv_shows", TvShowsResource{})
+		app.Resource("/tv_shows/{tv_show_id}/seasons", SeasonsResource{})
+		app.Resource("/tv_shows/{tv_show_id}/seasons/{season_id}/episodes", EpisodesResource{})
 		app.Resource("/video_games", VideoGamesResource{})
 		app.Resource("/books", BooksResource{})
 		app.Resource("/audio_books", AudioBooksResource{})
 		app.Resource("/events", EventsResource{})
+		app.Resource("/series", SeriesResource{})
+
+		app.Resource("/groups", GroupsResource{})
+		groupInvites := GroupInvitesResource{}
+		app.POST("/groups/{group_id}/invites", groupInvites.Create).Name("groupInvitesPath")
+		app.DELETE("/groups/{group_id}/invites/{invite_id}", groupInvites.Destroy).Name("groupInvitePath")
+		members := GroupMembersResource{}
+		app.PUT("/groups/{group_id}/members/{membership_id}", members.Update).Name("groupMemberPath")
+		app.DELETE("/groups/{group_id}/members/{membership_id}", members.Destroy)
+		invites := InvitesResource{}
+		app.GET("/invites", invites.List)
+		app.POST("/invites/{invite_id}/accept", invites.Accept).Name("acceptInvitePath")
+		app.DELETE("/invites/{invite_id}", invites.Decline).Name("invitePath")
+
+		tags := TagsResource{}
+		app.GET("/tags", tags.List)
+		app.GET("/tags/{slug}", tags.Show).Name("tagPath")
+
+		app.GET("/search", SearchResource{}.List)
+
+		trash := TrashResource{}
+		app.GET("/trash", trash.List)
+		app.DELETE("/trash", trash.Empty)
+		app.POST("/trash/{completion_id}/restore", trash.Restore).Name("restoreTrashPath")
+		app.DELETE("/trash/{completion_id}", trash.Purge).Name("purgeTrashPath")
+
+		profiles := ProfilesResource{}
+		app.GET("/u/{username}", profiles.Show).Name("profilePath")
+		app.GET("/u/{username}/{completion_id}", profiles.ShowCompletion).Name("profileCompletionPath")
+
+		// The OpenAPI document describing the completion routes, and a page
+		// showing it.
+		app.GET("/api/openapi.json", OpenAPIHandler).Name("openAPIPath")
+		app.GET("/api/docs", APIDocsHandler).Name("apiDocsPath")
+
+		// The JSON API, for clients with an API token. It has the same
+		// actions as the pages above, without the forms.
+		api := app.Group(apiPrefix)
+		apiResource(api, "/completions", "completion_id", CompletionsResource{})
+		apiResource(api, "/completions/{completion_id}/entries", "progress_entry_id", ProgressEntriesResource{})
+		apiResource(api, "/completions/{completion_id}/runs", "run_id", RunsResource{})
+		api.GET("/completions/{completion_id}/revisions", RevisionsResource{}.List)
+		apiResource(api, "/tv_shows", "tv_show_id", TvShowsResource{})
+		apiResource(api, "/tv_shows/{tv_show_id}/seasons", "season_id", SeasonsResource{})
+		apiResource(api, "/tv_shows/{tv_show_id}/seasons/{season_id}/episodes", "episode_id", EpisodesResource{})
+		apiResource(api, "/video_games", "video_game_id", VideoGamesResource{})
+		apiResource(api, "/books", "book_id", BooksResource{})
+		apiResource(api, "/audio_books", "audio_book_id", AudioBooksResource{})
+		apiResource(api, "/events", "event_id", EventsResource{})
+		apiResource(api, "/series", "series_id", SeriesResource{})
+		apiResource(api, "/groups", "group_id", GroupsResource{})
+		api.POST("/groups/{group_id}/invites", groupInvites.Create)
+		api.DELETE("/groups/{group_id}/invites/{invite_id}", groupInvites.Destroy)
+		api.PUT("/groups/{group_id}/members/{membership_id}", members.Update)
+		api.DELETE("/groups/{group_id}/members/{membership_id}", members.Destroy)
+		api.GET("/invites", invites.List)
+		api.POST("/invites/{invite_id}/accept", invites.Accept)
+		api.DELETE("/invites/{invite_id}", invites.Decline)
+		api.GET("/tags", tags.List)
+		api.GET("/tags/{slug}", tags.Show)
+		api.GET("/search", SearchResource{}.List)
+		api.GET("/trash", trash.List)
+		api.DELETE("/trash", trash.Empty)
+		api.POST("/trash/{completion_id}/restore", trash.Restore)
+		api.DELETE("/trash/{completion_id}", trash.Purge)
+		api.ANY("/{path:.+}", apiNotFound)
+
+		// The admin screens, for admins only.
+		admin := app.Group("/admin")
+		admin.Use(requireAdmin)
+		admin.Resource("/completion_types", CompletionTypesResource{})
 		app.ServeFiles("/", http.FS(public.FS())) // serve files from the public directory
 	})
 
@@ -1,48 +1,336 @@
 package actions
 
 import (
+    "fmt"
     "net/http"
 
+    "completion_tracker/models"
+
     "github.com/gobuffalo/buffalo"
+    "github.com/gobuffalo/pop/v6"
+    "github.com/gobuffalo/validate/v3"
+    "github.com/gobuffalo/x/responder"
 )
 
 type AudioBooksResource struct{
     buffalo.Resource
 }
 
+// audioBookForm binds an Audio Book completion from the request. The HTML
+// form posts the time listened and the runtime as h:mm durations, which
+// are stored on the completion in minutes as its Completions and Target.
+type audioBookForm struct {
+    *models.Completion
+    Listened *string `json:"-" xml:"-" form:"Listened"`
+    Runtime  *string `json:"-" xml:"-" form:"Runtime"`
+}
+
+// bindAudioBook binds the request onto completion, converting any posted
+// durations to minutes. JSON and XML clients send the minutes directly.
+// A duration that isn't h:mm comes back as a validation error on the
+// field it fills, so the form can be shown again.
+func bindAudioBook(c buffalo.Context, completion *models.Completion) (*validate.Errors, error) {
+    form := &audioBookForm{Completion: completion}
+    if err := c.Bind(form); err != nil {
+        return nil, err
+    }
+
+    verrs := validate.NewErrors()
+    if form.Listened != nil {
+        if d, err := models.ParseDuration(*form.Listened); err != nil {
+            verrs.Add("completions", T.Translate(c, "audio_book.listened.invalid"))
+        } else {
+            completion.Completions = int(d)
+        }
+    }
+    if form.Runtime != nil {
+        if d, err := models.ParseDuration(*form.Runtime); err != nil {
+            verrs.Add("target", T.Translate(c, "audio_book.runtime.invalid"))
+        } else {
+            completion.Target = int(d)
+        }
+    }
+    return verrs, nil
+}
 
-// List default implementation.
+// List gets all Audio Book completions. This function is mapped to the path
+// GET /audio_books
 func (v AudioBooksResource) List(c buffalo.Context) error {
-    return c.Render(http.StatusOK, r.String("AudioBook#List"))
+    // Get the DB connection from the context
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    completions := &models.Completions{}
+
+    // The Audio Book completions the User can see, leaving out the trash
+    q := tx.Scope(visibleCompletions(c))
+    q = q.Where("type = ?", models.CompletionTypeAudioBook)
+
+    // Optionally filter by lifecycle status, rating, completion date and
+    // progress
+    q, err := filterCompletions(c, q)
+    if err != nil {
+        return err
+    }
+
+    return responder.Wants("html", func(c buffalo.Context) error {
+        // Sort and retrieve the page asked for by the "page" and
+        // "per_page" params
+        pagination, err := paginate(c, q, completions)
+        if err != nil {
+            return err
+        }
+        c.Set("pagination", pagination)
+        c.Set("statuses", models.GetStatuses())
+        c.Set("completions", completions)
+        return c.Render(http.StatusOK, r.HTML("audio_books/index.plush.html"))
+    }).Wants("json", func(c buffalo.Context) error {
+        // JSON clients page through by cursor
+        if err := paginateByCursor(c, q, completions); err != nil {
+            return err
+        }
+        return c.Render(200, r.JSON(completions))
+    }).Wants("xml", func(c buffalo.Context) error {
+        if _, err := paginate(c, q, completions); err != nil {
+            return err
+        }
+        return c.Render(200, r.XML(completions))
+    }).Respond(c)
 }
 
-// Show default implementation.
+// Show gets the data for one Audio Book completion. This function is mapped to
+// the path GET /audio_books/{audio_book_id}
 func (v AudioBooksResource) Show(c buffalo.Context) error {
-    return c.Render(http.StatusOK, r.String("AudioBook#Show"))
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    completion := &models.Completion{}
+    if err := tx.Eager("StatusTransitions", "Runs", "Series", "Group", "Tags").Scope(visibleCompletions(c)).Find(completion, c.Param("audio_book_id")); err != nil {
+        return c.Error(http.StatusNotFound, err)
+    }
+
+    // Ensure this is actually an Audio Book completion
+    if completion.Type != models.CompletionTypeAudioBook {
+        return c.Error(http.StatusNotFound, fmt.Errorf("completion is not an audio book"))
+    }
+
+    // Only the latest changes, the rest are paged through at its revisions path
+    if err := loadRecentRevisions(tx, completion); err != nil {
+        return err
+    }
+
+    return responder.Wants("html", func(c buffalo.Context) error {
+        c.Set("completion", completion)
+        return c.Render(http.StatusOK, r.HTML("audio_books/show.plush.html"))
+    }).Wants("json", func(c buffalo.Context) error {
+        return c.Render(200, r.JSON(completion))
+    }).Wants("xml", func(c buffalo.Context) error {
+        return c.Render(200, r.XML(completion))
+    }).Respond(c)
 }
 
-// Create default implementation.
+// Create adds an Audio Book completion to the DB. This function is mapped to the
+// path POST /audio_books
 func (v AudioBooksResource) Create(c buffalo.Context) error {
-    return c.Render(http.StatusOK, r.String("AudioBook#Create"))
+    completion := &models.Completion{
+        Type: models.CompletionTypeAudioBook,
+    }
+
+    bindErrs, err := bindAudioBook(c, completion)
+    if err != nil {
+        return err
+    }
+
+    // Ensure type is set correctly
+    completion.Type = models.CompletionTypeAudioBook
+
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    // It always belongs to the signed in User, whatever was posted
+    if err := setOwner(c, tx, completion); err != nil {
+        return err
+    }
+
+    // Nothing is saved while a duration can't be read
+    verrs := bindErrs
+    if !verrs.HasAny() {
+        if verrs, err = tx.ValidateAndCreate(completion); err != nil {
+            return err
+        }
+    }
+
+    if verrs.HasAny() {
+        return responder.Wants("html", func(c buffalo.Context) error {
+            c.Set("errors", verrs)
+            c.Set("completion", completion)
+            c.Set("statuses", models.GetStatuses())
+            if err := setCompletionOptions(c); err != nil {
+                return err
+            }
+            return c.Render(http.StatusUnprocessableEntity, r.HTML("audio_books/new.plush.html"))
+        }).Wants("json", func(c buffalo.Context) error {
+            return renderValidationErrors(c, verrs)
+        }).Wants("xml", func(c buffalo.Context) error {
+            return c.Render(http.StatusUnprocessableEntity, r.XML(verrs))
+        }).Respond(c)
+    }
+
+    return responder.Wants("html", func(c buffalo.Context) error {
+        c.Flash().Add("success", T.Translate(c, "audio_book.created.success"))
+        return c.Redirect(http.StatusSeeOther, "/audio_books/%v", completion.ID)
+    }).Wants("json", func(c buffalo.Context) error {
+        return c.Render(http.StatusCreated, r.JSON(completion))
+    }).Wants("xml", func(c buffalo.Context) error {
+        return c.Render(http.StatusCreated, r.XML(completion))
+    }).Respond(c)
 }
 
-// Update default implementation.
+// Update changes an Audio Book completion in the DB. This function is mapped to
+// the path PUT /audio_books/{audio_book_id}
 func (v AudioBooksResource) Update(c buffalo.Context) error {
-    return c.Render(http.StatusOK, r.String("AudioBook#Update"))
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    completion := &models.Completion{}
+    if err := tx.Scope(editableCompletions(c)).Find(completion, c.Param("audio_book_id")); err != nil {
+        return c.Error(http.StatusNotFound, err)
+    }
+
+    if completion.Type != models.CompletionTypeAudioBook {
+        return c.Error(http.StatusNotFound, fmt.Errorf("completion is not an audio book"))
+    }
+
+    // Remember which it is, and how it was shared, which only its owner
+    // can change
+    persisted := *completion
+
+    bindErrs, err := bindAudioBook(c, completion)
+    if err != nil {
+        return err
+    }
+
+    // Ensure type remains Audio Book
+    completion.Type = models.CompletionTypeAudioBook
+
+    // It stays the Completion that was found, whatever id was posted
+    keepIdentity(completion, persisted)
+
+    // It keeps its owner, whoever edits it
+    if err := keepOwner(c, tx, completion, persisted); err != nil {
+        return err
+    }
+
+    // Nothing is saved while a duration can't be read
+    verrs := bindErrs
+    if !verrs.HasAny() {
+        if verrs, err = tx.ValidateAndUpdate(completion); err != nil {
+            return err
+        }
+    }
+
+    if verrs.HasAny() {
+        return responder.Wants("html", func(c buffalo.Context) error {
+            c.Set("errors", verrs)
+            c.Set("completion", completion)
+            c.Set("statuses", models.GetStatuses())
+            if err := setCompletionOptions(c); err != nil {
+                return err
+            }
+            return c.Render(http.StatusUnprocessableEntity, r.HTML("audio_books/edit.plush.html"))
+        }).Wants("json", func(c buffalo.Context) error {
+            return renderValidationErrors(c, verrs)
+        }).Wants("xml", func(c buffalo.Context) error {
+            return c.Render(http.StatusUnprocessableEntity, r.XML(verrs))
+        }).Respond(c)
+    }
+
+    return responder.Wants("html", func(c buffalo.Context) error {
+        c.Flash().Add("success", T.Translate(c, "audio_book.updated.success"))
+        return c.Redirect(http.StatusSeeOther, "/audio_books/%v", completion.ID)
+    }).Wants("json", func(c buffalo.Context) error {
+        return c.Render(http.StatusOK, r.JSON(completion))
+    }).Wants("xml", func(c buffalo.Context) error {
+        return c.Render(http.StatusOK, r.XML(completion))
+    }).Respond(c)
 }
 
-// Destroy default implementation.
+// Destroy moves an Audio Book completion to the trash. This function is mapped
+// to the path DELETE /audio_books/{audio_book_id}
 func (v AudioBooksResource) Destroy(c buffalo.Context) error {
-    return c.Render(http.StatusOK, r.String("AudioBook#Destroy"))
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    completion := &models.Completion{}
+    if err := tx.Scope(ownCompletions(c)).Find(completion, c.Param("audio_book_id")); err != nil {
+        return c.Error(http.StatusNotFound, err)
+    }
+
+    if completion.Type != models.CompletionTypeAudioBook {
+        return c.Error(http.StatusNotFound, fmt.Errorf("completion is not an audio book"))
+    }
+
+    if err := completion.Trash(tx); err != nil {
+        return err
+    }
+
+    return responder.Wants("html", func(c buffalo.Context) error {
+        c.Flash().Add("success", T.Translate(c, "audio_book.destroyed.success"))
+        return c.Redirect(http.StatusSeeOther, "/audio_books")
+    }).Wants("json", func(c buffalo.Context) error {
+        return c.Render(http.StatusOK, r.JSON(completion))
+    }).Wants("xml", func(c buffalo.Context) error {
+        return c.Render(http.StatusOK, r.XML(completion))
+    }).Respond(c)
 }
 
-// New default implementation.
+// New renders the form for creating a new Audio Book completion.
+// This function is mapped to the path GET /audio_books/new
 func (v AudioBooksResource) New(c buffalo.Context) error {
-    return c.Render(http.StatusOK, r.String("AudioBook#New"))
+    completion := &models.Completion{
+        Type: models.CompletionTypeAudioBook,
+    }
+    c.Set("completion", completion)
+    c.Set("statuses", models.GetStatuses())
+    if err := setCompletionOptions(c); err != nil {
+        return err
+    }
+
+    return c.Render(http.StatusOK, r.HTML("audio_books/new.plush.html"))
 }
 
-// Edit default implementation.
+// Edit renders a edit form for an Audio Book completion. This function is
+// mapped to the path GET /audio_books/{audio_book_id}/edit
 func (v AudioBooksResource) Edit(c buffalo.Context) error {
-    return c.Render(http.StatusOK, r.String("AudioBook#Edit"))
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    completion := &models.Completion{}
+    if err := tx.Eager("Tags").Scope(editableCompletions(c)).Find(completion, c.Param("audio_book_id")); err != nil {
+        return c.Error(http.StatusNotFound, err)
+    }
+
+    if completion.Type != models.CompletionTypeAudioBook {
+        return c.Error(http.StatusNotFound, fmt.Errorf("completion is not an audio book"))
+    }
+
+    c.Set("completion", completion)
+    c.Set("statuses", models.GetStatuses())
+    if err := setCompletionOptions(c); err != nil {
+        return err
+    }
+    return c.Render(http.StatusOK, r.HTML("audio_books/edit.plush.html"))
 }
 
@@ -1,36 +1,134 @@
 package actions
 
 import (
-  "testing"
+  "net/http"
+  "time"
 
-  "github.com/stretchr/testify/require"
+  "completion_tracker/models"
+
+  "github.com/gobuffalo/nulls"
 )
 
+func (as *ActionSuite) createAudioBook(name string, listened, runtime int) *models.Completion {
+  audioBook := &models.Completion{
+    Name:        name,
+    Type:        models.CompletionTypeAudioBook,
+    Completions: listened,
+    Target:      runtime,
+    CompletedAt: time.Now(),
+    UserID:      nulls.NewUUID(as.user.ID),
+  }
+  verrs, err := as.DB.ValidateAndCreate(audioBook)
+  as.NoError(err)
+  as.False(verrs.HasAny())
+  return audioBook
+}
+
 func (as *ActionSuite) Test_AudioBooksResource_List() {
-  as.Fail("Not Implemented!")
+  as.createAudioBook("Project Hail Mary", 240, 960)
+
+  res := as.HTML("/audio_books").Get()
+  as.Equal(http.StatusOK, res.Code)
+  as.Contains(res.Body.String(), "Project Hail Mary")
+  as.Contains(res.Body.String(), "25%")
+  as.Contains(res.Body.String(), "12:00 to go")
+
+  audioBooks := models.Completions{}
+  jres := as.JSON("/audio_books").Get()
+  as.Equal(http.StatusOK, jres.Code)
+  jres.Bind(&audioBooks)
+  as.Len(audioBooks, 1)
+  as.Equal(960, audioBooks[0].Target)
 }
 
 func (as *ActionSuite) Test_AudioBooksResource_Show() {
-  as.Fail("Not Implemented!")
+  audioBook := as.createAudioBook("Project Hail Mary", 240, 960)
+
+  res := as.HTML("/audio_books/%s", audioBook.ID).Get()
+  as.Equal(http.StatusOK, res.Code)
+  as.Contains(res.Body.String(), "16:00")
+
+  book := &models.Completion{Name: "Dune", Type: models.CompletionTypeBook, Completions: 12, CompletedAt: time.Now(), UserID: nulls.NewUUID(as.user.ID)}
+  as.NoError(as.DB.Create(book))
+  res = as.HTML("/audio_books/%s", book.ID).Get()
+  as.Equal(http.StatusNotFound, res.Code)
 }
 
 func (as *ActionSuite) Test_AudioBooksResource_Create() {
-  as.Fail("Not Implemented!")
+  res := as.HTML("/audio_books").Post(map[string]interface{}{
+    "Name":        "Project Hail Mary",
+    "Listened":    "1:30",
+    "Runtime":     "16:10",
+    "CompletedAt": time.Now().Format("2006-01-02T15:04"),
+  })
+  as.Equal(http.StatusSeeOther, res.Code)
+
+  audioBook := &models.Completion{}
+  as.NoError(as.DB.Where("name = ?", "Project Hail Mary").First(audioBook))
+  as.Equal(models.CompletionTypeAudioBook, audioBook.Type)
+  as.Equal(90, audioBook.Completions)
+  as.Equal(970, audioBook.Target)
+
+  res = as.HTML("/audio_books").Post(map[string]interface{}{
+    "Name":        "Overlistened",
+    "Listened":    "20:00",
+    "Runtime":     "16:10",
+    "CompletedAt": time.Now().Format("2006-01-02T15:04"),
+  })
+  as.Equal(http.StatusUnprocessableEntity, res.Code)
+
+  // A duration that isn't h:mm re-renders the form rather than failing
+  res = as.HTML("/audio_books").Post(map[string]interface{}{
+    "Name":        "Misheard",
+    "Listened":    "an hour",
+    "Runtime":     "16:10",
+    "CompletedAt": time.Now().Format("2006-01-02T15:04"),
+  })
+  as.Equal(http.StatusUnprocessableEntity, res.Code)
+  as.Contains(res.Body.String(), "Time Listened must be written as h:mm")
+
+  count, err := as.DB.Where("name = ?", "Misheard").Count(&models.Completion{})
+  as.NoError(err)
+  as.Equal(0, count)
 }
 
 func (as *ActionSuite) Test_AudioBooksResource_Update() {
-  as.Fail("Not Implemented!")
+  audioBook := as.createAudioBook("Project Hail Mary", 90, 970)
+
+  res := as.JSON("/audio_books/%s", audioBook.ID).Put(map[string]interface{}{
+    "name":         "Project Hail Mary",
+    "completions":  970,
+    "target":       970,
+    "completed_at": audioBook.CompletedAt,
+  })
+  as.Equal(http.StatusOK, res.Code)
+
+  as.NoError(as.DB.Reload(audioBook))
+  as.Equal(970, audioBook.Completions)
 }
 
 func (as *ActionSuite) Test_AudioBooksResource_Destroy() {
-  as.Fail("Not Implemented!")
+  audioBook := as.createAudioBook("Project Hail Mary", 90, 970)
+
+  res := as.HTML("/audio_books/%s", audioBook.ID).Delete()
+  as.Equal(http.StatusSeeOther, res.Code)
+  as.Equal("/audio_books", res.Location())
+
+  count, err := as.DB.Scope(models.NotTrashed).Where("id = ?", audioBook.ID).Count(&models.Completion{})
+  as.NoError(err)
+  as.Equal(0, count)
 }
 
 func (as *ActionSuite) Test_AudioBooksResource_New() {
-  as.Fail("Not Implemented!")
+  res := as.HTML("/audio_books/new").Get()
+  as.Equal(http.StatusOK, res.Code)
+  as.Contains(res.Body.String(), "Total Runtime")
 }
 
 func (as *ActionSuite) Test_AudioBooksResource_Edit() {
-  as.Fail("Not Implemented!")
-}
+  audioBook := as.createAudioBook("Project Hail Mary", 90, 970)
 
+  res := as.HTML("/audio_books/%s/edit", audioBook.ID).Get()
+  as.Equal(http.StatusOK, res.Code)
+  as.Contains(res.Body.String(), `value="1:30"`)
+}
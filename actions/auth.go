@@ -0,0 +1,203 @@
+package actions
+
+import (
+    "errors"
+    "fmt"
+    "net/http"
+    "strings"
+
+    "completion_tracker/models"
+
+    "github.com/gobuffalo/buffalo"
+    "github.com/gobuffalo/middleware/csrf"
+    "github.com/gobuffalo/pop/v6"
+    "github.com/gobuffalo/validate/v3"
+    "github.com/gobuffalo/x/responder"
+)
+
+// SetCurrentUser loads the User signed in to the session, or the owner
+// of the API token the request carries, and makes them available as
+// "current_user". A request with a token never falls back to the session,
+// and one with a bad token is turned away. The JSON API only takes tokens.
+func SetCurrentUser(next buffalo.Handler) buffalo.Handler {
+    return func(c buffalo.Context) error {
+        tx, ok := c.Value("tx").(*pop.Connection)
+        if !ok {
+            return fmt.Errorf("no transaction found")
+        }
+
+        if token := bearerToken(c); token != "" {
+            u, err := models.AuthenticateToken(tx, token)
+            if errors.Is(err, models.ErrInvalidToken) {
+                c.Response().Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+                return c.Error(http.StatusUnauthorized, err)
+            }
+            if err != nil {
+                return err
+            }
+            c.Set("current_user", u)
+            return next(c)
+        }
+
+        uid := c.Session().Get("current_user_id")
+        if uid == nil || isAPI(c) {
+            return next(c)
+        }
+
+        u := &models.User{}
+        if err := tx.Find(u, uid); err != nil {
+            // The account has gone, so sign the session out
+            c.Session().Clear()
+            return next(c)
+        }
+        c.Set("current_user", u)
+        return next(c)
+    }
+}
+
+// Authorize requires a signed in User. A browser is sent to sign in and
+// then brought back, and an API client gets a 401.
+func Authorize(next buffalo.Handler) buffalo.Handler {
+    return func(c buffalo.Context) error {
+        if _, ok := c.Value("current_user").(*models.User); ok {
+            return next(c)
+        }
+
+        return responder.Wants("html", func(c buffalo.Context) error {
+            if c.Request().Method == http.MethodGet {
+                c.Session().Set("redirect_url", c.Request().URL.String())
+            }
+            c.Flash().Add("danger", T.Translate(c, "auth.required"))
+            return c.Redirect(http.StatusFound, "/signin")
+        }).Wants("json", func(c buffalo.Context) error {
+            return c.Error(http.StatusUnauthorized, errors.New("sign in required"))
+        }).Wants("xml", func(c buffalo.Context) error {
+            return c.Error(http.StatusUnauthorized, errors.New("sign in required"))
+        }).Respond(c)
+    }
+}
+
+// requireAdmin only lets admins through. Everyone else is forbidden.
+func requireAdmin(next buffalo.Handler) buffalo.Handler {
+    return func(c buffalo.Context) error {
+        if u := currentUser(c); u == nil || !u.Admin {
+            return c.Error(http.StatusForbidden, errors.New("only admins can do that"))
+        }
+        return next(c)
+    }
+}
+
+// bearerToken returns the API token in the request's Authorization header,
+// if it has one.
+func bearerToken(c buffalo.Context) string {
+    scheme, token, ok := strings.Cut(c.Request().Header.Get("Authorization"), " ")
+    if !ok || !strings.EqualFold(scheme, "Bearer") {
+        return ""
+    }
+    return strings.TrimSpace(token)
+}
+
+// protectFromForgery checks the CSRF token of every request except those
+// authenticated with an API token, or made to the JSON API, which only
+// takes tokens. Browsers never add the Authorization header on their own,
+// so such a request can't be forged from another site, and scripts have
+// no form to take a CSRF token from.
+func protectFromForgery(next buffalo.Handler) buffalo.Handler {
+    protected := csrf.New(next)
+    return func(c buffalo.Context) error {
+        if bearerToken(c) != "" || isAPI(c) {
+            return next(c)
+        }
+        return protected(c)
+    }
+}
+
+// currentUser returns the signed in User. It is only nil on the pages
+// Authorize skips.
+func currentUser(c buffalo.Context) *models.User {
+    u, _ := c.Value("current_user").(*models.User)
+    return u
+}
+
+// AuthNew renders the sign in form. This function is mapped to the path
+// GET /signin
+func AuthNew(c buffalo.Context) error {
+    c.Set("user", &models.User{})
+    return c.Render(http.StatusOK, r.HTML("auth/new.plush.html"))
+}
+
+// AuthCreate signs a User in with their email and password. This
+// function is mapped to the path POST /signin
+func AuthCreate(c buffalo.Context) error {
+    // Allocate an empty User
+    u := &models.User{}
+
+    // Bind the email and password from the html form
+    if err := c.Bind(u); err != nil {
+        return err
+    }
+
+    // Get the DB connection from the context
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    user, err := models.AuthenticateUser(tx, u.Email, u.Password)
+    if errors.Is(err, models.ErrInvalidCredentials) {
+        verrs := validate.NewErrors()
+        verrs.Add("email", T.Translate(c, "auth.invalid"))
+
+        return responder.Wants("html", func(c buffalo.Context) error {
+            // Make the errors available inside the html template
+            c.Set("errors", verrs)
+
+            // Render again the sign in form, keeping the email
+            u.Password = ""
+            c.Set("user", u)
+
+            return c.Render(http.StatusUnprocessableEntity, r.HTML("auth/new.plush.html"))
+        }).Wants("json", func(c buffalo.Context) error {
+            return renderValidationErrors(c, verrs)
+        }).Wants("xml", func(c buffalo.Context) error {
+            return c.Render(http.StatusUnprocessableEntity, r.XML(verrs))
+        }).Respond(c)
+    }
+    if err != nil {
+        return err
+    }
+
+    return signIn(c, user)
+}
+
+// AuthDestroy signs the current User out. This function is mapped to the
+// path DELETE /signout
+func AuthDestroy(c buffalo.Context) error {
+    c.Session().Clear()
+    c.Flash().Add("success", T.Translate(c, "auth.signed_out"))
+    return c.Redirect(http.StatusSeeOther, "/")
+}
+
+// signIn starts a session for user and sends them back to the page they
+// were trying to reach, or to the home page.
+func signIn(c buffalo.Context, user *models.User) error {
+    redirectURL := "/"
+    if u, ok := c.Session().Get("redirect_url").(string); ok && u != "" {
+        redirectURL = u
+    }
+
+    c.Session().Clear()
+    c.Session().Set("current_user_id", user.ID.String())
+
+    // Never send the password back
+    user.Password, user.PasswordConfirmation = "", ""
+
+    return responder.Wants("html", func(c buffalo.Context) error {
+        c.Flash().Add("success", T.Translate(c, "auth.signed_in"))
+        return c.Redirect(http.StatusSeeOther, redirectURL)
+    }).Wants("json", func(c buffalo.Context) error {
+        return c.Render(http.StatusOK, r.JSON(user))
+    }).Wants("xml", func(c buffalo.Context) error {
+        return c.Render(http.StatusOK, r.XML(user))
+    }).Respond(c)
+}
@@ -0,0 +1,97 @@
+package actions
+
+import (
+  "net/http"
+
+  "completion_tracker/models"
+)
+
+func (as *ActionSuite) Test_Authorize() {
+  as.Session.Clear()
+
+  res := as.HTML("/books").Get()
+  as.Equal(http.StatusFound, res.Code)
+  as.Equal("/signin", res.Location())
+
+  as.Equal(http.StatusUnauthorized, as.JSON("/books").Get().Code)
+  as.Equal(http.StatusUnauthorized, as.JSON("/completions").Post(map[string]interface{}{"name": "Dune"}).Code)
+
+  // The home page and the sign up and sign in pages stay public
+  as.Equal(http.StatusOK, as.HTML("/").Get().Code)
+  as.Equal(http.StatusOK, as.HTML("/signin").Get().Code)
+  as.Equal(http.StatusOK, as.HTML("/signup").Get().Code)
+}
+
+func (as *ActionSuite) Test_AuthCreate() {
+  as.Session.Clear()
+
+  // Signing in returns to the page that asked for it
+  as.HTML("/books").Get()
+  res := as.HTML("/signin").Post(map[string]interface{}{
+    "Email":    "Reader@Example.com",
+    "Password": testPassword,
+  })
+  as.Equal(http.StatusSeeOther, res.Code)
+  as.Equal("/books", res.Location())
+  as.Equal(as.user.ID.String(), as.Session.Get("current_user_id"))
+
+  as.Equal(http.StatusOK, as.HTML("/books").Get().Code)
+}
+
+func (as *ActionSuite) Test_AuthCreate_Invalid() {
+  as.Session.Clear()
+
+  for _, email := range []string{"reader@example.com", "nobody@example.com"} {
+    res := as.HTML("/signin").Post(map[string]interface{}{
+      "Email":    email,
+      "Password": "wrong password",
+    })
+    as.Equal(http.StatusUnprocessableEntity, res.Code)
+    as.Contains(res.Body.String(), "Invalid email or password")
+    as.Nil(as.Session.Get("current_user_id"))
+  }
+}
+
+func (as *ActionSuite) Test_AuthDestroy() {
+  res := as.HTML("/signout").Delete()
+  as.Equal(http.StatusSeeOther, res.Code)
+  as.Nil(as.Session.Get("current_user_id"))
+
+  as.Equal(http.StatusFound, as.HTML("/books").Get().Code)
+}
+
+func (as *ActionSuite) Test_UsersCreate() {
+  as.Session.Clear()
+
+  // Nothing but the email, username and password can be posted, so no
+  // one can sign up already linked to someone else's single sign-on
+  res := as.HTML("/users").Post(map[string]interface{}{
+    "Email":                "new@example.com",
+    "Password":             testPassword,
+    "PasswordConfirmation": testPassword,
+    "OIDCIssuer":           "https://id.example.com",
+    "OIDCSubject":          "42",
+  })
+  as.Equal(http.StatusSeeOther, res.Code)
+  as.Equal("/", res.Location())
+
+  u := &models.User{}
+  as.NoError(as.DB.Where("email = ?", "new@example.com").First(u))
+  as.NotEqual(testPassword, u.PasswordHash)
+  as.False(u.OIDCIssuer.Valid)
+  as.False(u.OIDCSubject.Valid)
+  as.Equal(u.ID.String(), as.Session.Get("current_user_id"))
+}
+
+func (as *ActionSuite) Test_UsersCreate_Invalid() {
+  as.Session.Clear()
+
+  res := as.HTML("/users").Post(map[string]interface{}{
+    "Email":                "reader@example.com",
+    "Password":             "short",
+    "PasswordConfirmation": "shorter",
+  })
+  as.Equal(http.StatusUnprocessableEntity, res.Code)
+  as.Contains(res.Body.String(), "There is already an account with that email.")
+  as.Contains(res.Body.String(), "Password confirmation doesn&#39;t match the password.")
+}
@@ -0,0 +1,35 @@
+package actions
+
+import (
+    "strconv"
+
+    "github.com/gobuffalo/buffalo/binding"
+    "github.com/gobuffalo/nulls"
+    "github.com/gofrs/uuid"
+)
+
+func init() {
+    // The series select posts an empty or nil ID for "No series"
+    binding.RegisterCustomDecoder(func(vals []string) (interface{}, error) {
+        if len(vals) == 0 || vals[0] == "" || vals[0] == uuid.Nil.String() {
+            return nulls.UUID{}, nil
+        }
+        id, err := uuid.FromString(vals[0])
+        if err != nil {
+            return nil, err
+        }
+        return nulls.NewUUID(id), nil
+    }, []interface{}{nulls.UUID{}}, nil)
+
+    // The rating select posts an empty value for "Unrated"
+    binding.RegisterCustomDecoder(func(vals []string) (interface{}, error) {
+        if len(vals) == 0 || vals[0] == "" {
+            return nulls.Float64{}, nil
+        }
+        f, err := strconv.ParseFloat(vals[0], 64)
+        if err != nil {
+            return nil, err
+        }
+        return nulls.NewFloat64(f), nil
+    }, []interface{}{nulls.Float64{}}, nil)
+}
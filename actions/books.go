@@ -1,9 +1,14 @@
 package actions
 
 import (
+    "fmt"
     "net/http"
 
+    "completion_tracker/models"
+
     "github.com/gobuffalo/buffalo"
+    "github.com/gobuffalo/pop/v6"
+    "github.com/gobuffalo/x/responder"
 )
 
 type BooksResource struct{
@@ -11,38 +16,276 @@ type BooksResource struct{
 }
 
 
-// List default implementation.
+// List gets all Book completions. This function is mapped to the path
+// GET /books
 func (v BooksResource) List(c buffalo.Context) error {
-    return c.Render(http.StatusOK, r.String("Book#List"))
+    // Get the DB connection from the context
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    completions := &models.Completions{}
+
+    // The Book completions the User can see, leaving out the trash
+    q := tx.Scope(visibleCompletions(c))
+    q = q.Where("type = ?", models.CompletionTypeBook)
+
+    // Optionally filter by lifecycle status, rating, completion date and
+    // progress
+    q, err := filterCompletions(c, q)
+    if err != nil {
+        return err
+    }
+
+    return responder.Wants("html", func(c buffalo.Context) error {
+        // Sort and retrieve the page asked for by the "page" and
+        // "per_page" params
+        pagination, err := paginate(c, q, completions)
+        if err != nil {
+            return err
+        }
+        c.Set("pagination", pagination)
+        c.Set("statuses", models.GetStatuses())
+        c.Set("completions", completions)
+        return c.Render(http.StatusOK, r.HTML("books/index.plush.html"))
+    }).Wants("json", func(c buffalo.Context) error {
+        // JSON clients page through by cursor
+        if err := paginateByCursor(c, q, completions); err != nil {
+            return err
+        }
+        return c.Render(200, r.JSON(completions))
+    }).Wants("xml", func(c buffalo.Context) error {
+        if _, err := paginate(c, q, completions); err != nil {
+            return err
+        }
+        return c.Render(200, r.XML(completions))
+    }).Respond(c)
 }
 
-// Show default implementation.
+// Show gets the data for one Book completion. This function is mapped to
+// the path GET /books/{book_id}
 func (v BooksResource) Show(c buffalo.Context) error {
-    return c.Render(http.StatusOK, r.String("Book#Show"))
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    completion := &models.Completion{}
+    if err := tx.Eager("StatusTransitions", "Runs", "Series", "Group", "Tags").Scope(visibleCompletions(c)).Find(completion, c.Param("book_id")); err != nil {
+        return c.Error(http.StatusNotFound, err)
+    }
+
+    // Ensure this is actually a Book completion
+    if completion.Type != models.CompletionTypeBook {
+        return c.Error(http.StatusNotFound, fmt.Errorf("completion is not a book"))
+    }
+
+    // Only the latest changes, the rest are paged through at its revisions path
+    if err := loadRecentRevisions(tx, completion); err != nil {
+        return err
+    }
+
+    return responder.Wants("html", func(c buffalo.Context) error {
+        c.Set("completion", completion)
+        return c.Render(http.StatusOK, r.HTML("books/show.plush.html"))
+    }).Wants("json", func(c buffalo.Context) error {
+        return c.Render(200, r.JSON(completion))
+    }).Wants("xml", func(c buffalo.Context) error {
+        return c.Render(200, r.XML(completion))
+    }).Respond(c)
 }
 
-// Create default implementation.
+// Create adds a Book completion to the DB. This function is mapped to the
+// path POST /books
 func (v BooksResource) Create(c buffalo.Context) error {
-    return c.Render(http.StatusOK, r.String("Book#Create"))
+    completion := &models.Completion{
+        Type: models.CompletionTypeBook,
+    }
+
+    if err := c.Bind(completion); err != nil {
+        return err
+    }
+
+    // Ensure type is set correctly
+    completion.Type = models.CompletionTypeBook
+
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    // It always belongs to the signed in User, whatever was posted
+    if err := setOwner(c, tx, completion); err != nil {
+        return err
+    }
+
+    verrs, err := tx.ValidateAndCreate(completion)
+    if err != nil {
+        return err
+    }
+
+    if verrs.HasAny() {
+        return responder.Wants("html", func(c buffalo.Context) error {
+            c.Set("errors", verrs)
+            c.Set("completion", completion)
+            c.Set("statuses", models.GetStatuses())
+            if err := setCompletionOptions(c); err != nil {
+                return err
+            }
+            return c.Render(http.StatusUnprocessableEntity, r.HTML("books/new.plush.html"))
+        }).Wants("json", func(c buffalo.Context) error {
+            return renderValidationErrors(c, verrs)
+        }).Wants("xml", func(c buffalo.Context) error {
+            return c.Render(http.StatusUnprocessableEntity, r.XML(verrs))
+        }).Respond(c)
+    }
+
+    return responder.Wants("html", func(c buffalo.Context) error {
+        c.Flash().Add("success", T.Translate(c, "book.created.success"))
+        return c.Redirect(http.StatusSeeOther, "/books/%v", completion.ID)
+    }).Wants("json", func(c buffalo.Context) error {
+        return c.Render(http.StatusCreated, r.JSON(completion))
+    }).Wants("xml", func(c buffalo.Context) error {
+        return c.Render(http.StatusCreated, r.XML(completion))
+    }).Respond(c)
 }
 
-// Update default implementation.
+// Update changes a Book completion in the DB. This function is mapped to
+// the path PUT /books/{book_id}
 func (v BooksResource) Update(c buffalo.Context) error {
-    return c.Render(http.StatusOK, r.String("Book#Update"))
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    completion := &models.Completion{}
+    if err := tx.Scope(editableCompletions(c)).Find(completion, c.Param("book_id")); err != nil {
+        return c.Error(http.StatusNotFound, err)
+    }
+
+    if completion.Type != models.CompletionTypeBook {
+        return c.Error(http.StatusNotFound, fmt.Errorf("completion is not a book"))
+    }
+
+    // Remember which it is, and how it was shared, which only its owner
+    // can change
+    persisted := *completion
+
+    if err := c.Bind(completion); err != nil {
+        return err
+    }
+
+    // Ensure type remains Book
+    completion.Type = models.CompletionTypeBook
+
+    // It stays the Completion that was found, whatever id was posted
+    keepIdentity(completion, persisted)
+
+    // It keeps its owner, whoever edits it
+    if err := keepOwner(c, tx, completion, persisted); err != nil {
+        return err
+    }
+
+    verrs, err := tx.ValidateAndUpdate(completion)
+    if err != nil {
+        return err
+    }
+
+    if verrs.HasAny() {
+        return responder.Wants("html", func(c buffalo.Context) error {
+            c.Set("errors", verrs)
+            c.Set("completion", completion)
+            c.Set("statuses", models.GetStatuses())
+            if err := setCompletionOptions(c); err != nil {
+                return err
+            }
+            return c.Render(http.StatusUnprocessableEntity, r.HTML("books/edit.plush.html"))
+        }).Wants("json", func(c buffalo.Context) error {
+            return renderValidationErrors(c, verrs)
+        }).Wants("xml", func(c buffalo.Context) error {
+            return c.Render(http.StatusUnprocessableEntity, r.XML(verrs))
+        }).Respond(c)
+    }
+
+    return responder.Wants("html", func(c buffalo.Context) error {
+        c.Flash().Add("success", T.Translate(c, "book.updated.success"))
+        return c.Redirect(http.StatusSeeOther, "/books/%v", completion.ID)
+    }).Wants("json", func(c buffalo.Context) error {
+        return c.Render(http.StatusOK, r.JSON(completion))
+    }).Wants("xml", func(c buffalo.Context) error {
+        return c.Render(http.StatusOK, r.XML(completion))
+    }).Respond(c)
 }
 
-// Destroy default implementation.
+// Destroy moves a Book completion to the trash. This function is mapped
+// to the path DELETE /books/{book_id}
 func (v BooksResource) Destroy(c buffalo.Context) error {
-    return c.Render(http.StatusOK, r.String("Book#Destroy"))
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    completion := &models.Completion{}
+    if err := tx.Scope(ownCompletions(c)).Find(completion, c.Param("book_id")); err != nil {
+        return c.Error(http.StatusNotFound, err)
+    }
+
+    if completion.Type != models.CompletionTypeBook {
+        return c.Error(http.StatusNotFound, fmt.Errorf("completion is not a book"))
+    }
+
+    if err := completion.Trash(tx); err != nil {
+        return err
+    }
+
+    return responder.Wants("html", func(c buffalo.Context) error {
+        c.Flash().Add("success", T.Translate(c, "book.destroyed.success"))
+        return c.Redirect(http.StatusSeeOther, "/books")
+    }).Wants("json", func(c buffalo.Context) error {
+        return c.Render(http.StatusOK, r.JSON(completion))
+    }).Wants("xml", func(c buffalo.Context) error {
+        return c.Render(http.StatusOK, r.XML(completion))
+    }).Respond(c)
 }
 
-// New default implementation.
+// New renders the form for creating a new Book completion.
+// This function is mapped to the path GET /books/new
 func (v BooksResource) New(c buffalo.Context) error {
-    return c.Render(http.StatusOK, r.String("Book#New"))
+    completion := &models.Completion{
+        Type: models.CompletionTypeBook,
+    }
+    c.Set("completion", completion)
+    c.Set("statuses", models.GetStatuses())
+    if err := setCompletionOptions(c); err != nil {
+        return err
+    }
+
+    return c.Render(http.StatusOK, r.HTML("books/new.plush.html"))
 }
 
-// Edit default implementation.
+// Edit renders a edit form for a Book completion. This function is
+// mapped to the path GET /books/{book_id}/edit
 func (v BooksResource) Edit(c buffalo.Context) error {
-    return c.Render(http.StatusOK, r.String("Book#Edit"))
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    completion := &models.Completion{}
+    if err := tx.Eager("Tags").Scope(editableCompletions(c)).Find(completion, c.Param("book_id")); err != nil {
+        return c.Error(http.StatusNotFound, err)
+    }
+
+    if completion.Type != models.CompletionTypeBook {
+        return c.Error(http.StatusNotFound, fmt.Errorf("completion is not a book"))
+    }
+
+    c.Set("completion", completion)
+    c.Set("statuses", models.GetStatuses())
+    if err := setCompletionOptions(c); err != nil {
+        return err
+    }
+    return c.Render(http.StatusOK, r.HTML("books/edit.plush.html"))
 }
 
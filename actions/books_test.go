@@ -1,36 +1,310 @@
 package actions
 
 import (
-  "testing"
+  "fmt"
+  "net/http"
+  "regexp"
+  "time"
 
-  "github.com/stretchr/testify/require"
+  "completion_tracker/models"
+
+  "github.com/gobuffalo/nulls"
 )
 
+func (as *ActionSuite) createBook(name string, pagesRead, totalPages int) *models.Completion {
+  book := &models.Completion{
+    Name:        name,
+    Type:        models.CompletionTypeBook,
+    Completions: pagesRead,
+    Target:      totalPages,
+    CompletedAt: time.Now(),
+    UserID:      nulls.NewUUID(as.user.ID),
+  }
+  verrs, err := as.DB.ValidateAndCreate(book)
+  as.NoError(err)
+  as.False(verrs.HasAny())
+  return book
+}
+
 func (as *ActionSuite) Test_BooksResource_List() {
-  as.Fail("Not Implemented!")
+  as.createBook("Dune", 206, 412)
+  as.NoError(as.DB.Create(&models.Completion{Name: "Halo", Type: models.CompletionTypeVideoGame, Completions: 12, CompletedAt: time.Now(), UserID: nulls.NewUUID(as.user.ID)}))
+
+  res := as.HTML("/books").Get()
+  as.Equal(http.StatusOK, res.Code)
+  as.Contains(res.Body.String(), "Dune")
+  as.Contains(res.Body.String(), "206 pages of 412 pages")
+  as.NotContains(res.Body.String(), "Halo")
+
+  books := models.Completions{}
+  jres := as.JSON("/books").Get()
+  as.Equal(http.StatusOK, jres.Code)
+  as.Contains(jres.Body.String(), `"percent_complete":50`)
+  as.Contains(jres.Body.String(), `"remaining":206`)
+  jres.Bind(&books)
+  as.Len(books, 1)
 }
 
 func (as *ActionSuite) Test_BooksResource_Show() {
-  as.Fail("Not Implemented!")
+  book := as.createBook("Dune", 206, 412)
+
+  res := as.HTML("/books/%s", book.ID).Get()
+  as.Equal(http.StatusOK, res.Code)
+  as.Contains(res.Body.String(), "206 pages to go")
+
+  game := &models.Completion{Name: "Halo", Type: models.CompletionTypeVideoGame, Completions: 12, CompletedAt: time.Now(), UserID: nulls.NewUUID(as.user.ID)}
+  as.NoError(as.DB.Create(game))
+  res = as.HTML("/books/%s", game.ID).Get()
+  as.Equal(http.StatusNotFound, res.Code)
+}
+
+func (as *ActionSuite) Test_BooksResource_OtherUsers() {
+  book := as.createBook("Dune", 206, 412)
+
+  as.signIn(as.createUser("stranger@example.com"))
+
+  res := as.HTML("/books").Get()
+  as.Equal(http.StatusOK, res.Code)
+  as.NotContains(res.Body.String(), "Dune")
+
+  res = as.HTML("/books/%s", book.ID).Get()
+  as.Equal(http.StatusNotFound, res.Code)
+
+  res = as.HTML("/books/%s/edit", book.ID).Get()
+  as.Equal(http.StatusNotFound, res.Code)
 }
 
 func (as *ActionSuite) Test_BooksResource_Create() {
-  as.Fail("Not Implemented!")
+  res := as.JSON("/books").Post(map[string]interface{}{
+    "name":         "Dune",
+    "completions":  100,
+    "target":       412,
+    "completed_at": time.Now(),
+  })
+  as.Equal(http.StatusCreated, res.Code)
+
+  book := &models.Completion{}
+  as.NoError(as.DB.Where("name = ?", "Dune").First(book))
+  as.Equal(models.CompletionTypeBook, book.Type)
+  as.Equal(412, book.Target)
+
+  res = as.JSON("/books").Post(map[string]interface{}{
+    "name":         "Too Many Pages",
+    "completions":  500,
+    "target":       412,
+    "completed_at": time.Now(),
+  })
+  as.Equal(http.StatusUnprocessableEntity, res.Code)
 }
 
 func (as *ActionSuite) Test_BooksResource_Update() {
-  as.Fail("Not Implemented!")
+  book := as.createBook("Dune", 100, 412)
+
+  res := as.HTML("/books/%s", book.ID).Put(map[string]interface{}{
+    "Name":        "Dune",
+    "Completions": 412,
+    "Target":      412,
+    "CompletedAt": book.CompletedAt.Format("2006-01-02T15:04"),
+  })
+  as.Equal(http.StatusSeeOther, res.Code)
+  as.Equal(fmt.Sprintf("/books/%s", book.ID), res.Location())
+
+  as.NoError(as.DB.Reload(book))
+  as.Equal(412, book.Completions)
 }
 
 func (as *ActionSuite) Test_BooksResource_Destroy() {
-  as.Fail("Not Implemented!")
+  book := as.createBook("Dune", 206, 412)
+
+  res := as.HTML("/books/%s", book.ID).Delete()
+  as.Equal(http.StatusSeeOther, res.Code)
+
+  count, err := as.DB.Scope(models.NotTrashed).Where("id = ?", book.ID).Count(&models.Completion{})
+  as.NoError(err)
+  as.Equal(0, count)
 }
 
 func (as *ActionSuite) Test_BooksResource_New() {
-  as.Fail("Not Implemented!")
+  res := as.HTML("/books/new").Get()
+  as.Equal(http.StatusOK, res.Code)
+  as.Contains(res.Body.String(), "Total Pages")
 }
 
 func (as *ActionSuite) Test_BooksResource_Edit() {
-  as.Fail("Not Implemented!")
+  book := as.createBook("Dune", 206, 412)
+
+  res := as.HTML("/books/%s/edit", book.ID).Get()
+  as.Equal(http.StatusOK, res.Code)
+  as.Contains(res.Body.String(), "Dune")
 }
 
+func (as *ActionSuite) Test_BooksResource_Rating() {
+  dune := as.createBook("Dune", 206, 412)
+  dune.Rating = nulls.NewFloat64(4.5)
+  dune.Review = "A *classic*."
+  as.NoError(as.DB.Update(dune))
+
+  emma := as.createBook("Emma", 10, 474)
+  emma.Rating = nulls.NewFloat64(2)
+  as.NoError(as.DB.Update(emma))
+
+  as.createBook("Unrated", 1, 100)
+
+  books := models.Completions{}
+  res := as.JSON("/books?sort=rating").Get()
+  as.Equal(http.StatusOK, res.Code)
+  res.Bind(&books)
+  as.Len(books, 3)
+  as.Equal("Dune", books[0].Name)
+  as.Equal("Emma", books[1].Name)
+  as.Equal("Unrated", books[2].Name)
+
+  books = models.Completions{}
+  res = as.JSON("/books?sort=rating&order=asc").Get()
+  res.Bind(&books)
+  as.Equal("Emma", books[0].Name)
+  as.Equal("Unrated", books[2].Name)
+
+  books = models.Completions{}
+  res = as.JSON("/books?min_rating=3").Get()
+  as.Contains(res.Body.String(), `"rating":4.5`)
+  res.Bind(&books)
+  as.Len(books, 1)
+
+  as.Equal(http.StatusBadRequest, as.JSON("/books?min_rating=lots").Get().Code)
+  as.Equal(http.StatusBadRequest, as.JSON("/books?max_rating=6").Get().Code)
+  as.Equal(http.StatusBadRequest, as.JSON("/books?rating=3.2").Get().Code)
+  as.Equal(http.StatusBadRequest, as.JSON("/books?min_rating=4&max_rating=2").Get().Code)
+  as.Equal(http.StatusBadRequest, as.JSON("/books?sort=venue").Get().Code)
+
+  html := as.HTML("/books/%s", dune.ID).Get()
+  as.Contains(html.Body.String(), "<em>classic</em>")
+  as.Contains(html.Body.String(), "fa-star-half-alt")
+
+  update := as.HTML("/books/%s", emma.ID).Put(map[string]interface{}{
+    "Name":        "Emma",
+    "Completions": 10,
+    "Target":      474,
+    "CompletedAt": emma.CompletedAt.Format("2006-01-02T15:04"),
+    "Rating":      "",
+  })
+  as.Equal(http.StatusSeeOther, update.Code)
+  as.NoError(as.DB.Reload(emma))
+  as.False(emma.Rating.Valid)
+}
+
+func (as *ActionSuite) Test_BooksResource_FilterAndSort() {
+  books := map[string]*models.Completion{
+    "Dune":    as.createBook("Dune", 206, 412),
+    "Emma":    as.createBook("Emma", 474, 474),
+    "Solaris": as.createBook("Solaris", 10, 100),
+  }
+  for name, day := range map[string]string{"Dune": "2024-03-10", "Emma": "2024-06-01", "Solaris": "2025-01-05"} {
+    completedAt, err := time.Parse("2006-01-02 15:04", day+" 18:30")
+    as.NoError(err)
+    as.NoError(as.DB.RawQuery("UPDATE completions SET completed_at = ? WHERE id = ?", completedAt, books[name].ID).Exec())
+  }
+
+  names := func(path string) []string {
+    res := as.JSON(path).Get()
+    as.Equal(http.StatusOK, res.Code, path)
+    list := models.Completions{}
+    res.Bind(&list)
+    var names []string
+    for _, c := range list {
+      names = append(names, c.Name)
+    }
+    return names
+  }
+
+  // The most recently completed come first unless asked otherwise
+  as.Equal([]string{"Solaris", "Emma", "Dune"}, names("/books"))
+  as.Equal([]string{"Dune", "Emma", "Solaris"}, names("/books?sort=name"))
+  as.Equal([]string{"Solaris", "Emma", "Dune"}, names("/books?sort=name&order=desc"))
+  as.Equal([]string{"Emma", "Dune", "Solaris"}, names("/books?sort=completions"))
+  as.Equal([]string{"Dune", "Emma", "Solaris"}, names("/books?sort=completed_at&order=asc"))
+  as.Len(names("/books?sort=created_at"), 3)
+
+  // Date ranges include the whole of both days
+  as.Equal([]string{"Emma", "Dune"}, names("/books?completed_from=2024-03-10&completed_to=2024-06-01"))
+  as.Equal([]string{"Solaris"}, names("/books?completed_from=2024-06-02"))
+
+  as.Equal([]string{"Emma", "Dune"}, names("/books?min_progress=50"))
+  as.Equal([]string{"Solaris", "Dune"}, names("/books?max_progress=50"))
+  as.Equal([]string{"Emma"}, names("/completions?type=book&min_progress=100"))
+
+  for _, path := range []string{
+    "/books?completed_from=yesterday",
+    "/books?completed_from=2024-06-01&completed_to=2024-03-10",
+    "/books?min_progress=101",
+    "/books?min_progress=half",
+    "/books?min_progress=60&max_progress=50",
+    "/books?sort=name&order=sideways",
+  } {
+    as.Equal(http.StatusBadRequest, as.JSON(path).Get().Code, path)
+  }
+
+  res := as.HTML("/books?sort=name&min_progress=50").Get()
+  as.Equal(http.StatusOK, res.Code)
+  as.Contains(res.Body.String(), `name="completed_from"`)
+  as.Contains(res.Body.String(), `<option value="name" selected>`)
+}
+
+func (as *ActionSuite) Test_BooksResource_Cursor() {
+  start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+  for i, name := range []string{"A", "B", "C", "D", "E"} {
+    book := as.createBook(name, 1, 10)
+    as.NoError(as.DB.RawQuery("UPDATE completions SET completed_at = ? WHERE id = ?", start.AddDate(0, 0, i), book.ID).Exec())
+  }
+
+  link := regexp.MustCompile(`<([^>]+)>; rel="(\w+)"`)
+  page := func(path, total string) ([]string, map[string]string) {
+    res := as.JSON(path).Get()
+    as.Equal(http.StatusOK, res.Code, path)
+    as.Equal(total, res.Header().Get("X-Total-Count"))
+
+    links := map[string]string{}
+    for _, m := range link.FindAllStringSubmatch(res.Header().Get("Link"), -1) {
+      links[m[2]] = m[1]
+    }
+
+    list := models.Completions{}
+    res.Bind(&list)
+    var names []string
+    for _, c := range list {
+      names = append(names, c.Name)
+    }
+    return names, links
+  }
+
+  names, links := page("/books?per_page=2", "5")
+  as.Equal([]string{"E", "D"}, names)
+  as.Equal("/books?per_page=2", links["first"])
+  as.NotContains(links, "prev")
+
+  // A book added in the meantime doesn't shift the pages after it
+  as.createBook("F", 1, 10)
+  as.NoError(as.DB.RawQuery("UPDATE completions SET completed_at = ? WHERE name = ?", start.AddDate(0, 1, 0), "F").Exec())
+
+  names, links = page(links["next"], "6")
+  as.Equal([]string{"C", "B"}, names)
+  prev := links["prev"]
+
+  names, links = page(links["next"], "6")
+  as.Equal([]string{"A"}, names)
+  as.NotContains(links, "next")
+
+  names, links = page(prev, "6")
+  as.Equal([]string{"E", "D"}, names)
+  as.Contains(links, "prev")
+  as.Contains(links, "next")
+
+  // Pages by number still work, with links to them
+  res := as.JSON("/books?sort=name&per_page=4").Get()
+  as.Equal(http.StatusOK, res.Code)
+  as.Contains(res.Header().Get("Link"), `</books?page=2&per_page=4&sort=name>; rel="next"`)
+
+  as.Equal(http.StatusBadRequest, as.JSON("/books?cursor=nonsense").Get().Code)
+  as.Equal(http.StatusBadRequest, as.JSON("/books?per_page=0").Get().Code)
+  as.Equal(http.StatusBadRequest, as.JSON("/books?per_page=1000").Get().Code)
+}
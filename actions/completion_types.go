@@ -0,0 +1,289 @@
+package actions
+
+import (
+    "fmt"
+    "net/http"
+
+    "completion_tracker/models"
+
+    "github.com/gobuffalo/buffalo"
+    "github.com/gobuffalo/pop/v6"
+    "github.com/gobuffalo/x/responder"
+)
+
+// loadCompletionTypes makes sure the completion types are loaded before
+// a request uses them. They are kept between requests, and reloaded
+// straight away by the admin screen when it changes one.
+func loadCompletionTypes(next buffalo.Handler) buffalo.Handler {
+    return func(c buffalo.Context) error {
+        tx, ok := c.Value("tx").(*pop.Connection)
+        if !ok {
+            return fmt.Errorf("no transaction found")
+        }
+
+        if err := models.EnsureCompletionTypes(tx); err != nil {
+            return err
+        }
+        return next(c)
+    }
+}
+
+// CompletionTypesResource is the admin screen for the kinds of thing that
+// can be completed. It is mounted at /admin/completion_types.
+type CompletionTypesResource struct{
+    buffalo.Resource
+}
+
+// List gets all completion types, the built-in ones first. This function
+// is mapped to the path GET /admin/completion_types
+func (v CompletionTypesResource) List(c buffalo.Context) error {
+    types := models.GetCompletionTypes()
+
+    return responder.Wants("html", func(c buffalo.Context) error {
+        return c.Render(http.StatusOK, r.HTML("completion_types/index.plush.html"))
+    }).Wants("json", func(c buffalo.Context) error {
+        return c.Render(200, r.JSON(types))
+    }).Wants("xml", func(c buffalo.Context) error {
+        return c.Render(200, r.XML(types))
+    }).Respond(c)
+}
+
+// Show gets the data for one completion type. This function is mapped to
+// the path GET /admin/completion_types/{completion_type_id}
+func (v CompletionTypesResource) Show(c buffalo.Context) error {
+    // Get the DB connection from the context
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    // Allocate an empty TypeDefinition
+    definition := &models.TypeDefinition{}
+
+    // To find the TypeDefinition the parameter completion_type_id is used.
+    if err := tx.Find(definition, c.Param("completion_type_id")); err != nil {
+        return c.Error(http.StatusNotFound, err)
+    }
+
+    return responder.Wants("html", func(c buffalo.Context) error {
+        return c.Redirect(http.StatusSeeOther, "/admin/completion_types/%v/edit", definition.ID)
+    }).Wants("json", func(c buffalo.Context) error {
+        return c.Render(200, r.JSON(definition))
+    }).Wants("xml", func(c buffalo.Context) error {
+        return c.Render(200, r.XML(definition))
+    }).Respond(c)
+}
+
+// New renders the form for adding a completion type. This function is
+// mapped to the path GET /admin/completion_types/new
+func (v CompletionTypesResource) New(c buffalo.Context) error {
+    c.Set("definition", &models.TypeDefinition{})
+
+    return c.Render(http.StatusOK, r.HTML("completion_types/new.plush.html"))
+}
+
+// Create adds a completion type to the DB. This function is mapped to the
+// path POST /admin/completion_types
+func (v CompletionTypesResource) Create(c buffalo.Context) error {
+    // Allocate an empty TypeDefinition
+    definition := &models.TypeDefinition{}
+
+    // Bind definition to the html form elements
+    if err := c.Bind(definition); err != nil {
+        return err
+    }
+
+    // Only the migrations add built-in types
+    definition.BuiltIn = false
+
+    // Get the DB connection from the context
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    // Validate the data from the html form
+    verrs, err := tx.ValidateAndCreate(definition)
+    if err != nil {
+        return err
+    }
+
+    if verrs.HasAny() {
+        return responder.Wants("html", func(c buffalo.Context) error {
+            // Make the errors available inside the html template
+            c.Set("errors", verrs)
+
+            // Render again the new.html template that the user can
+            // correct the input.
+            c.Set("definition", definition)
+
+            return c.Render(http.StatusUnprocessableEntity, r.HTML("completion_types/new.plush.html"))
+        }).Wants("json", func(c buffalo.Context) error {
+            return renderValidationErrors(c, verrs)
+        }).Wants("xml", func(c buffalo.Context) error {
+            return c.Render(http.StatusUnprocessableEntity, r.XML(verrs))
+        }).Respond(c)
+    }
+
+    // Every request sees the new type from now on
+    if err := models.LoadCompletionTypes(tx); err != nil {
+        return err
+    }
+
+    return responder.Wants("html", func(c buffalo.Context) error {
+        // If there are no errors set a success message
+        c.Flash().Add("success", T.Translate(c, "completion_type.created.success"))
+
+        // and redirect to the list of types
+        return c.Redirect(http.StatusSeeOther, "/admin/completion_types")
+    }).Wants("json", func(c buffalo.Context) error {
+        return c.Render(http.StatusCreated, r.JSON(definition))
+    }).Wants("xml", func(c buffalo.Context) error {
+        return c.Render(http.StatusCreated, r.XML(definition))
+    }).Respond(c)
+}
+
+// Edit renders a edit form for a completion type. This function is mapped
+// to the path GET /admin/completion_types/{completion_type_id}/edit
+func (v CompletionTypesResource) Edit(c buffalo.Context) error {
+    // Get the DB connection from the context
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    // Allocate an empty TypeDefinition
+    definition := &models.TypeDefinition{}
+
+    if err := tx.Find(definition, c.Param("completion_type_id")); err != nil {
+        return c.Error(http.StatusNotFound, err)
+    }
+
+    c.Set("definition", definition)
+    return c.Render(http.StatusOK, r.HTML("completion_types/edit.plush.html"))
+}
+
+// Update changes a completion type in the DB. Renaming a type renames it
+// on its completions too. This function is mapped to the path
+// PUT /admin/completion_types/{completion_type_id}
+func (v CompletionTypesResource) Update(c buffalo.Context) error {
+    // Get the DB connection from the context
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    // Allocate an empty TypeDefinition
+    definition := &models.TypeDefinition{}
+
+    if err := tx.Find(definition, c.Param("completion_type_id")); err != nil {
+        return c.Error(http.StatusNotFound, err)
+    }
+
+    // Bind TypeDefinition to the html form elements, keeping which type it
+    // is and whether it is built in
+    persisted := *definition
+    if err := c.Bind(definition); err != nil {
+        return err
+    }
+    definition.ID = persisted.ID
+    definition.BuiltIn = persisted.BuiltIn
+    definition.CreatedAt = persisted.CreatedAt
+
+    verrs, err := tx.ValidateAndUpdate(definition)
+    if err != nil {
+        return err
+    }
+
+    if verrs.HasAny() {
+        return responder.Wants("html", func(c buffalo.Context) error {
+            // Make the errors available inside the html template
+            c.Set("errors", verrs)
+
+            // Render again the edit.html template that the user can
+            // correct the input.
+            c.Set("definition", definition)
+
+            return c.Render(http.StatusUnprocessableEntity, r.HTML("completion_types/edit.plush.html"))
+        }).Wants("json", func(c buffalo.Context) error {
+            return renderValidationErrors(c, verrs)
+        }).Wants("xml", func(c buffalo.Context) error {
+            return c.Render(http.StatusUnprocessableEntity, r.XML(verrs))
+        }).Respond(c)
+    }
+
+    // Every request sees the change from now on
+    if err := models.LoadCompletionTypes(tx); err != nil {
+        return err
+    }
+
+    return responder.Wants("html", func(c buffalo.Context) error {
+        // If there are no errors set a success message
+        c.Flash().Add("success", T.Translate(c, "completion_type.updated.success"))
+
+        // and redirect to the list of types
+        return c.Redirect(http.StatusSeeOther, "/admin/completion_types")
+    }).Wants("json", func(c buffalo.Context) error {
+        return c.Render(http.StatusOK, r.JSON(definition))
+    }).Wants("xml", func(c buffalo.Context) error {
+        return c.Render(http.StatusOK, r.XML(definition))
+    }).Respond(c)
+}
+
+// Destroy removes a completion type that is neither built in nor in use.
+// This function is mapped to the path
+// DELETE /admin/completion_types/{completion_type_id}
+func (v CompletionTypesResource) Destroy(c buffalo.Context) error {
+    // Get the DB connection from the context
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    // Allocate an empty TypeDefinition
+    definition := &models.TypeDefinition{}
+
+    // To find the TypeDefinition the parameter completion_type_id is used.
+    if err := tx.Find(definition, c.Param("completion_type_id")); err != nil {
+        return c.Error(http.StatusNotFound, err)
+    }
+
+    verrs, err := definition.ValidateDestroy(tx)
+    if err != nil {
+        return err
+    }
+
+    if verrs.HasAny() {
+        return responder.Wants("html", func(c buffalo.Context) error {
+            // The type is removed from a button on the list, so report
+            // the problem there.
+            c.Flash().Add("danger", verrs.Error())
+            return c.Redirect(http.StatusSeeOther, "/admin/completion_types")
+        }).Wants("json", func(c buffalo.Context) error {
+            return renderValidationErrors(c, verrs)
+        }).Wants("xml", func(c buffalo.Context) error {
+            return c.Render(http.StatusUnprocessableEntity, r.XML(verrs))
+        }).Respond(c)
+    }
+
+    if err := tx.Destroy(definition); err != nil {
+        return err
+    }
+
+    // Every request stops seeing the type from now on
+    if err := models.LoadCompletionTypes(tx); err != nil {
+        return err
+    }
+
+    return responder.Wants("html", func(c buffalo.Context) error {
+        // If there are no errors set a flash message
+        c.Flash().Add("success", T.Translate(c, "completion_type.destroyed.success"))
+
+        // Redirect to the list of types
+        return c.Redirect(http.StatusSeeOther, "/admin/completion_types")
+    }).Wants("json", func(c buffalo.Context) error {
+        return c.Render(http.StatusOK, r.JSON(definition))
+    }).Wants("xml", func(c buffalo.Context) error {
+        return c.Render(http.StatusOK, r.XML(definition))
+    }).Respond(c)
+}
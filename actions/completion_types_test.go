@@ -0,0 +1,94 @@
+package actions
+
+import (
+  "net/http"
+  "time"
+
+  "completion_tracker/models"
+)
+
+func (as *ActionSuite) Test_CompletionTypesResource_List() {
+  res := as.HTML("/admin/completion_types").Get()
+  as.Equal(http.StatusOK, res.Code)
+  as.Contains(res.Body.String(), "Audio Book")
+
+  types := models.TypeDefinitions{}
+  jres := as.JSON("/admin/completion_types").Get()
+  as.Equal(http.StatusOK, jres.Code)
+  jres.Bind(&types)
+  as.Len(types, 5)
+}
+
+func (as *ActionSuite) Test_CompletionTypesResource_AdminOnly() {
+  // The first user to sign up is the admin
+  as.True(as.user.Admin)
+  as.Contains(as.HTML("/").Get().Body.String(), "Completion Types")
+
+  // and no one else can manage the types
+  stranger := as.createUser("stranger@example.com")
+  as.False(stranger.Admin)
+  as.signIn(stranger)
+
+  as.NotContains(as.HTML("/").Get().Body.String(), "Completion Types")
+  as.Equal(http.StatusForbidden, as.HTML("/admin/completion_types").Get().Code)
+  res := as.JSON("/admin/completion_types").Post(map[string]interface{}{
+    "name":          "Podcast",
+    "unit_singular": "episode",
+    "unit_plural":   "episodes",
+  })
+  as.Equal(http.StatusForbidden, res.Code)
+
+  count, err := as.DB.Where("name = ?", "Podcast").Count(&models.TypeDefinition{})
+  as.NoError(err)
+  as.Equal(0, count)
+}
+
+func (as *ActionSuite) Test_CompletionTypesResource_Create() {
+  res := as.HTML("/admin/completion_types").Post(map[string]interface{}{
+    "Name":         "Podcast",
+    "UnitSingular": "episode",
+    "UnitPlural":   "episodes",
+    "Icon":         "🎙️",
+  })
+  as.Equal(http.StatusSeeOther, res.Code)
+
+  // The new type can be used straight away, without a code change
+  jres := as.JSON("/completions").Post(map[string]interface{}{
+    "name":         "Serial",
+    "type":         "Podcast",
+    "completions":  3,
+    "completed_at": time.Now(),
+  })
+  as.Equal(http.StatusCreated, jres.Code)
+
+  list := as.HTML("/completions?type=podcast").Get()
+  as.Equal(http.StatusOK, list.Code)
+  as.Contains(list.Body.String(), "Serial")
+  as.Contains(list.Body.String(), "3 episodes")
+
+  as.Equal(http.StatusBadRequest, as.HTML("/completions?type=opera").Get().Code)
+
+  jres = as.JSON("/completions").Post(map[string]interface{}{
+    "name":         "Carmen",
+    "type":         "Opera",
+    "completed_at": time.Now(),
+  })
+  as.Equal(http.StatusUnprocessableEntity, jres.Code)
+}
+
+func (as *ActionSuite) Test_CompletionTypesResource_Destroy() {
+  podcast := &models.TypeDefinition{Name: "Podcast", UnitSingular: "episode", UnitPlural: "episodes"}
+  as.NoError(as.DB.Create(podcast))
+
+  book := &models.TypeDefinition{}
+  as.NoError(as.DB.Where("name = ?", models.CompletionTypeBook).First(book))
+  res := as.JSON("/admin/completion_types/%s", book.ID).Delete()
+  as.Equal(http.StatusUnprocessableEntity, res.Code)
+
+  res = as.JSON("/admin/completion_types/%s", podcast.ID).Delete()
+  as.Equal(http.StatusOK, res.Code)
+
+  count, err := as.DB.Where("name = ?", "Podcast").Count(&models.TypeDefinition{})
+  as.NoError(err)
+  as.Equal(0, count)
+}
@@ -7,6 +7,8 @@ import (
     "completion_tracker/models"
 
     "github.com/gobuffalo/buffalo"
+    "github.com/gobuffalo/nulls"
+    "github.com/gobuffalo/plush/v5"
     "github.com/gobuffalo/pop/v6"
     "github.com/gobuffalo/x/responder"
 )
@@ -15,7 +17,7 @@ type CompletionsResource struct{
     buffalo.Resource
 }
 
-// List gets all Completions. This function is mapped to the path
+// List gets all of the signed in User's Completions. This function is mapped to the path
 // GET /completions
 func (v CompletionsResource) List(c buffalo.Context) error {
     // Get the DB connection from the context
@@ -26,24 +28,44 @@ func (v CompletionsResource) List(c buffalo.Context) error {
 
     completions := &models.Completions{}
 
-    // Paginate results. Params "page" and "per_page" control pagination.
-    // Default values are "page=1" and "per_page=20".
-    q := tx.PaginateFromParams(c.Params())
+    // Only the Completions the User can see are listed, and those in the
+    // trash are left out.
+    q := tx.Scope(visibleCompletions(c))
 
-    // Retrieve all Completions from the DB
-    if err := q.All(completions); err != nil {
+    // Optionally filter by completion type, lifecycle status, rating,
+    // completion date and progress
+    q, err := filterByType(c, q)
+    if err != nil {
+        return err
+    }
+    q, err = filterCompletions(c, q)
+    if err != nil {
         return err
     }
 
     return responder.Wants("html", func(c buffalo.Context) error {
-        // Add the paginator to the context so it can be used in the template.
-        c.Set("pagination", q.Paginator)
+        // Sort and retrieve the page asked for by the "page" and
+        // "per_page" params, and add the paginator to the context so it
+        // can be used in the template.
+        pagination, err := paginate(c, q, completions)
+        if err != nil {
+            return err
+        }
+        c.Set("pagination", pagination)
+        c.Set("statuses", models.GetStatuses())
 
         c.Set("completions", completions)
         return c.Render(http.StatusOK, r.HTML("completions/index.plush.html"))
     }).Wants("json", func(c buffalo.Context) error {
+        // JSON clients page through by cursor
+        if err := paginateByCursor(c, q, completions); err != nil {
+            return err
+        }
         return c.Render(200, r.JSON(completions))
     }).Wants("xml", func(c buffalo.Context) error {
+        if _, err := paginate(c, q, completions); err != nil {
+            return err
+        }
         return c.Render(200, r.XML(completions))
     }).Respond(c)
 }
@@ -61,10 +83,15 @@ func (v CompletionsResource) Show(c buffalo.Context) error {
     completion := &models.Completion{}
 
     // To find the Completion the parameter completion_id is used.
-    if err := tx.Find(completion, c.Param("completion_id")); err != nil {
+    if err := tx.Eager("StatusTransitions", "Runs", "Series", "Group", "Tags").Scope(visibleCompletions(c)).Find(completion, c.Param("completion_id")); err != nil {
         return c.Error(http.StatusNotFound, err)
     }
 
+    // Only the latest changes, the rest are paged through at its revisions path
+    if err := loadRecentRevisions(tx, completion); err != nil {
+        return err
+    }
+
     return responder.Wants("html", func(c buffalo.Context) error {
         c.Set("completion", completion)
 
@@ -80,7 +107,10 @@ func (v CompletionsResource) Show(c buffalo.Context) error {
 // This function is mapped to the path GET /completions/new
 func (v CompletionsResource) New(c buffalo.Context) error {
     c.Set("completion", &models.Completion{})
-    c.Set("completionTypes", models.GetCompletionTypes())
+    c.Set("statuses", models.GetStatuses())
+    if err := setCompletionOptions(c); err != nil {
+        return err
+    }
 
     return c.Render(http.StatusOK, r.HTML("completions/new.plush.html"))
 }
@@ -96,12 +126,23 @@ func (v CompletionsResource) Create(c buffalo.Context) error {
         return err
     }
 
+    // A TV Show's episodes watched are counted from its season grid, not
+    // typed in
+    if completion.Type == models.CompletionTypeTVShow {
+        completion.Completions = 0
+    }
+
     // Get the DB connection from the context
     tx, ok := c.Value("tx").(*pop.Connection)
     if !ok {
         return fmt.Errorf("no transaction found")
     }
 
+    // It always belongs to the signed in User, whatever was posted
+    if err := setOwner(c, tx, completion); err != nil {
+        return err
+    }
+
     // Validate the data from the html form
     verrs, err := tx.ValidateAndCreate(completion)
     if err != nil {
@@ -116,10 +157,14 @@ func (v CompletionsResource) Create(c buffalo.Context) error {
             // Render again the new.html template that the user can
             // correct the input.
             c.Set("completion", completion)
+            c.Set("statuses", models.GetStatuses())
+            if err := setCompletionOptions(c); err != nil {
+                return err
+            }
 
             return c.Render(http.StatusUnprocessableEntity, r.HTML("completions/new.plush.html"))
         }).Wants("json", func(c buffalo.Context) error {
-            return c.Render(http.StatusUnprocessableEntity, r.JSON(verrs))
+            return renderValidationErrors(c, verrs)
         }).Wants("xml", func(c buffalo.Context) error {
             return c.Render(http.StatusUnprocessableEntity, r.XML(verrs))
         }).Respond(c)
@@ -150,11 +195,15 @@ func (v CompletionsResource) Edit(c buffalo.Context) error {
     // Allocate an empty Completion
     completion := &models.Completion{}
 
-    if err := tx.Find(completion, c.Param("completion_id")); err != nil {
+    if err := tx.Eager("Tags").Scope(editableCompletions(c)).Find(completion, c.Param("completion_id")); err != nil {
         return c.Error(http.StatusNotFound, err)
     }
 
     c.Set("completion", completion)
+    c.Set("statuses", models.GetStatuses())
+    if err := setCompletionOptions(c); err != nil {
+        return err
+    }
     return c.Render(http.StatusOK, r.HTML("completions/edit.plush.html"))
 }
 
@@ -170,15 +219,32 @@ func (v CompletionsResource) Update(c buffalo.Context) error {
     // Allocate an empty Completion
     completion := &models.Completion{}
 
-    if err := tx.Find(completion, c.Param("completion_id")); err != nil {
+    if err := tx.Scope(editableCompletions(c)).Find(completion, c.Param("completion_id")); err != nil {
         return c.Error(http.StatusNotFound, err)
     }
 
+    // Remember which it is, and how it was shared, which only its owner
+    // can change
+    persisted := *completion
+
     // Bind Completion to the html form elements
     if err := c.Bind(completion); err != nil {
         return err
     }
 
+    // It stays the Completion that was found, whatever id was posted
+    keepIdentity(completion, persisted)
+
+    // A TV Show's counts come from its season grid, not the form
+    if err := keepEpisodeCounts(tx, completion, persisted); err != nil {
+        return err
+    }
+
+    // It keeps its owner, whoever edits it
+    if err := keepOwner(c, tx, completion, persisted); err != nil {
+        return err
+    }
+
     verrs, err := tx.ValidateAndUpdate(completion)
     if err != nil {
         return err
@@ -192,10 +258,14 @@ func (v CompletionsResource) Update(c buffalo.Context) error {
             // Render again the edit.html template that the user can
             // correct the input.
             c.Set("completion", completion)
+            c.Set("statuses", models.GetStatuses())
+            if err := setCompletionOptions(c); err != nil {
+                return err
+            }
 
             return c.Render(http.StatusUnprocessableEntity, r.HTML("completions/edit.plush.html"))
         }).Wants("json", func(c buffalo.Context) error {
-            return c.Render(http.StatusUnprocessableEntity, r.JSON(verrs))
+            return renderValidationErrors(c, verrs)
         }).Wants("xml", func(c buffalo.Context) error {
             return c.Render(http.StatusUnprocessableEntity, r.XML(verrs))
         }).Respond(c)
@@ -214,7 +284,7 @@ func (v CompletionsResource) Update(c buffalo.Context) error {
     }).Respond(c)
 }
 
-// Destroy deletes a Completion from the DB. This function is mapped
+// Destroy moves a Completion to the trash. This function is mapped
 // to the path DELETE /completions/{completion_id}
 func (v CompletionsResource) Destroy(c buffalo.Context) error {
     // Get the DB connection from the context
@@ -227,11 +297,11 @@ func (v CompletionsResource) Destroy(c buffalo.Context) error {
     completion := &models.Completion{}
 
     // To find the Completion the parameter completion_id is used.
-    if err := tx.Find(completion, c.Param("completion_id")); err != nil {
+    if err := tx.Scope(ownCompletions(c)).Find(completion, c.Param("completion_id")); err != nil {
         return c.Error(http.StatusNotFound, err)
     }
 
-    if err := tx.Destroy(completion); err != nil {
+    if err := completion.Trash(tx); err != nil {
         return err
     }
 
@@ -248,3 +318,108 @@ func (v CompletionsResource) Destroy(c buffalo.Context) error {
     }).Respond(c)
 }
 
+// ownCompletions scopes a query to the signed in User's completions that
+// are not in the trash. Anyone else's are treated as if they don't exist.
+func ownCompletions(c buffalo.Context) pop.ScopeFunc {
+    return func(q *pop.Query) *pop.Query {
+        return q.Scope(models.OwnedBy(currentUser(c))).Scope(models.NotTrashed)
+    }
+}
+
+// visibleCompletions scopes a query to the completions the signed in User
+// can see, their own and those shared with their groups, that are not in
+// the trash.
+func visibleCompletions(c buffalo.Context) pop.ScopeFunc {
+    return func(q *pop.Query) *pop.Query {
+        return q.Scope(models.VisibleTo(currentUser(c))).Scope(models.NotTrashed)
+    }
+}
+
+// editableCompletions scopes a query to the completions the signed in User
+// can update, their own and those shared with groups they edit, that are
+// not in the trash.
+func editableCompletions(c buffalo.Context) pop.ScopeFunc {
+    return func(q *pop.Query) *pop.Query {
+        return q.Scope(models.EditableBy(currentUser(c))).Scope(models.NotTrashed)
+    }
+}
+
+// setOwner gives completion to the signed in User, provided the Series it
+// names is theirs and they can share it with the Group it names.
+func setOwner(c buffalo.Context, tx *pop.Connection, completion *models.Completion) error {
+    completion.UserID = nulls.NewUUID(currentUser(c).ID)
+    if err := checkSeries(c, tx, completion); err != nil {
+        return err
+    }
+    return checkGroup(c, tx, completion)
+}
+
+// keepIdentity undoes whatever was posted about which Completion this is,
+// so that an update only ever changes the one that was found. persisted is
+// the Completion as it was before binding.
+func keepIdentity(completion *models.Completion, persisted models.Completion) {
+    completion.ID = persisted.ID
+    completion.CreatedAt = persisted.CreatedAt
+    completion.DeletedAt = persisted.DeletedAt
+}
+
+// keepOwner undoes whatever was posted about who completion belongs to.
+// Only its owner can change its privacy or move it to another Group;
+// editors in its Group can update everything else. It can only be moved
+// to one of its owner's series. persisted is the Completion as it was
+// before binding.
+func keepOwner(c buffalo.Context, tx *pop.Connection, completion *models.Completion, persisted models.Completion) error {
+    completion.UserID = persisted.UserID
+    if persisted.UserID.UUID != currentUser(c).ID {
+        completion.Privacy = persisted.Privacy
+        completion.GroupID = persisted.GroupID
+    }
+
+    if completion.SeriesID != persisted.SeriesID {
+        if err := checkSeries(c, tx, completion); err != nil {
+            return err
+        }
+    }
+
+    if completion.GroupID == persisted.GroupID {
+        return nil
+    }
+    return checkGroup(c, tx, completion)
+}
+
+// ownsCompletion reports whether the signed in User owns completion, as
+// they will a new one, and so can change its privacy and group.
+func ownsCompletion(completion interface{}, help plush.HelperContext) bool {
+    u, ok := help.Value("current_user").(*models.User)
+    if !ok {
+        return false
+    }
+
+    var owner nulls.UUID
+    switch c := completion.(type) {
+    case *models.Completion:
+        owner = c.UserID
+    case models.Completion:
+        owner = c.UserID
+    }
+    return !owner.Valid || owner.UUID == u.ID
+}
+
+// typePaths maps each CompletionType to the path of the resource that
+// manages it.
+var typePaths = map[models.CompletionType]string{
+    models.CompletionTypeTVShow:    "/tv_shows",
+    models.CompletionTypeVideoGame: "/video_games",
+    models.CompletionTypeBook:      "/books",
+    models.CompletionTypeAudioBook: "/audio_books",
+    models.CompletionTypeEvent:     "/events",
+}
+
+// showPathFor returns the type-specific show page of a Completion, falling
+// back to the generic one.
+func showPathFor(completion models.Completion) string {
+    if p, ok := typePaths[completion.Type]; ok {
+        return fmt.Sprintf("%s/%s", p, completion.ID)
+    }
+    return fmt.Sprintf("/completions/%s", completion.ID)
+}
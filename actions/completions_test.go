@@ -1,30 +1,235 @@
 package actions
 
+import (
+  "net/http"
+  "time"
+
+  "completion_tracker/models"
+
+  "github.com/gobuffalo/nulls"
+)
+
+func (as *ActionSuite) createCompletion(name string, status models.Status) *models.Completion {
+  completion := &models.Completion{
+    Name:        name,
+    Type:        models.CompletionTypeVideoGame,
+    Completions: 5,
+    Status:      status,
+    CompletedAt: time.Now(),
+    UserID:      nulls.NewUUID(as.user.ID),
+  }
+  verrs, err := as.DB.ValidateAndCreate(completion)
+  as.NoError(err)
+  as.False(verrs.HasAny())
+  return completion
+}
+
 func (as *ActionSuite) Test_CompletionsResource_List() {
-  as.Fail("Not Implemented!")
+  as.createCompletion("Hades", models.StatusInProgress)
+  as.createCompletion("Celeste", models.StatusPlanned)
+
+  res := as.HTML("/completions").Get()
+  as.Equal(http.StatusOK, res.Code)
+  as.Contains(res.Body.String(), "Hades")
+  as.Contains(res.Body.String(), "Celeste")
+
+  res = as.HTML("/completions?status=in-progress").Get()
+  as.Equal(http.StatusOK, res.Code)
+  as.Contains(res.Body.String(), "Hades")
+  as.NotContains(res.Body.String(), "Celeste")
+
+  completions := models.Completions{}
+  jres := as.JSON("/completions?status=planned").Get()
+  as.Equal(http.StatusOK, jres.Code)
+  jres.Bind(&completions)
+  as.Len(completions, 1)
+  as.Equal("Celeste", completions[0].Name)
+
+  res = as.HTML("/completions?status=finished").Get()
+  as.Equal(http.StatusBadRequest, res.Code)
 }
 
 func (as *ActionSuite) Test_CompletionsResource_Show() {
-  as.Fail("Not Implemented!")
+  completion := as.createCompletion("Hades", models.StatusPlanned)
+
+  res := as.HTML("/completions/%s", completion.ID).Get()
+  as.Equal(http.StatusOK, res.Code)
+  as.Contains(res.Body.String(), "Added as planned")
+
+  jres := as.JSON("/completions/%s", completion.ID).Get()
+  as.Equal(http.StatusOK, jres.Code)
+  as.Contains(jres.Body.String(), `"status_transitions"`)
 }
 
 func (as *ActionSuite) Test_CompletionsResource_Create() {
-  as.Fail("Not Implemented!")
+  res := as.JSON("/completions").Post(map[string]interface{}{
+    "name":         "Hades",
+    "type":         models.CompletionTypeVideoGame,
+    "completions":  5,
+    "completed_at": time.Now(),
+  })
+  as.Equal(http.StatusCreated, res.Code)
+
+  completion := &models.Completion{}
+  as.NoError(as.DB.Where("name = ?", "Hades").First(completion))
+  as.Equal(models.StatusPlanned, completion.Status)
+}
+
+func (as *ActionSuite) Test_CompletionsResource_TvShowCounts() {
+  // Episodes watched can't be typed in for a new TV Show
+  jres := as.JSON("/completions").Post(map[string]interface{}{
+    "name":         "Andor",
+    "type":         models.CompletionTypeTVShow,
+    "completions":  5,
+    "completed_at": time.Now(),
+  })
+  as.Equal(http.StatusCreated, jres.Code)
+
+  andor := &models.Completion{}
+  as.NoError(as.DB.Where("name = ?", "Andor").First(andor))
+  as.Equal(0, andor.Completions)
+
+  // Nor changed, along with the total once it has episodes
+  tvShow := as.createTvShow("Severance", 0, 0)
+  as.createSeason(tvShow, 1, 3)
+
+  jres = as.JSON("/completions/%s", tvShow.ID).Put(map[string]interface{}{
+    "name":        "Severance",
+    "completions": 2,
+    "target":      20,
+  })
+  as.Equal(http.StatusOK, jres.Code)
+
+  as.NoError(as.DB.Reload(tvShow))
+  as.Equal(0, tvShow.Completions)
+  as.Equal(3, tvShow.Target)
+
+  // And a completion can't become or stop being a TV Show
+  jres = as.JSON("/completions/%s", tvShow.ID).Put(map[string]interface{}{
+    "type": models.CompletionTypeBook,
+  })
+  as.Equal(http.StatusUnprocessableEntity, jres.Code)
+
+  book := as.createBook("Dune", 206, 412)
+  jres = as.JSON("/completions/%s", book.ID).Put(map[string]interface{}{
+    "type": models.CompletionTypeTVShow,
+  })
+  as.Equal(http.StatusUnprocessableEntity, jres.Code)
+
+  as.NoError(as.DB.Reload(tvShow))
+  as.Equal(models.CompletionTypeTVShow, tvShow.Type)
+  as.NoError(as.DB.Reload(book))
+  as.Equal(models.CompletionTypeBook, book.Type)
+  as.Equal(206, book.Completions)
+}
+
+func (as *ActionSuite) Test_CompletionsResource_OtherUsers() {
+  completion := as.createCompletion("Hades", models.StatusInProgress)
+
+  // Someone else signed in can't see or change it
+  stranger := as.createUser("stranger@example.com")
+  as.signIn(stranger)
+
+  res := as.HTML("/completions").Get()
+  as.Equal(http.StatusOK, res.Code)
+  as.NotContains(res.Body.String(), "Hades")
+
+  res = as.HTML("/completions/%s", completion.ID).Get()
+  as.Equal(http.StatusNotFound, res.Code)
+
+  jres := as.JSON("/completions/%s", completion.ID).Put(map[string]interface{}{"name": "Celeste"})
+  as.Equal(http.StatusNotFound, jres.Code)
+
+  jres = as.JSON("/completions/%s", completion.ID).Delete()
+  as.Equal(http.StatusNotFound, jres.Code)
+
+  jres = as.JSON("/completions/%s/entries", completion.ID).Get()
+  as.Equal(http.StatusNotFound, jres.Code)
+
+  // Nor by posting its id in an update of one of their own
+  theirs := &models.Completion{Name: "Celeste", Type: models.CompletionTypeVideoGame, CompletedAt: time.Now(), UserID: nulls.NewUUID(stranger.ID)}
+  as.NoError(as.DB.Create(theirs))
+  for _, path := range []string{"/completions/%s", "/video_games/%s"} {
+    jres = as.JSON(path, theirs.ID).Put(map[string]interface{}{
+      "id":           completion.ID,
+      "name":         "Celeste Classic",
+      "type":         models.CompletionTypeVideoGame,
+      "completed_at": theirs.CompletedAt,
+    })
+    as.Equal(http.StatusOK, jres.Code)
+  }
+
+  as.NoError(as.DB.Reload(theirs))
+  as.Equal("Celeste Classic", theirs.Name)
+
+  as.NoError(as.DB.Reload(completion))
+  as.Equal("Hades", completion.Name)
+  as.False(completion.IsTrashed())
+}
+
+func (as *ActionSuite) Test_CompletionsResource_Create_Owner() {
+  stranger := as.createUser("stranger@example.com")
+
+  // The completion belongs to whoever is signed in, whatever is posted
+  res := as.JSON("/completions").Post(map[string]interface{}{
+    "name":         "Hades",
+    "type":         models.CompletionTypeVideoGame,
+    "completed_at": time.Now(),
+    "user_id":      stranger.ID,
+  })
+  as.Equal(http.StatusCreated, res.Code)
+
+  completion := &models.Completion{}
+  as.NoError(as.DB.Where("name = ?", "Hades").First(completion))
+  as.Equal(nulls.NewUUID(as.user.ID), completion.UserID)
 }
 
 func (as *ActionSuite) Test_CompletionsResource_Update() {
-  as.Fail("Not Implemented!")
+  completion := as.createCompletion("Hades", models.StatusInProgress)
+
+  res := as.JSON("/completions/%s", completion.ID).Put(map[string]interface{}{
+    "status": models.StatusCompleted,
+  })
+  as.Equal(http.StatusOK, res.Code)
+
+  res = as.JSON("/completions/%s", completion.ID).Put(map[string]interface{}{
+    "status": models.StatusPaused,
+  })
+  as.Equal(http.StatusUnprocessableEntity, res.Code)
+
+  as.NoError(as.DB.Reload(completion))
+  as.Equal(models.StatusCompleted, completion.Status)
 }
 
 func (as *ActionSuite) Test_CompletionsResource_Destroy() {
-  as.Fail("Not Implemented!")
+  completion := as.createCompletion("Hades", models.StatusPlanned)
+
+  res := as.HTML("/completions/%s", completion.ID).Delete()
+  as.Equal(http.StatusSeeOther, res.Code)
+  as.Equal("/completions", res.Location())
+
+  // The completion is only moved to the trash, keeping its history
+  trashed := &models.Completion{}
+  as.NoError(as.DB.Find(trashed, completion.ID))
+  as.True(trashed.IsTrashed())
+
+  count, err := as.DB.Where("completion_id = ?", completion.ID).Count(&models.StatusTransition{})
+  as.NoError(err)
+  as.Equal(1, count)
+
+  as.Equal(http.StatusNotFound, as.HTML("/completions/%s", completion.ID).Get().Code)
 }
 
 func (as *ActionSuite) Test_CompletionsResource_New() {
-  as.Fail("Not Implemented!")
+  res := as.HTML("/completions/new").Get()
+  as.Equal(http.StatusOK, res.Code)
+  as.Contains(res.Body.String(), "in-progress")
 }
 
 func (as *ActionSuite) Test_CompletionsResource_Edit() {
-  as.Fail("Not Implemented!")
-}
+  completion := as.createCompletion("Hades", models.StatusPlanned)
 
+  res := as.HTML("/completions/%s/edit", completion.ID).Get()
+  as.Equal(http.StatusOK, res.Code)
+  as.Contains(res.Body.String(), "Hades")
+}
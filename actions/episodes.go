@@ -0,0 +1,282 @@
+package actions
+
+import (
+    "fmt"
+    "net/http"
+    "time"
+
+    "completion_tracker/models"
+
+    "github.com/gobuffalo/buffalo"
+    "github.com/gobuffalo/nulls"
+    "github.com/gobuffalo/pop/v6"
+    "github.com/gobuffalo/x/responder"
+)
+
+// EpisodesResource manages the Episodes of a Season, including marking
+// them watched. It is mounted at
+// /tv_shows/{tv_show_id}/seasons/{season_id}/episodes.
+type EpisodesResource struct{
+    buffalo.Resource
+}
+
+// episodeForm binds an Episode from the request. The season grid posts
+// Watched as a toggle rather than a date; API clients may send either.
+type episodeForm struct {
+    *models.Episode
+    Watched *bool `json:"watched,omitempty" xml:"-" form:"Watched"`
+}
+
+// bindEpisode binds the request onto episode. Watched marks the episode
+// watched now, unless it already was, or clears its WatchedAt.
+func bindEpisode(c buffalo.Context, episode *models.Episode) error {
+    form := &episodeForm{Episode: episode}
+    if err := c.Bind(form); err != nil {
+        return err
+    }
+
+    if form.Watched != nil {
+        if !*form.Watched {
+            episode.WatchedAt = nulls.Time{}
+        } else if !episode.IsWatched() {
+            episode.WatchedAt = nulls.NewTime(time.Now())
+        }
+    }
+    return nil
+}
+
+// findEpisodeSeason loads the TV Show and Season an episode route is
+// nested under.
+func findEpisodeSeason(c buffalo.Context, tx *pop.Connection) (*models.Completion, *models.Season, error) {
+    completion, err := findTvShow(c, tx)
+    if err != nil {
+        return nil, nil, err
+    }
+
+    season, err := findSeason(c, tx, completion)
+    if err != nil {
+        return nil, nil, err
+    }
+    return completion, season, nil
+}
+
+// findEpisode loads the Episode named by episode_id, provided it belongs
+// to season.
+func findEpisode(c buffalo.Context, tx *pop.Connection, season *models.Season) (*models.Episode, error) {
+    episode := &models.Episode{}
+    if err := tx.Where("season_id = ?", season.ID).Find(episode, c.Param("episode_id")); err != nil {
+        return nil, c.Error(http.StatusNotFound, err)
+    }
+    return episode, nil
+}
+
+// List gets all Episodes of a Season. This function is mapped to the path
+// GET /tv_shows/{tv_show_id}/seasons/{season_id}/episodes
+func (v EpisodesResource) List(c buffalo.Context) error {
+    // Get the DB connection from the context
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    completion, season, err := findEpisodeSeason(c, tx)
+    if err != nil {
+        return err
+    }
+
+    episodes := &models.Episodes{}
+    if err := tx.Where("season_id = ?", season.ID).Order("number asc").All(episodes); err != nil {
+        return err
+    }
+
+    return responder.Wants("html", func(c buffalo.Context) error {
+        return c.Redirect(http.StatusSeeOther, "/tv_shows/%v#season-%d", completion.ID, season.Number)
+    }).Wants("json", func(c buffalo.Context) error {
+        return c.Render(200, r.JSON(episodes))
+    }).Wants("xml", func(c buffalo.Context) error {
+        return c.Render(200, r.XML(episodes))
+    }).Respond(c)
+}
+
+// Show gets the data for one Episode. This function is mapped to the path
+// GET /tv_shows/{tv_show_id}/seasons/{season_id}/episodes/{episode_id}
+func (v EpisodesResource) Show(c buffalo.Context) error {
+    // Get the DB connection from the context
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    completion, season, err := findEpisodeSeason(c, tx)
+    if err != nil {
+        return err
+    }
+
+    episode, err := findEpisode(c, tx, season)
+    if err != nil {
+        return err
+    }
+
+    return responder.Wants("html", func(c buffalo.Context) error {
+        return c.Redirect(http.StatusSeeOther, "/tv_shows/%v#season-%d", completion.ID, season.Number)
+    }).Wants("json", func(c buffalo.Context) error {
+        return c.Render(200, r.JSON(episode))
+    }).Wants("xml", func(c buffalo.Context) error {
+        return c.Render(200, r.XML(episode))
+    }).Respond(c)
+}
+
+// Create adds an Episode to a Season. This function is mapped to the path
+// POST /tv_shows/{tv_show_id}/seasons/{season_id}/episodes
+func (v EpisodesResource) Create(c buffalo.Context) error {
+    // Get the DB connection from the context
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    completion, season, err := findEpisodeSeason(c, tx)
+    if err != nil {
+        return err
+    }
+
+    // Allocate an empty Episode
+    episode := &models.Episode{}
+
+    // Bind episode to the html form elements
+    if err := bindEpisode(c, episode); err != nil {
+        return err
+    }
+    episode.SeasonID = season.ID
+
+    // Number the episode after the last one when no number was given
+    if episode.Number == 0 {
+        last := &models.Episode{}
+        if err := tx.Where("season_id = ?", season.ID).Order("number desc").First(last); err == nil {
+            episode.Number = last.Number + 1
+        } else {
+            episode.Number = 1
+        }
+    }
+
+    // Validate the data from the html form
+    verrs, err := tx.ValidateAndCreate(episode)
+    if err != nil {
+        return err
+    }
+
+    if verrs.HasAny() {
+        return responder.Wants("html", func(c buffalo.Context) error {
+            c.Flash().Add("danger", verrs.Error())
+            return c.Redirect(http.StatusSeeOther, "/tv_shows/%v#season-%d", completion.ID, season.Number)
+        }).Wants("json", func(c buffalo.Context) error {
+            return renderValidationErrors(c, verrs)
+        }).Wants("xml", func(c buffalo.Context) error {
+            return c.Render(http.StatusUnprocessableEntity, r.XML(verrs))
+        }).Respond(c)
+    }
+
+    return responder.Wants("html", func(c buffalo.Context) error {
+        // If there are no errors set a success message
+        c.Flash().Add("success", T.Translate(c, "episode.created.success"))
+
+        // and redirect to the TV Show's season grid
+        return c.Redirect(http.StatusSeeOther, "/tv_shows/%v#season-%d", completion.ID, season.Number)
+    }).Wants("json", func(c buffalo.Context) error {
+        return c.Render(http.StatusCreated, r.JSON(episode))
+    }).Wants("xml", func(c buffalo.Context) error {
+        return c.Render(http.StatusCreated, r.XML(episode))
+    }).Respond(c)
+}
+
+// Update changes an Episode, including marking it watched or unwatched.
+// This function is mapped to the path
+// PUT /tv_shows/{tv_show_id}/seasons/{season_id}/episodes/{episode_id}
+func (v EpisodesResource) Update(c buffalo.Context) error {
+    // Get the DB connection from the context
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    completion, season, err := findEpisodeSeason(c, tx)
+    if err != nil {
+        return err
+    }
+
+    episode, err := findEpisode(c, tx, season)
+    if err != nil {
+        return err
+    }
+
+    // Bind Episode to the html form elements, keeping which episode it is
+    persisted := *episode
+    if err := bindEpisode(c, episode); err != nil {
+        return err
+    }
+    episode.ID = persisted.ID
+    episode.CreatedAt = persisted.CreatedAt
+    episode.SeasonID = season.ID
+
+    verrs, err := tx.ValidateAndUpdate(episode)
+    if err != nil {
+        return err
+    }
+
+    if verrs.HasAny() {
+        return responder.Wants("html", func(c buffalo.Context) error {
+            c.Flash().Add("danger", verrs.Error())
+            return c.Redirect(http.StatusSeeOther, "/tv_shows/%v#season-%d", completion.ID, season.Number)
+        }).Wants("json", func(c buffalo.Context) error {
+            return renderValidationErrors(c, verrs)
+        }).Wants("xml", func(c buffalo.Context) error {
+            return c.Render(http.StatusUnprocessableEntity, r.XML(verrs))
+        }).Respond(c)
+    }
+
+    return responder.Wants("html", func(c buffalo.Context) error {
+        // The grid is toggled an episode at a time, so skip the flash
+        // and go straight back to it.
+        return c.Redirect(http.StatusSeeOther, "/tv_shows/%v#season-%d", completion.ID, season.Number)
+    }).Wants("json", func(c buffalo.Context) error {
+        return c.Render(http.StatusOK, r.JSON(episode))
+    }).Wants("xml", func(c buffalo.Context) error {
+        return c.Render(http.StatusOK, r.XML(episode))
+    }).Respond(c)
+}
+
+// Destroy deletes an Episode. This function is mapped to the path
+// DELETE /tv_shows/{tv_show_id}/seasons/{season_id}/episodes/{episode_id}
+func (v EpisodesResource) Destroy(c buffalo.Context) error {
+    // Get the DB connection from the context
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    completion, season, err := findEpisodeSeason(c, tx)
+    if err != nil {
+        return err
+    }
+
+    episode, err := findEpisode(c, tx, season)
+    if err != nil {
+        return err
+    }
+
+    if err := tx.Destroy(episode); err != nil {
+        return err
+    }
+
+    return responder.Wants("html", func(c buffalo.Context) error {
+        // If there are no errors set a flash message
+        c.Flash().Add("success", T.Translate(c, "episode.destroyed.success"))
+
+        // Redirect to the TV Show's season grid
+        return c.Redirect(http.StatusSeeOther, "/tv_shows/%v#season-%d", completion.ID, season.Number)
+    }).Wants("json", func(c buffalo.Context) error {
+        return c.Render(http.StatusOK, r.JSON(episode))
+    }).Wants("xml", func(c buffalo.Context) error {
+        return c.Render(http.StatusOK, r.XML(episode))
+    }).Respond(c)
+}
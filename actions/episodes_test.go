@@ -0,0 +1,101 @@
+package actions
+
+import (
+  "net/http"
+
+  "completion_tracker/models"
+)
+
+func (as *ActionSuite) createSeason(tvShow *models.Completion, number, episodes int) *models.Season {
+  season := &models.Season{CompletionID: tvShow.ID, Number: number, EpisodeCount: episodes}
+  verrs, err := as.DB.ValidateAndCreate(season)
+  as.NoError(err)
+  as.False(verrs.HasAny())
+  return season
+}
+
+func (as *ActionSuite) Test_EpisodesResource_Update() {
+  tvShow := as.createTvShow("Severance", 0, 0)
+  season := as.createSeason(tvShow, 1, 9)
+  episode := season.Episodes[0]
+
+  res := as.HTML("/tv_shows/%s/seasons/%s/episodes/%s", tvShow.ID, season.ID, episode.ID).Put(map[string]interface{}{
+    "Watched": true,
+  })
+  as.Equal(http.StatusSeeOther, res.Code)
+
+  as.NoError(as.DB.Reload(tvShow))
+  as.Equal(1, tvShow.Completions)
+
+  as.NoError(as.DB.Reload(&episode))
+  as.True(episode.IsWatched())
+
+  jres := as.JSON("/tv_shows/%s/seasons/%s/episodes/%s", tvShow.ID, season.ID, episode.ID).Put(map[string]interface{}{
+    "watched": false,
+  })
+  as.Equal(http.StatusOK, jres.Code)
+
+  as.NoError(as.DB.Reload(tvShow))
+  as.Equal(0, tvShow.Completions)
+}
+
+func (as *ActionSuite) Test_EpisodesResource_Create() {
+  tvShow := as.createTvShow("Severance", 0, 0)
+  season := as.createSeason(tvShow, 1, 2)
+
+  jres := as.JSON("/tv_shows/%s/seasons/%s/episodes", tvShow.ID, season.ID).Post(map[string]interface{}{
+    "title":   "Defiant Jazz",
+    "watched": true,
+  })
+  as.Equal(http.StatusCreated, jres.Code)
+
+  episode := &models.Episode{}
+  as.NoError(as.DB.Where("season_id = ? AND number = ?", season.ID, 3).First(episode))
+  as.Equal("Defiant Jazz", episode.Title)
+
+  as.NoError(as.DB.Reload(tvShow))
+  as.Equal(3, tvShow.Target)
+  as.Equal(1, tvShow.Completions)
+}
+
+func (as *ActionSuite) Test_EpisodesResource_Destroy() {
+  tvShow := as.createTvShow("Severance", 0, 0)
+  season := as.createSeason(tvShow, 1, 2)
+  episode := season.Episodes[1]
+
+  other := as.createTvShow("Andor", 0, 0)
+  res := as.HTML("/tv_shows/%s/seasons/%s/episodes/%s", other.ID, season.ID, episode.ID).Delete()
+  as.Equal(http.StatusNotFound, res.Code)
+
+  res = as.HTML("/tv_shows/%s/seasons/%s/episodes/%s", tvShow.ID, season.ID, episode.ID).Delete()
+  as.Equal(http.StatusSeeOther, res.Code)
+
+  as.NoError(as.DB.Reload(tvShow))
+  as.Equal(1, tvShow.Target)
+}
+
+func (as *ActionSuite) Test_EpisodesResource_Update_OtherUsers() {
+  episode := as.createSeason(as.createTvShow("Severance", 0, 0), 1, 1).Episodes[0]
+
+  // Posting its id in an update of someone else's episode leaves it alone
+  as.user = as.createUser("stranger@example.com")
+  as.signIn(as.user)
+  tvShow := as.createTvShow("Andor", 0, 0)
+  season := as.createSeason(tvShow, 1, 1)
+  theirs := season.Episodes[0]
+
+  jres := as.JSON("/tv_shows/%s/seasons/%s/episodes/%s", tvShow.ID, season.ID, theirs.ID).Put(map[string]interface{}{
+    "id":      episode.ID,
+    "title":   "Kassa",
+    "watched": true,
+  })
+  as.Equal(http.StatusOK, jres.Code)
+
+  as.NoError(as.DB.Reload(&theirs))
+  as.Equal("Kassa", theirs.Title)
+  as.True(theirs.IsWatched())
+
+  as.NoError(as.DB.Reload(&episode))
+  as.NotEqual("Kassa", episode.Title)
+  as.False(episode.IsWatched())
+}
@@ -1,48 +1,320 @@
 package actions
 
 import (
+    "fmt"
     "net/http"
 
+    "completion_tracker/models"
+
     "github.com/gobuffalo/buffalo"
+    "github.com/gobuffalo/pop/v6"
+    "github.com/gobuffalo/x/responder"
 )
 
 type EventsResource struct{
     buffalo.Resource
 }
 
+// eventStatuses gives the lifecycle status that follows from each
+// attendance status.
+var eventStatuses = map[models.AttendanceStatus]models.Status{
+    models.AttendanceRegistered: models.StatusPlanned,
+    models.AttendanceAttended:   models.StatusCompleted,
+    models.AttendanceMissed:     models.StatusAbandoned,
+}
+
+// bindEvent binds the request onto an Event completion. An Event is a
+// single thing to attend, and counts as completed on its scheduled date
+// once it has been attended. Its status follows its attendance.
+func bindEvent(c buffalo.Context, completion *models.Completion) error {
+    if err := c.Bind(completion); err != nil {
+        return err
+    }
+
+    completion.CompletedAt = completion.ScheduledAt.Time
+    completion.Target = 1
+    completion.Completions = 0
+    if completion.Attendance == models.AttendanceAttended {
+        completion.Completions = 1
+    }
+    if status, ok := eventStatuses[completion.Attendance]; ok {
+        completion.Status = status
+    }
+    return nil
+}
+
 
-// List default implementation.
+// List gets all Event completions. This function is mapped to the path
+// GET /events
 func (v EventsResource) List(c buffalo.Context) error {
-    return c.Render(http.StatusOK, r.String("Event#List"))
+    // Get the DB connection from the context
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    completions := &models.Completions{}
+
+    // The Event completions the User can see, leaving out the trash
+    q := tx.Scope(visibleCompletions(c))
+    q = q.Where("type = ?", models.CompletionTypeEvent)
+
+    // Optionally filter by lifecycle status, rating, completion date and
+    // progress
+    q, err := filterCompletions(c, q)
+    if err != nil {
+        return err
+    }
+
+    return responder.Wants("html", func(c buffalo.Context) error {
+        // Sort and retrieve the page asked for by the "page" and
+        // "per_page" params
+        pagination, err := paginate(c, q, completions)
+        if err != nil {
+            return err
+        }
+        c.Set("pagination", pagination)
+        c.Set("statuses", models.GetStatuses())
+        c.Set("completions", completions)
+        return c.Render(http.StatusOK, r.HTML("events/index.plush.html"))
+    }).Wants("json", func(c buffalo.Context) error {
+        // JSON clients page through by cursor
+        if err := paginateByCursor(c, q, completions); err != nil {
+            return err
+        }
+        return c.Render(200, r.JSON(completions))
+    }).Wants("xml", func(c buffalo.Context) error {
+        if _, err := paginate(c, q, completions); err != nil {
+            return err
+        }
+        return c.Render(200, r.XML(completions))
+    }).Respond(c)
 }
 
-// Show default implementation.
+// Show gets the data for one Event completion. This function is mapped to
+// the path GET /events/{event_id}
 func (v EventsResource) Show(c buffalo.Context) error {
-    return c.Render(http.StatusOK, r.String("Event#Show"))
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    completion := &models.Completion{}
+    if err := tx.Eager("StatusTransitions", "Runs", "Series", "Group", "Tags").Scope(visibleCompletions(c)).Find(completion, c.Param("event_id")); err != nil {
+        return c.Error(http.StatusNotFound, err)
+    }
+
+    // Ensure this is actually an Event completion
+    if completion.Type != models.CompletionTypeEvent {
+        return c.Error(http.StatusNotFound, fmt.Errorf("completion is not an event"))
+    }
+
+    // Only the latest changes, the rest are paged through at its revisions path
+    if err := loadRecentRevisions(tx, completion); err != nil {
+        return err
+    }
+
+    return responder.Wants("html", func(c buffalo.Context) error {
+        c.Set("completion", completion)
+        return c.Render(http.StatusOK, r.HTML("events/show.plush.html"))
+    }).Wants("json", func(c buffalo.Context) error {
+        return c.Render(200, r.JSON(completion))
+    }).Wants("xml", func(c buffalo.Context) error {
+        return c.Render(200, r.XML(completion))
+    }).Respond(c)
 }
 
-// Create default implementation.
+// Create adds an Event completion to the DB. This function is mapped to the
+// path POST /events
 func (v EventsResource) Create(c buffalo.Context) error {
-    return c.Render(http.StatusOK, r.String("Event#Create"))
+    completion := &models.Completion{
+        Type: models.CompletionTypeEvent,
+    }
+
+    if err := bindEvent(c, completion); err != nil {
+        return err
+    }
+
+    // Ensure type is set correctly
+    completion.Type = models.CompletionTypeEvent
+
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    // It always belongs to the signed in User, whatever was posted
+    if err := setOwner(c, tx, completion); err != nil {
+        return err
+    }
+
+    verrs, err := tx.ValidateAndCreate(completion)
+    if err != nil {
+        return err
+    }
+
+    if verrs.HasAny() {
+        return responder.Wants("html", func(c buffalo.Context) error {
+            c.Set("errors", verrs)
+            c.Set("completion", completion)
+            c.Set("attendanceStatuses", models.GetAttendanceStatuses())
+            if err := setCompletionOptions(c); err != nil {
+                return err
+            }
+            return c.Render(http.StatusUnprocessableEntity, r.HTML("events/new.plush.html"))
+        }).Wants("json", func(c buffalo.Context) error {
+            return renderValidationErrors(c, verrs)
+        }).Wants("xml", func(c buffalo.Context) error {
+            return c.Render(http.StatusUnprocessableEntity, r.XML(verrs))
+        }).Respond(c)
+    }
+
+    return responder.Wants("html", func(c buffalo.Context) error {
+        c.Flash().Add("success", T.Translate(c, "event.created.success"))
+        return c.Redirect(http.StatusSeeOther, "/events/%v", completion.ID)
+    }).Wants("json", func(c buffalo.Context) error {
+        return c.Render(http.StatusCreated, r.JSON(completion))
+    }).Wants("xml", func(c buffalo.Context) error {
+        return c.Render(http.StatusCreated, r.XML(completion))
+    }).Respond(c)
 }
 
-// Update default implementation.
+// Update changes an Event completion in the DB. This function is mapped to
+// the path PUT /events/{event_id}
 func (v EventsResource) Update(c buffalo.Context) error {
-    return c.Render(http.StatusOK, r.String("Event#Update"))
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    completion := &models.Completion{}
+    if err := tx.Scope(editableCompletions(c)).Find(completion, c.Param("event_id")); err != nil {
+        return c.Error(http.StatusNotFound, err)
+    }
+
+    if completion.Type != models.CompletionTypeEvent {
+        return c.Error(http.StatusNotFound, fmt.Errorf("completion is not an event"))
+    }
+
+    // Remember which it is, and how it was shared, which only its owner
+    // can change
+    persisted := *completion
+
+    if err := bindEvent(c, completion); err != nil {
+        return err
+    }
+
+    // Ensure type remains Event
+    completion.Type = models.CompletionTypeEvent
+
+    // It stays the Completion that was found, whatever id was posted
+    keepIdentity(completion, persisted)
+
+    // It keeps its owner, whoever edits it
+    if err := keepOwner(c, tx, completion, persisted); err != nil {
+        return err
+    }
+
+    verrs, err := tx.ValidateAndUpdate(completion)
+    if err != nil {
+        return err
+    }
+
+    if verrs.HasAny() {
+        return responder.Wants("html", func(c buffalo.Context) error {
+            c.Set("errors", verrs)
+            c.Set("completion", completion)
+            c.Set("attendanceStatuses", models.GetAttendanceStatuses())
+            if err := setCompletionOptions(c); err != nil {
+                return err
+            }
+            return c.Render(http.StatusUnprocessableEntity, r.HTML("events/edit.plush.html"))
+        }).Wants("json", func(c buffalo.Context) error {
+            return renderValidationErrors(c, verrs)
+        }).Wants("xml", func(c buffalo.Context) error {
+            return c.Render(http.StatusUnprocessableEntity, r.XML(verrs))
+        }).Respond(c)
+    }
+
+    return responder.Wants("html", func(c buffalo.Context) error {
+        c.Flash().Add("success", T.Translate(c, "event.updated.success"))
+        return c.Redirect(http.StatusSeeOther, "/events/%v", completion.ID)
+    }).Wants("json", func(c buffalo.Context) error {
+        return c.Render(http.StatusOK, r.JSON(completion))
+    }).Wants("xml", func(c buffalo.Context) error {
+        return c.Render(http.StatusOK, r.XML(completion))
+    }).Respond(c)
 }
 
-// Destroy default implementation.
+// Destroy moves an Event completion to the trash. This function is mapped
+// to the path DELETE /events/{event_id}
 func (v EventsResource) Destroy(c buffalo.Context) error {
-    return c.Render(http.StatusOK, r.String("Event#Destroy"))
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    completion := &models.Completion{}
+    if err := tx.Scope(ownCompletions(c)).Find(completion, c.Param("event_id")); err != nil {
+        return c.Error(http.StatusNotFound, err)
+    }
+
+    if completion.Type != models.CompletionTypeEvent {
+        return c.Error(http.StatusNotFound, fmt.Errorf("completion is not an event"))
+    }
+
+    if err := completion.Trash(tx); err != nil {
+        return err
+    }
+
+    return responder.Wants("html", func(c buffalo.Context) error {
+        c.Flash().Add("success", T.Translate(c, "event.destroyed.success"))
+        return c.Redirect(http.StatusSeeOther, "/events")
+    }).Wants("json", func(c buffalo.Context) error {
+        return c.Render(http.StatusOK, r.JSON(completion))
+    }).Wants("xml", func(c buffalo.Context) error {
+        return c.Render(http.StatusOK, r.XML(completion))
+    }).Respond(c)
 }
 
-// New default implementation.
+// New renders the form for creating a new Event completion.
+// This function is mapped to the path GET /events/new
 func (v EventsResource) New(c buffalo.Context) error {
-    return c.Render(http.StatusOK, r.String("Event#New"))
+    completion := &models.Completion{
+        Type:       models.CompletionTypeEvent,
+        Attendance: models.AttendanceRegistered,
+    }
+    c.Set("completion", completion)
+    c.Set("attendanceStatuses", models.GetAttendanceStatuses())
+    if err := setCompletionOptions(c); err != nil {
+        return err
+    }
+
+    return c.Render(http.StatusOK, r.HTML("events/new.plush.html"))
 }
 
-// Edit default implementation.
+// Edit renders a edit form for an Event completion. This function is
+// mapped to the path GET /events/{event_id}/edit
 func (v EventsResource) Edit(c buffalo.Context) error {
-    return c.Render(http.StatusOK, r.String("Event#Edit"))
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    completion := &models.Completion{}
+    if err := tx.Eager("Tags").Scope(editableCompletions(c)).Find(completion, c.Param("event_id")); err != nil {
+        return c.Error(http.StatusNotFound, err)
+    }
+
+    if completion.Type != models.CompletionTypeEvent {
+        return c.Error(http.StatusNotFound, fmt.Errorf("completion is not an event"))
+    }
+
+    c.Set("completion", completion)
+    c.Set("attendanceStatuses", models.GetAttendanceStatuses())
+    if err := setCompletionOptions(c); err != nil {
+        return err
+    }
+    return c.Render(http.StatusOK, r.HTML("events/edit.plush.html"))
 }
 
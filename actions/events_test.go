@@ -1,36 +1,128 @@
 package actions
 
 import (
-  "testing"
+  "net/http"
+  "time"
 
-  "github.com/stretchr/testify/require"
+  "completion_tracker/models"
+
+  "github.com/gobuffalo/nulls"
 )
 
+func (as *ActionSuite) createEvent(name string, attendance models.AttendanceStatus) *models.Completion {
+  scheduled := time.Now().Add(-24 * time.Hour)
+  event := &models.Completion{
+    Name:        name,
+    Type:        models.CompletionTypeEvent,
+    ScheduledAt: nulls.NewTime(scheduled),
+    Venue:       "The Fillmore",
+    Attendance:  attendance,
+    Companions:  "Sam, Alex",
+    CompletedAt: scheduled,
+    UserID:      nulls.NewUUID(as.user.ID),
+  }
+  verrs, err := as.DB.ValidateAndCreate(event)
+  as.NoError(err)
+  as.False(verrs.HasAny())
+  return event
+}
+
 func (as *ActionSuite) Test_EventsResource_List() {
-  as.Fail("Not Implemented!")
+  as.createEvent("Gophercon", models.AttendanceAttended)
+
+  res := as.HTML("/events").Get()
+  as.Equal(http.StatusOK, res.Code)
+  body := res.Body.String()
+  as.Contains(body, "Gophercon")
+  as.Contains(body, "The Fillmore")
+  as.Contains(body, "Attended")
+  as.Contains(body, "Alex")
+
+  events := models.Completions{}
+  xres := as.XML("/events").Get()
+  as.Equal(http.StatusOK, xres.Code)
+
+  jres := as.JSON("/events").Get()
+  as.Equal(http.StatusOK, jres.Code)
+  jres.Bind(&events)
+  as.Len(events, 1)
 }
 
 func (as *ActionSuite) Test_EventsResource_Show() {
-  as.Fail("Not Implemented!")
+  event := as.createEvent("Gophercon", models.AttendanceMissed)
+
+  res := as.HTML("/events/%s", event.ID).Get()
+  as.Equal(http.StatusOK, res.Code)
+  as.Contains(res.Body.String(), "Missed")
+
+  book := &models.Completion{Name: "Dune", Type: models.CompletionTypeBook, Completions: 12, CompletedAt: time.Now(), UserID: nulls.NewUUID(as.user.ID)}
+  as.NoError(as.DB.Create(book))
+  res = as.HTML("/events/%s", book.ID).Get()
+  as.Equal(http.StatusNotFound, res.Code)
 }
 
 func (as *ActionSuite) Test_EventsResource_Create() {
-  as.Fail("Not Implemented!")
+  res := as.HTML("/events").Post(map[string]interface{}{
+    "Name":        "Gophercon",
+    "ScheduledAt": "2025-08-26T09:00",
+    "Venue":       "Seattle",
+    "Attendance":  "registered",
+    "Companions":  "Sam",
+  })
+  as.Equal(http.StatusSeeOther, res.Code)
+
+  event := &models.Completion{}
+  as.NoError(as.DB.Where("name = ?", "Gophercon").First(event))
+  as.Equal(models.CompletionTypeEvent, event.Type)
+  as.Equal(models.AttendanceRegistered, event.Attendance)
+  as.Equal(0, event.Completions)
+  as.Equal(event.ScheduledAt.Time, event.CompletedAt)
+
+  res = as.HTML("/events").Post(map[string]interface{}{
+    "Name":       "No Date",
+    "Attendance": "sort of",
+  })
+  as.Equal(http.StatusUnprocessableEntity, res.Code)
 }
 
 func (as *ActionSuite) Test_EventsResource_Update() {
-  as.Fail("Not Implemented!")
+  event := as.createEvent("Gophercon", models.AttendanceRegistered)
+
+  res := as.JSON("/events/%s", event.ID).Put(map[string]interface{}{
+    "name":         "Gophercon",
+    "scheduled_at": event.ScheduledAt,
+    "venue":        "Seattle",
+    "attendance":   "attended",
+  })
+  as.Equal(http.StatusOK, res.Code)
+
+  as.NoError(as.DB.Reload(event))
+  as.Equal(models.AttendanceAttended, event.Attendance)
+  as.Equal(1, event.Completions)
 }
 
 func (as *ActionSuite) Test_EventsResource_Destroy() {
-  as.Fail("Not Implemented!")
+  event := as.createEvent("Gophercon", models.AttendanceAttended)
+
+  res := as.HTML("/events/%s", event.ID).Delete()
+  as.Equal(http.StatusSeeOther, res.Code)
+  as.Equal("/events", res.Location())
+
+  count, err := as.DB.Scope(models.NotTrashed).Where("id = ?", event.ID).Count(&models.Completion{})
+  as.NoError(err)
+  as.Equal(0, count)
 }
 
 func (as *ActionSuite) Test_EventsResource_New() {
-  as.Fail("Not Implemented!")
+  res := as.HTML("/events/new").Get()
+  as.Equal(http.StatusOK, res.Code)
+  as.Contains(res.Body.String(), "registered")
 }
 
 func (as *ActionSuite) Test_EventsResource_Edit() {
-  as.Fail("Not Implemented!")
-}
+  event := as.createEvent("Gophercon", models.AttendanceAttended)
 
+  res := as.HTML("/events/%s/edit", event.ID).Get()
+  as.Equal(http.StatusOK, res.Code)
+  as.Contains(res.Body.String(), "The Fillmore")
+}
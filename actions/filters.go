@@ -0,0 +1,274 @@
+package actions
+
+import (
+    "fmt"
+    "net/http"
+    "net/url"
+    "strconv"
+    "strings"
+    "time"
+
+    "completion_tracker/models"
+
+    "github.com/gobuffalo/buffalo"
+    "github.com/gobuffalo/plush/v5"
+    "github.com/gobuffalo/pop/v6"
+)
+
+// filterByStatus narrows q to the lifecycle status given in the "status"
+// param, if there is one. An unknown status is a bad request.
+func filterByStatus(c buffalo.Context, q *pop.Query) (*pop.Query, error) {
+    status := models.Status(c.Param("status"))
+    if status == "" {
+        return q, nil
+    }
+
+    if !status.IsValid() {
+        return q, c.Error(http.StatusBadRequest, fmt.Errorf("unknown status %q", status))
+    }
+    return q.Where("status = ?", status), nil
+}
+
+// filterByRating narrows q to completions rated exactly "rating" stars,
+// at least "min_rating" and at most "max_rating", for whichever of the
+// params are given. Unrated completions are left out once any is. A rating
+// that isn't a whole or half number of stars from 0 to 5, or a minimum
+// above the maximum, is a bad request.
+func filterByRating(c buffalo.Context, q *pop.Query) (*pop.Query, error) {
+    least, most := 0.0, float64(models.MaxRating)
+    for _, b := range []struct{
+        param string
+        clause string
+        rating *float64
+    }{
+        {"rating", "rating = ?", nil},
+        {"min_rating", "rating >= ?", &least},
+        {"max_rating", "rating <= ?", &most},
+    } {
+        value := c.Param(b.param)
+        if value == "" {
+            continue
+        }
+
+        rating, err := strconv.ParseFloat(value, 64)
+        if err != nil || !models.IsValidRating(rating) {
+            return q, c.Error(http.StatusBadRequest, fmt.Errorf("%s %q is not a rating from 0 to %d in half stars", b.param, value, models.MaxRating))
+        }
+        if b.rating != nil {
+            *b.rating = rating
+        }
+        q = q.Where(b.clause, rating)
+    }
+
+    if least > most {
+        return q, c.Error(http.StatusBadRequest, fmt.Errorf("min_rating %v is above max_rating %v", least, most))
+    }
+    return q, nil
+}
+
+// dateFormat is the format of the dates in the "completed_from" and
+// "completed_to" params, as sent by a date input.
+const dateFormat = "2006-01-02"
+
+// filterByCompletedAt narrows q to completions completed on or after the
+// day in the "completed_from" param and on or before the one in
+// "completed_to", for whichever of the two are given. A date that isn't
+// formatted as 2006-01-02, or a range that ends before it starts, is a bad
+// request.
+func filterByCompletedAt(c buffalo.Context, q *pop.Query) (*pop.Query, error) {
+    var from, to time.Time
+    for _, b := range []struct{
+        param string
+        day *time.Time
+    }{
+        {"completed_from", &from},
+        {"completed_to", &to},
+    } {
+        value := c.Param(b.param)
+        if value == "" {
+            continue
+        }
+
+        day, err := time.Parse(dateFormat, value)
+        if err != nil {
+            return q, c.Error(http.StatusBadRequest, fmt.Errorf("%s %q is not a date like %s", b.param, value, dateFormat))
+        }
+        *b.day = day
+    }
+
+    if !from.IsZero() && !to.IsZero() && to.Before(from) {
+        return q, c.Error(http.StatusBadRequest, fmt.Errorf("completed_to %s is before completed_from %s", to.Format(dateFormat), from.Format(dateFormat)))
+    }
+    if !from.IsZero() {
+        q = q.Where("completions.completed_at >= ?", from)
+    }
+    if !to.IsZero() {
+        // The whole of the last day is included
+        q = q.Where("completions.completed_at < ?", to.AddDate(0, 0, 1))
+    }
+    return q, nil
+}
+
+// progressSQL is a completion's progress towards its target as a whole
+// percentage, the way Completion.PercentComplete works it out.
+const progressSQL = "(CASE WHEN completions.target > 0 THEN LEAST(completions.completions * 100 / completions.target, 100) ELSE 0 END)"
+
+// filterByProgress narrows q to completions at least "min_progress" and at
+// most "max_progress" percent of the way to their target, for whichever of
+// the two params are given. Completions without a target count as 0%. A
+// percentage that isn't a whole number from 0 to 100, or a minimum above
+// the maximum, is a bad request.
+func filterByProgress(c buffalo.Context, q *pop.Query) (*pop.Query, error) {
+    least, most := 0, 100
+    for _, b := range []struct{
+        param string
+        percent *int
+    }{
+        {"min_progress", &least},
+        {"max_progress", &most},
+    } {
+        value := c.Param(b.param)
+        if value == "" {
+            continue
+        }
+
+        percent, err := strconv.Atoi(value)
+        if err != nil || percent < 0 || percent > 100 {
+            return q, c.Error(http.StatusBadRequest, fmt.Errorf("%s %q is not a percentage from 0 to 100", b.param, value))
+        }
+        *b.percent = percent
+    }
+
+    if least > most {
+        return q, c.Error(http.StatusBadRequest, fmt.Errorf("min_progress %d is above max_progress %d", least, most))
+    }
+    if least > 0 {
+        q = q.Where(progressSQL+" >= ?", least)
+    }
+    if most < 100 {
+        q = q.Where(progressSQL+" <= ?", most)
+    }
+    return q, nil
+}
+
+// sortColumns are the columns a completion list can be sorted by with the
+// "sort" param, and the direction each is sorted in by default.
+var sortColumns = map[string]string{
+    "name": "asc",
+    "completed_at": "desc",
+    "completions": "desc",
+    "created_at": "desc",
+    "rating": "desc",
+}
+
+// defaultSort is the column completion lists are sorted by when no "sort"
+// param is given, so the most recently completed come first.
+const defaultSort = "completed_at"
+
+// sortByParams orders q by the column given in the "sort" param, or
+// defaultSort, in the direction sortOrder gives. Unrated and other empty
+// values always sort last, and completions that tie stay in the same
+// order from one page to the next. An unknown column is a bad request.
+func sortByParams(c buffalo.Context, q *pop.Query) (*pop.Query, error) {
+    column := c.Param("sort")
+    if column == "" {
+        column = defaultSort
+    }
+
+    if _, ok := sortColumns[column]; !ok {
+        return q, c.Error(http.StatusBadRequest, fmt.Errorf("can't sort by %q", column))
+    }
+
+    order, err := sortOrder(c, column)
+    if err != nil {
+        return q, err
+    }
+    return q.Order(fmt.Sprintf("completions.%s %s nulls last, completions.id %s", column, order, order)), nil
+}
+
+// sortOrder is the direction to sort column in: the "order" param's, or
+// the column's default one. An unknown direction is a bad request.
+func sortOrder(c buffalo.Context, column string) (string, error) {
+    order := sortColumns[column]
+    if o := strings.ToLower(c.Param("order")); o != "" {
+        if o != "asc" && o != "desc" {
+            return order, c.Error(http.StatusBadRequest, fmt.Errorf("unknown order %q", o))
+        }
+        order = o
+    }
+    return order, nil
+}
+
+// flipOrder is the opposite direction to order.
+func flipOrder(order string) string {
+    if order == "desc" {
+        return "asc"
+    }
+    return "desc"
+}
+
+// filterCompletions narrows q by every filter a completion list takes: the
+// lifecycle status, rating, completion date and progress. The first param
+// that isn't valid is a bad request. Lists are sorted as they are paged,
+// by paginate or paginateByCursor.
+func filterCompletions(c buffalo.Context, q *pop.Query) (*pop.Query, error) {
+    filters := []func(buffalo.Context, *pop.Query) (*pop.Query, error){
+        filterByStatus,
+        filterByRating,
+        filterByCompletedAt,
+        filterByProgress,
+    }
+
+    for _, filter := range filters {
+        var err error
+        if q, err = filter(c, q); err != nil {
+            return q, err
+        }
+    }
+    return q, nil
+}
+
+// sortPath links the current list sorted by column, starting again from
+// its first page. A list already sorted by column, including by default,
+// is flipped to the opposite direction.
+func sortPath(column string, help plush.HelperContext) string {
+    req, ok := help.Value("request").(*http.Request)
+    if !ok {
+        return "?sort=" + url.QueryEscape(column)
+    }
+
+    query := req.URL.Query()
+    order := sortColumns[column]
+    sorted := query.Get("sort")
+    if sorted == "" {
+        sorted = defaultSort
+    }
+    if sorted == column {
+        current := strings.ToLower(query.Get("order"))
+        if current == "" {
+            current = order
+        }
+        order = flipOrder(current)
+    }
+
+    query.Set("sort", column)
+    query.Set("order", order)
+    query.Del("page")
+    return req.URL.Path + "?" + query.Encode()
+}
+
+// filterByType narrows q to the completion type whose slug is given in the
+// "type" param, if there is one. An unknown type is a bad request.
+func filterByType(c buffalo.Context, q *pop.Query) (*pop.Query, error) {
+    slug := c.Param("type")
+    if slug == "" {
+        return q, nil
+    }
+
+    for _, t := range models.GetCompletionTypes() {
+        if t.Slug == slug {
+            return q.Where("type = ?", t.Name), nil
+        }
+    }
+    return q, c.Error(http.StatusBadRequest, fmt.Errorf("unknown completion type %q", slug))
+}
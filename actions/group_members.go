@@ -0,0 +1,245 @@
+package actions
+
+import (
+    "errors"
+    "fmt"
+    "net/http"
+
+    "completion_tracker/models"
+
+    "github.com/gobuffalo/buffalo"
+    "github.com/gobuffalo/pop/v6"
+    "github.com/gobuffalo/x/responder"
+)
+
+// GroupInvitesResource lets a Group's owners invite people to join it by
+// email. It is mounted at /groups/{group_id}/invites, and its HTML lives
+// on the Group's page.
+type GroupInvitesResource struct{}
+
+// Create invites the posted email to join the Group with the posted role.
+// Whoever signs in with that email can accept it from their groups page.
+// This function is mapped to the path POST /groups/{group_id}/invites
+func (v GroupInvitesResource) Create(c buffalo.Context) error {
+    // Get the DB connection from the context
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    group, membership, err := findManagedGroup(c, tx)
+    if err != nil {
+        return err
+    }
+
+    // Allocate an empty GroupInvite
+    invite := &models.GroupInvite{}
+
+    // Bind invite to the html form elements
+    if err := c.Bind(invite); err != nil {
+        return err
+    }
+    invite.GroupID = group.ID
+
+    // Validate the data from the html form
+    verrs, err := tx.ValidateAndCreate(invite)
+    if err != nil {
+        return err
+    }
+
+    if verrs.HasAny() {
+        return responder.Wants("html", func(c buffalo.Context) error {
+            // Render the Group's page again with the errors in its invite
+            // form
+            c.Set("errors", verrs)
+            c.Set("invite", invite)
+            return renderGroup(c, tx, group, membership, http.StatusUnprocessableEntity)
+        }).Wants("json", func(c buffalo.Context) error {
+            return renderValidationErrors(c, verrs)
+        }).Wants("xml", func(c buffalo.Context) error {
+            return c.Render(http.StatusUnprocessableEntity, r.XML(verrs))
+        }).Respond(c)
+    }
+
+    return responder.Wants("html", func(c buffalo.Context) error {
+        // If there are no errors set a success message
+        c.Flash().Add("success", T.Translate(c, "group.invite.created"))
+
+        // and redirect back to the Group
+        return c.Redirect(http.StatusSeeOther, "/groups/%v", group.ID)
+    }).Wants("json", func(c buffalo.Context) error {
+        return c.Render(http.StatusCreated, r.JSON(invite))
+    }).Wants("xml", func(c buffalo.Context) error {
+        return c.Render(http.StatusCreated, r.XML(invite))
+    }).Respond(c)
+}
+
+// Destroy withdraws an invite that hasn't been accepted yet. This function
+// is mapped to the path DELETE /groups/{group_id}/invites/{invite_id}
+func (v GroupInvitesResource) Destroy(c buffalo.Context) error {
+    // Get the DB connection from the context
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    group, _, err := findManagedGroup(c, tx)
+    if err != nil {
+        return err
+    }
+
+    // Allocate an empty GroupInvite
+    invite := &models.GroupInvite{}
+
+    // To find the GroupInvite the parameter invite_id is used, and it has
+    // to be one of the Group's.
+    if err := tx.Where("group_id = ?", group.ID).Find(invite, c.Param("invite_id")); err != nil {
+        return c.Error(http.StatusNotFound, err)
+    }
+
+    if err := tx.Destroy(invite); err != nil {
+        return err
+    }
+
+    return responder.Wants("html", func(c buffalo.Context) error {
+        // If there are no errors set a flash message
+        c.Flash().Add("success", T.Translate(c, "group.invite.withdrawn"))
+
+        // Redirect back to the Group
+        return c.Redirect(http.StatusSeeOther, "/groups/%v", group.ID)
+    }).Wants("json", func(c buffalo.Context) error {
+        return c.Render(http.StatusOK, r.JSON(invite))
+    }).Wants("xml", func(c buffalo.Context) error {
+        return c.Render(http.StatusOK, r.XML(invite))
+    }).Respond(c)
+}
+
+// GroupMembersResource lets a Group's owners change its members' roles and
+// remove them, and lets members leave. It is mounted at
+// /groups/{group_id}/members, and its HTML lives on the Group's page.
+type GroupMembersResource struct{}
+
+// findMember loads the Membership named by membership_id, provided it is
+// one of group's.
+func findMember(c buffalo.Context, tx *pop.Connection, group *models.Group) (*models.Membership, error) {
+    member := &models.Membership{}
+    if err := tx.Where("group_id = ?", group.ID).Find(member, c.Param("membership_id")); err != nil {
+        return nil, c.Error(http.StatusNotFound, err)
+    }
+    return member, nil
+}
+
+// Update changes a member's role. A Group's last owner can't be made
+// anything less. This function is mapped to the path
+// PUT /groups/{group_id}/members/{membership_id}
+func (v GroupMembersResource) Update(c buffalo.Context) error {
+    // Get the DB connection from the context
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    group, membership, err := findManagedGroup(c, tx)
+    if err != nil {
+        return err
+    }
+
+    member, err := findMember(c, tx, group)
+    if err != nil {
+        return err
+    }
+
+    // Only the role can be changed
+    form := &models.Membership{}
+    if err := c.Bind(form); err != nil {
+        return err
+    }
+    member.Role = form.Role
+
+    verrs, err := tx.ValidateAndUpdate(member)
+    if err != nil {
+        return err
+    }
+
+    if verrs.HasAny() {
+        return responder.Wants("html", func(c buffalo.Context) error {
+            // Render the Group's page again with the errors
+            c.Set("errors", verrs)
+            return renderGroup(c, tx, group, membership, http.StatusUnprocessableEntity)
+        }).Wants("json", func(c buffalo.Context) error {
+            return renderValidationErrors(c, verrs)
+        }).Wants("xml", func(c buffalo.Context) error {
+            return c.Render(http.StatusUnprocessableEntity, r.XML(verrs))
+        }).Respond(c)
+    }
+
+    return responder.Wants("html", func(c buffalo.Context) error {
+        // If there are no errors set a success message
+        c.Flash().Add("success", T.Translate(c, "group.member.updated"))
+
+        // and redirect back to the Group
+        return c.Redirect(http.StatusSeeOther, "/groups/%v", group.ID)
+    }).Wants("json", func(c buffalo.Context) error {
+        return c.Render(http.StatusOK, r.JSON(member))
+    }).Wants("xml", func(c buffalo.Context) error {
+        return c.Render(http.StatusOK, r.XML(member))
+    }).Respond(c)
+}
+
+// Destroy takes a member out of the Group. Owners can remove anyone, and
+// any member can remove themselves to leave, but a Group's last owner
+// can't go. This function is mapped to the path
+// DELETE /groups/{group_id}/members/{membership_id}
+func (v GroupMembersResource) Destroy(c buffalo.Context) error {
+    // Get the DB connection from the context
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    group, membership, err := findGroup(c, tx)
+    if err != nil {
+        return err
+    }
+
+    member, err := findMember(c, tx, group)
+    if err != nil {
+        return err
+    }
+
+    leaving := member.ID == membership.ID
+    if !leaving && !membership.Role.CanManage() {
+        return c.Error(http.StatusForbidden, errors.New("only the group's owners can remove its members"))
+    }
+
+    verrs, err := member.Remove(tx)
+    if err != nil {
+        return err
+    }
+
+    if verrs.HasAny() {
+        return responder.Wants("html", func(c buffalo.Context) error {
+            // Render the Group's page again with the errors
+            c.Set("errors", verrs)
+            return renderGroup(c, tx, group, membership, http.StatusUnprocessableEntity)
+        }).Wants("json", func(c buffalo.Context) error {
+            return renderValidationErrors(c, verrs)
+        }).Wants("xml", func(c buffalo.Context) error {
+            return c.Render(http.StatusUnprocessableEntity, r.XML(verrs))
+        }).Respond(c)
+    }
+
+    return responder.Wants("html", func(c buffalo.Context) error {
+        if leaving {
+            c.Flash().Add("success", T.Translate(c, "group.member.left"))
+            return c.Redirect(http.StatusSeeOther, "/groups")
+        }
+
+        c.Flash().Add("success", T.Translate(c, "group.member.removed"))
+        return c.Redirect(http.StatusSeeOther, "/groups/%v", group.ID)
+    }).Wants("json", func(c buffalo.Context) error {
+        return c.Render(http.StatusOK, r.JSON(member))
+    }).Wants("xml", func(c buffalo.Context) error {
+        return c.Render(http.StatusOK, r.XML(member))
+    }).Respond(c)
+}
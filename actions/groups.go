@@ -0,0 +1,328 @@
+package actions
+
+import (
+    "errors"
+    "fmt"
+    "net/http"
+
+    "completion_tracker/models"
+
+    "github.com/gobuffalo/buffalo"
+    "github.com/gobuffalo/pop/v6"
+    "github.com/gobuffalo/x/responder"
+)
+
+// GroupsResource manages groups: households or teams whose members share
+// completions. Every member can see a Group, its totals and the
+// completions shared with it; only its owners can change it.
+type GroupsResource struct{
+    buffalo.Resource
+}
+
+// findGroup loads the Group named by group_id and the signed in User's
+// Membership of it. Groups they are not a member of are treated as if they
+// don't exist.
+func findGroup(c buffalo.Context, tx *pop.Connection) (*models.Group, *models.Membership, error) {
+    group := &models.Group{}
+    if err := tx.Find(group, c.Param("group_id")); err != nil {
+        return nil, nil, c.Error(http.StatusNotFound, err)
+    }
+
+    membership, err := models.FindMembership(tx, group.ID, currentUser(c))
+    if err != nil {
+        return nil, nil, c.Error(http.StatusNotFound, err)
+    }
+    return group, membership, nil
+}
+
+// findManagedGroup loads the Group named by group_id, provided the signed
+// in User is one of its owners.
+func findManagedGroup(c buffalo.Context, tx *pop.Connection) (*models.Group, *models.Membership, error) {
+    group, membership, err := findGroup(c, tx)
+    if err != nil {
+        return nil, nil, err
+    }
+    if !membership.Role.CanManage() {
+        return nil, nil, c.Error(http.StatusForbidden, errors.New("only the group's owners can change it"))
+    }
+    return group, membership, nil
+}
+
+// checkGroup makes sure the signed in User can share completion with the
+// Group it names, which takes being one of its owners or editors.
+func checkGroup(c buffalo.Context, tx *pop.Connection, completion *models.Completion) error {
+    if !completion.GroupID.Valid {
+        return nil
+    }
+
+    membership, err := models.FindMembership(tx, completion.GroupID.UUID, currentUser(c))
+    if err != nil || !membership.Role.CanEdit() {
+        return c.Error(http.StatusForbidden, fmt.Errorf("can't share with group %s", completion.GroupID.UUID))
+    }
+    return nil
+}
+
+// setGroupOptions makes the groups the signed in User can share with
+// available to the completion forms, after a "No group" option.
+func setGroupOptions(c buffalo.Context, tx *pop.Connection) error {
+    memberships, err := models.MembershipsOf(tx, currentUser(c))
+    if err != nil {
+        return err
+    }
+
+    groups := models.Groups{{Name: "No group"}}
+    for _, m := range memberships {
+        if m.Role.CanEdit() && m.Group != nil {
+            groups = append(groups, *m.Group)
+        }
+    }
+    c.Set("groupOptions", groups)
+    return nil
+}
+
+// renderGroup renders the page of group, with its members, totals and
+// completions, for a member with membership. Its owners also see the
+// invites waiting to be accepted.
+func renderGroup(c buffalo.Context, tx *pop.Connection, group *models.Group, membership *models.Membership, status int) error {
+    if err := tx.Load(group, "Memberships.User"); err != nil {
+        return err
+    }
+    if err := group.LoadCompletions(tx); err != nil {
+        return err
+    }
+    if membership.Role.CanManage() {
+        if err := tx.Load(group, "Invites"); err != nil {
+            return err
+        }
+    }
+
+    return responder.Wants("html", func(c buffalo.Context) error {
+        c.Set("group", group)
+        c.Set("membership", membership)
+        c.Set("roles", models.GetRoles())
+        if _, ok := c.Value("invite").(*models.GroupInvite); !ok {
+            c.Set("invite", &models.GroupInvite{Role: models.RoleViewer})
+        }
+        return c.Render(status, r.HTML("groups/show.plush.html"))
+    }).Wants("json", func(c buffalo.Context) error {
+        return c.Render(status, r.JSON(group))
+    }).Wants("xml", func(c buffalo.Context) error {
+        return c.Render(status, r.XML(group))
+    }).Respond(c)
+}
+
+// List gets the groups the signed in User is a member of, with their role
+// in each. The html page also lists the invites waiting for them. This
+// function is mapped to the path GET /groups
+func (v GroupsResource) List(c buffalo.Context) error {
+    // Get the DB connection from the context
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    memberships, err := models.MembershipsOf(tx, currentUser(c))
+    if err != nil {
+        return err
+    }
+
+    return responder.Wants("html", func(c buffalo.Context) error {
+        invites, err := models.InvitesFor(tx, currentUser(c))
+        if err != nil {
+            return err
+        }
+        c.Set("memberships", memberships)
+        c.Set("invites", invites)
+        return c.Render(http.StatusOK, r.HTML("groups/index.plush.html"))
+    }).Wants("json", func(c buffalo.Context) error {
+        return c.Render(200, r.JSON(memberships))
+    }).Wants("xml", func(c buffalo.Context) error {
+        return c.Render(200, r.XML(memberships))
+    }).Respond(c)
+}
+
+// Show gets one Group with its members and the completions shared with
+// it, totalled by type and by member. This function is mapped to the path
+// GET /groups/{group_id}
+func (v GroupsResource) Show(c buffalo.Context) error {
+    // Get the DB connection from the context
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    group, membership, err := findGroup(c, tx)
+    if err != nil {
+        return err
+    }
+
+    return renderGroup(c, tx, group, membership, http.StatusOK)
+}
+
+// New renders the form for creating a new Group.
+// This function is mapped to the path GET /groups/new
+func (v GroupsResource) New(c buffalo.Context) error {
+    c.Set("group", &models.Group{})
+
+    return c.Render(http.StatusOK, r.HTML("groups/new.plush.html"))
+}
+
+// Create adds a Group to the DB with the signed in User as its owner. This
+// function is mapped to the path POST /groups
+func (v GroupsResource) Create(c buffalo.Context) error {
+    // Allocate an empty Group
+    group := &models.Group{}
+
+    // Bind group to the html form elements
+    if err := c.Bind(group); err != nil {
+        return err
+    }
+
+    // Get the DB connection from the context
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    // Validate the data from the html form
+    verrs, err := group.Create(tx, currentUser(c))
+    if err != nil {
+        return err
+    }
+
+    if verrs.HasAny() {
+        return responder.Wants("html", func(c buffalo.Context) error {
+            // Make the errors available inside the html template
+            c.Set("errors", verrs)
+
+            // Render again the new.html template that the user can
+            // correct the input.
+            c.Set("group", group)
+
+            return c.Render(http.StatusUnprocessableEntity, r.HTML("groups/new.plush.html"))
+        }).Wants("json", func(c buffalo.Context) error {
+            return renderValidationErrors(c, verrs)
+        }).Wants("xml", func(c buffalo.Context) error {
+            return c.Render(http.StatusUnprocessableEntity, r.XML(verrs))
+        }).Respond(c)
+    }
+
+    return responder.Wants("html", func(c buffalo.Context) error {
+        // If there are no errors set a success message
+        c.Flash().Add("success", T.Translate(c, "group.created.success"))
+
+        // and redirect to the show page
+        return c.Redirect(http.StatusSeeOther, "/groups/%v", group.ID)
+    }).Wants("json", func(c buffalo.Context) error {
+        return c.Render(http.StatusCreated, r.JSON(group))
+    }).Wants("xml", func(c buffalo.Context) error {
+        return c.Render(http.StatusCreated, r.XML(group))
+    }).Respond(c)
+}
+
+// Edit renders a edit form for a Group. This function is
+// mapped to the path GET /groups/{group_id}/edit
+func (v GroupsResource) Edit(c buffalo.Context) error {
+    // Get the DB connection from the context
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    group, _, err := findManagedGroup(c, tx)
+    if err != nil {
+        return err
+    }
+
+    c.Set("group", group)
+    return c.Render(http.StatusOK, r.HTML("groups/edit.plush.html"))
+}
+
+// Update changes a Group in the DB. This function is mapped to
+// the path PUT /groups/{group_id}
+func (v GroupsResource) Update(c buffalo.Context) error {
+    // Get the DB connection from the context
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    group, _, err := findManagedGroup(c, tx)
+    if err != nil {
+        return err
+    }
+
+    // Bind Group to the html form elements, keeping which group it is
+    persisted := *group
+    if err := c.Bind(group); err != nil {
+        return err
+    }
+    group.ID = persisted.ID
+    group.CreatedAt = persisted.CreatedAt
+
+    verrs, err := tx.ValidateAndUpdate(group)
+    if err != nil {
+        return err
+    }
+
+    if verrs.HasAny() {
+        return responder.Wants("html", func(c buffalo.Context) error {
+            // Make the errors available inside the html template
+            c.Set("errors", verrs)
+
+            // Render again the edit.html template that the user can
+            // correct the input.
+            c.Set("group", group)
+
+            return c.Render(http.StatusUnprocessableEntity, r.HTML("groups/edit.plush.html"))
+        }).Wants("json", func(c buffalo.Context) error {
+            return renderValidationErrors(c, verrs)
+        }).Wants("xml", func(c buffalo.Context) error {
+            return c.Render(http.StatusUnprocessableEntity, r.XML(verrs))
+        }).Respond(c)
+    }
+
+    return responder.Wants("html", func(c buffalo.Context) error {
+        // If there are no errors set a success message
+        c.Flash().Add("success", T.Translate(c, "group.updated.success"))
+
+        // and redirect to the show page
+        return c.Redirect(http.StatusSeeOther, "/groups/%v", group.ID)
+    }).Wants("json", func(c buffalo.Context) error {
+        return c.Render(http.StatusOK, r.JSON(group))
+    }).Wants("xml", func(c buffalo.Context) error {
+        return c.Render(http.StatusOK, r.XML(group))
+    }).Respond(c)
+}
+
+// Destroy deletes a Group from the DB. The completions shared with it go
+// back to being only their owners'. This function is mapped to the path
+// DELETE /groups/{group_id}
+func (v GroupsResource) Destroy(c buffalo.Context) error {
+    // Get the DB connection from the context
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    group, _, err := findManagedGroup(c, tx)
+    if err != nil {
+        return err
+    }
+
+    if err := tx.Destroy(group); err != nil {
+        return err
+    }
+
+    return responder.Wants("html", func(c buffalo.Context) error {
+        // If there are no errors set a flash message
+        c.Flash().Add("success", T.Translate(c, "group.destroyed.success"))
+
+        // Redirect to the index page
+        return c.Redirect(http.StatusSeeOther, "/groups")
+    }).Wants("json", func(c buffalo.Context) error {
+        return c.Render(http.StatusOK, r.JSON(group))
+    }).Wants("xml", func(c buffalo.Context) error {
+        return c.Render(http.StatusOK, r.XML(group))
+    }).Respond(c)
+}
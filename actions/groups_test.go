@@ -0,0 +1,200 @@
+package actions
+
+import (
+  "fmt"
+  "net/http"
+
+  "completion_tracker/models"
+
+  "github.com/gobuffalo/nulls"
+)
+
+// createGroup creates a group owned by the signed in user, with members
+// joining in the given roles.
+func (as *ActionSuite) createGroup(members map[*models.User]models.Role) *models.Group {
+  g := &models.Group{Name: "Our house"}
+  verrs, err := g.Create(as.DB, as.user)
+  as.NoError(err)
+  as.False(verrs.HasAny())
+
+  for u, role := range members {
+    as.NoError(as.DB.Create(&models.Membership{GroupID: g.ID, UserID: u.ID, Role: role}))
+  }
+  return g
+}
+
+func (as *ActionSuite) Test_GroupsResource_Create() {
+  res := as.HTML("/groups").Post(map[string]interface{}{"Name": "Game night"})
+  as.Equal(http.StatusSeeOther, res.Code)
+
+  g := &models.Group{}
+  as.NoError(as.DB.Where("name = ?", "Game night").First(g))
+  as.Equal(fmt.Sprintf("/groups/%s", g.ID), res.Location())
+
+  m, err := models.FindMembership(as.DB, g.ID, as.user)
+  as.NoError(err)
+  as.Equal(models.RoleOwner, m.Role)
+
+  res = as.HTML("/groups").Get()
+  as.Equal(http.StatusOK, res.Code)
+  as.Contains(res.Body.String(), "Game night")
+
+  as.Equal(http.StatusUnprocessableEntity, as.HTML("/groups").Post(map[string]interface{}{"Name": ""}).Code)
+}
+
+func (as *ActionSuite) Test_GroupsResource_Show() {
+  viewer := as.createUser("viewer@example.com")
+  g := as.createGroup(map[*models.User]models.Role{viewer: models.RoleViewer})
+  book := as.createBook("Dune", 206, 412)
+  book.GroupID = nulls.NewUUID(g.ID)
+  as.NoError(as.DB.Update(book))
+
+  res := as.HTML("/groups/%s", g.ID).Get()
+  as.Equal(http.StatusOK, res.Code)
+  as.Contains(res.Body.String(), "Dune")
+  as.Contains(res.Body.String(), "viewer@example.com")
+
+  jres := as.JSON("/groups/%s", g.ID).Get()
+  as.Equal(http.StatusOK, jres.Code)
+  as.Contains(jres.Body.String(), `"totals":[{"type":"Book","count":1`)
+
+  // Members can see the group, but only its owners can change it
+  as.signIn(viewer)
+  as.Equal(http.StatusOK, as.HTML("/groups/%s", g.ID).Get().Code)
+  as.Equal(http.StatusForbidden, as.HTML("/groups/%s/edit", g.ID).Get().Code)
+  as.Equal(http.StatusForbidden, as.HTML("/groups/%s", g.ID).Delete().Code)
+
+  as.signIn(as.createUser("stranger@example.com"))
+  as.Equal(http.StatusNotFound, as.HTML("/groups/%s", g.ID).Get().Code)
+}
+
+func (as *ActionSuite) Test_GroupsResource_Update_OtherGroups() {
+  g := as.createGroup(nil)
+
+  // Posting its id in an update of another group leaves it alone
+  as.user = as.createUser("stranger@example.com")
+  as.signIn(as.user)
+  theirs := as.createGroup(nil)
+
+  jres := as.JSON("/groups/%s", theirs.ID).Put(map[string]interface{}{
+    "id":   g.ID,
+    "name": "Book club",
+  })
+  as.Equal(http.StatusOK, jres.Code)
+
+  as.NoError(as.DB.Reload(theirs))
+  as.Equal("Book club", theirs.Name)
+
+  as.NoError(as.DB.Reload(g))
+  as.Equal("Our house", g.Name)
+}
+
+func (as *ActionSuite) Test_GroupInvites() {
+  g := as.createGroup(nil)
+  invited := as.createUser("invited@example.com")
+
+  res := as.HTML("/groups/%s/invites", g.ID).Post(map[string]interface{}{"Email": "Invited@Example.com", "Role": "editor"})
+  as.Equal(http.StatusSeeOther, res.Code)
+  invite := &models.GroupInvite{}
+  as.NoError(as.DB.Where("email = ?", "invited@example.com").First(invite))
+
+  as.Equal(http.StatusUnprocessableEntity, as.HTML("/groups/%s/invites", g.ID).Post(map[string]interface{}{"Email": "invited@example.com", "Role": "editor"}).Code)
+
+  // Only the person invited can accept
+  as.signIn(as.createUser("stranger@example.com"))
+  as.Equal(http.StatusNotFound, as.HTML("/invites/%s/accept", invite.ID).Post(nil).Code)
+  as.Equal(http.StatusNotFound, as.HTML("/groups/%s", g.ID).Get().Code)
+
+  as.signIn(invited)
+  res = as.HTML("/groups").Get()
+  as.Contains(res.Body.String(), "Our house")
+
+  res = as.HTML("/invites/%s/accept", invite.ID).Post(nil)
+  as.Equal(http.StatusSeeOther, res.Code)
+  as.Equal(fmt.Sprintf("/groups/%s", g.ID), res.Location())
+
+  m, err := models.FindMembership(as.DB, g.ID, invited)
+  as.NoError(err)
+  as.Equal(models.RoleEditor, m.Role)
+
+  // Editors can't invite anyone
+  as.Equal(http.StatusForbidden, as.HTML("/groups/%s/invites", g.ID).Post(map[string]interface{}{"Email": "friend@example.com", "Role": "viewer"}).Code)
+}
+
+func (as *ActionSuite) Test_GroupMembers() {
+  member := as.createUser("member@example.com")
+  g := as.createGroup(map[*models.User]models.Role{member: models.RoleViewer})
+  m, err := models.FindMembership(as.DB, g.ID, member)
+  as.NoError(err)
+  own, err := models.FindMembership(as.DB, g.ID, as.user)
+  as.NoError(err)
+
+  res := as.HTML("/groups/%s/members/%s", g.ID, m.ID).Put(map[string]interface{}{"Role": "editor"})
+  as.Equal(http.StatusSeeOther, res.Code)
+  as.NoError(as.DB.Reload(m))
+  as.Equal(models.RoleEditor, m.Role)
+
+  // The last owner can't leave
+  res = as.HTML("/groups/%s/members/%s", g.ID, own.ID).Delete()
+  as.Equal(http.StatusUnprocessableEntity, res.Code)
+
+  // Members can leave, but can't remove anyone else
+  as.signIn(member)
+  as.Equal(http.StatusForbidden, as.HTML("/groups/%s/members/%s", g.ID, own.ID).Delete().Code)
+  res = as.HTML("/groups/%s/members/%s", g.ID, m.ID).Delete()
+  as.Equal(http.StatusSeeOther, res.Code)
+  as.Equal("/groups", res.Location())
+  as.Equal(http.StatusNotFound, as.HTML("/groups/%s", g.ID).Get().Code)
+}
+
+func (as *ActionSuite) Test_GroupCompletions() {
+  editor := as.createUser("editor@example.com")
+  viewer := as.createUser("viewer@example.com")
+  g := as.createGroup(map[*models.User]models.Role{editor: models.RoleEditor, viewer: models.RoleViewer})
+
+  res := as.JSON("/books").Post(map[string]interface{}{
+    "name":     "Dune",
+    "target":   412,
+    "group_id": g.ID,
+  })
+  as.Equal(http.StatusCreated, res.Code)
+  book := &models.Completion{}
+  as.NoError(as.DB.Where("name = ?", "Dune").First(book))
+  as.Equal(g.ID, book.GroupID.UUID)
+
+  // Viewers can see it, but not change it
+  as.signIn(viewer)
+  as.Contains(as.HTML("/books").Get().Body.String(), "Dune")
+  as.Equal(http.StatusOK, as.HTML("/books/%s", book.ID).Get().Code)
+  as.Equal(http.StatusNotFound, as.HTML("/books/%s/edit", book.ID).Get().Code)
+  as.Equal(http.StatusNotFound, as.JSON("/books/%s", book.ID).Put(map[string]interface{}{"name": "Dune", "completions": 50}).Code)
+  as.Equal(http.StatusNotFound, as.JSON("/completions/%s/entries", book.ID).Post(map[string]interface{}{"units": 10}).Code)
+
+  // Editors can update its progress, but it stays the owner's and in
+  // the group, whatever they send
+  as.signIn(editor)
+  res = as.JSON("/books/%s", book.ID).Put(map[string]interface{}{
+    "name":        "Dune",
+    "completions": 100,
+    "target":      412,
+    "privacy":     "public",
+    "group_id":    nil,
+  })
+  as.Equal(http.StatusOK, res.Code)
+  as.NoError(as.DB.Reload(book))
+  as.Equal(100, book.Completions)
+  as.Equal(as.user.ID, book.UserID.UUID)
+  as.Equal(models.PrivacyPrivate, book.Privacy)
+  as.Equal(g.ID, book.GroupID.UUID)
+
+  // Only its owner can put it in the trash
+  as.Equal(http.StatusNotFound, as.HTML("/books/%s", book.ID).Delete().Code)
+
+  // Nobody can share with a group they don't edit
+  as.signIn(viewer)
+  res = as.JSON("/books").Post(map[string]interface{}{"name": "Emma", "group_id": g.ID})
+  as.Equal(http.StatusForbidden, res.Code)
+
+  as.signIn(as.createUser("stranger@example.com"))
+  as.Equal(http.StatusNotFound, as.HTML("/books/%s", book.ID).Get().Code)
+}
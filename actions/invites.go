@@ -0,0 +1,112 @@
+package actions
+
+import (
+    "errors"
+    "fmt"
+    "net/http"
+
+    "completion_tracker/models"
+
+    "github.com/gobuffalo/buffalo"
+    "github.com/gobuffalo/pop/v6"
+    "github.com/gobuffalo/x/responder"
+)
+
+// InvitesResource is the other side of GroupInvitesResource: the invites
+// to join groups that are waiting for the signed in User, found by their
+// email, which they can accept or decline.
+type InvitesResource struct{}
+
+// findInvite loads the GroupInvite named by invite_id, provided it was
+// sent to the signed in User's email.
+func findInvite(c buffalo.Context, tx *pop.Connection) (*models.GroupInvite, error) {
+    invite := &models.GroupInvite{}
+    if err := tx.Eager("Group").Where("email = ?", currentUser(c).Email).Find(invite, c.Param("invite_id")); err != nil {
+        return nil, c.Error(http.StatusNotFound, err)
+    }
+    return invite, nil
+}
+
+// List gets the invites waiting for the signed in User. This function is
+// mapped to the path GET /invites
+func (v InvitesResource) List(c buffalo.Context) error {
+    // Get the DB connection from the context
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    invites, err := models.InvitesFor(tx, currentUser(c))
+    if err != nil {
+        return err
+    }
+
+    return responder.Wants("html", func(c buffalo.Context) error {
+        c.Set("invites", invites)
+        return c.Render(http.StatusOK, r.HTML("invites/index.plush.html"))
+    }).Wants("json", func(c buffalo.Context) error {
+        return c.Render(200, r.JSON(invites))
+    }).Wants("xml", func(c buffalo.Context) error {
+        return c.Render(200, r.XML(invites))
+    }).Respond(c)
+}
+
+// Accept makes the signed in User a member of the invite's Group. This
+// function is mapped to the path POST /invites/{invite_id}/accept
+func (v InvitesResource) Accept(c buffalo.Context) error {
+    // Get the DB connection from the context
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    invite, err := findInvite(c, tx)
+    if err != nil {
+        return err
+    }
+
+    membership, err := invite.Accept(tx, currentUser(c))
+    if errors.Is(err, models.ErrNotInvited) {
+        return c.Error(http.StatusNotFound, err)
+    }
+    if err != nil {
+        return err
+    }
+
+    return responder.Wants("html", func(c buffalo.Context) error {
+        c.Flash().Add("success", T.Translate(c, "group.invite.accepted"))
+        return c.Redirect(http.StatusSeeOther, "/groups/%v", membership.GroupID)
+    }).Wants("json", func(c buffalo.Context) error {
+        return c.Render(http.StatusCreated, r.JSON(membership))
+    }).Wants("xml", func(c buffalo.Context) error {
+        return c.Render(http.StatusCreated, r.XML(membership))
+    }).Respond(c)
+}
+
+// Decline turns an invite down. This function is mapped to the path
+// DELETE /invites/{invite_id}
+func (v InvitesResource) Decline(c buffalo.Context) error {
+    // Get the DB connection from the context
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    invite, err := findInvite(c, tx)
+    if err != nil {
+        return err
+    }
+
+    if err := tx.Destroy(invite); err != nil {
+        return err
+    }
+
+    return responder.Wants("html", func(c buffalo.Context) error {
+        c.Flash().Add("success", T.Translate(c, "group.invite.declined"))
+        return c.Redirect(http.StatusSeeOther, "/groups")
+    }).Wants("json", func(c buffalo.Context) error {
+        return c.Render(http.StatusOK, r.JSON(invite))
+    }).Wants("xml", func(c buffalo.Context) error {
+        return c.Render(http.StatusOK, r.XML(invite))
+    }).Respond(c)
+}
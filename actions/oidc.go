@@ -0,0 +1,230 @@
+package actions
+
+import (
+    "context"
+    "crypto/rand"
+    "encoding/base64"
+    "errors"
+    "fmt"
+    "net/http"
+    "strings"
+    "sync"
+
+    "completion_tracker/models"
+
+    "github.com/coreos/go-oidc/v3/oidc"
+    "github.com/gobuffalo/buffalo"
+    "github.com/gobuffalo/envy"
+    "github.com/gobuffalo/pop/v6"
+    "golang.org/x/oauth2"
+)
+
+// oidcLogin signs users in through an OpenID Connect provider, such as a
+// company's identity provider, using the authorization code flow with
+// PKCE. It is configured by these environment variables, and is off
+// unless OIDC_ISSUER is set:
+//
+//	OIDC_ISSUER         the provider's issuer URL, used for discovery
+//	OIDC_CLIENT_ID      the client registered with the provider
+//	OIDC_CLIENT_SECRET  its secret, if it has one
+//	OIDC_REDIRECT_URL   defaults to the app's HOST + /auth/oidc/callback
+//	OIDC_PROVIDER_NAME  shown on the sign in button, default "Single Sign-On"
+type oidcLogin struct {
+    config   oauth2.Config
+    verifier *oidc.IDTokenVerifier
+}
+
+var (
+    oidcMu     sync.Mutex
+    oidcLoaded *oidcLogin
+)
+
+// oidcProviderName is the name of the configured single sign-on provider,
+// or empty when there isn't one.
+func oidcProviderName() string {
+    if envy.Get("OIDC_ISSUER", "") == "" {
+        return ""
+    }
+    return envy.Get("OIDC_PROVIDER_NAME", "Single Sign-On")
+}
+
+// loadOIDC discovers the configured provider the first time it is needed,
+// so that the app starts even when the provider can't be reached.
+func loadOIDC() (*oidcLogin, error) {
+    oidcMu.Lock()
+    defer oidcMu.Unlock()
+
+    if oidcLoaded != nil {
+        return oidcLoaded, nil
+    }
+
+    issuer := envy.Get("OIDC_ISSUER", "")
+    if issuer == "" {
+        return nil, errors.New("single sign-on is not configured")
+    }
+
+    // The provider keeps the context to fetch its signing keys later, so
+    // it mustn't be a request's.
+    provider, err := oidc.NewProvider(context.Background(), issuer)
+    if err != nil {
+        return nil, fmt.Errorf("discovering %s: %w", issuer, err)
+    }
+
+    clientID := envy.Get("OIDC_CLIENT_ID", "")
+    oidcLoaded = &oidcLogin{
+        config: oauth2.Config{
+            ClientID:     clientID,
+            ClientSecret: envy.Get("OIDC_CLIENT_SECRET", ""),
+            RedirectURL:  envy.Get("OIDC_REDIRECT_URL", strings.TrimSuffix(App().Host, "/")+"/auth/oidc/callback"),
+            Endpoint:     provider.Endpoint(),
+            Scopes:       []string{oidc.ScopeOpenID, "email", "profile"},
+        },
+        verifier: provider.Verifier(&oidc.Config{ClientID: clientID}),
+    }
+    return oidcLoaded, nil
+}
+
+// randomString is a random URL-safe string for an OIDC state or nonce.
+func randomString() (string, error) {
+    b := make([]byte, 32)
+    if _, err := rand.Read(b); err != nil {
+        return "", err
+    }
+    return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// OIDCStart sends the browser to the provider to sign in. The state,
+// nonce and PKCE verifier that the callback checks are kept in the
+// session. This function is mapped to the path GET /auth/oidc
+func OIDCStart(c buffalo.Context) error {
+    if oidcProviderName() == "" {
+        return c.Error(http.StatusNotFound, errors.New("single sign-on is not configured"))
+    }
+
+    login, err := loadOIDC()
+    if err != nil {
+        return err
+    }
+
+    state, err := randomString()
+    if err != nil {
+        return err
+    }
+    nonce, err := randomString()
+    if err != nil {
+        return err
+    }
+    verifier := oauth2.GenerateVerifier()
+
+    c.Session().Set("oidc_state", state)
+    c.Session().Set("oidc_nonce", nonce)
+    c.Session().Set("oidc_verifier", verifier)
+
+    url := login.config.AuthCodeURL(state, oidc.Nonce(nonce), oauth2.S256ChallengeOption(verifier))
+    return c.Redirect(http.StatusFound, url)
+}
+
+// OIDCCallback is where the provider sends the browser back. It exchanges
+// the code for an ID token, checks it, and signs in the User it belongs
+// to, creating them on their first visit. A User who is already signed in
+// links the identity to their account instead. This function is mapped to
+// the path GET /auth/oidc/callback
+func OIDCCallback(c buffalo.Context) error {
+    if oidcProviderName() == "" {
+        return c.Error(http.StatusNotFound, errors.New("single sign-on is not configured"))
+    }
+
+    login, err := loadOIDC()
+    if err != nil {
+        return err
+    }
+
+    // The state, nonce and verifier are only good for one attempt
+    state, _ := c.Session().Get("oidc_state").(string)
+    nonce, _ := c.Session().Get("oidc_nonce").(string)
+    verifier, _ := c.Session().Get("oidc_verifier").(string)
+    c.Session().Delete("oidc_state")
+    c.Session().Delete("oidc_nonce")
+    c.Session().Delete("oidc_verifier")
+
+    if e := c.Param("error"); e != "" {
+        return oidcFailed(c, fmt.Errorf("the provider refused: %s %s", e, c.Param("error_description")))
+    }
+    if state == "" || c.Param("state") != state {
+        return oidcFailed(c, errors.New("state doesn't match"))
+    }
+
+    token, err := login.config.Exchange(c, c.Param("code"), oauth2.VerifierOption(verifier))
+    if err != nil {
+        return oidcFailed(c, err)
+    }
+
+    raw, ok := token.Extra("id_token").(string)
+    if !ok {
+        return oidcFailed(c, errors.New("no id_token in the token response"))
+    }
+    idToken, err := login.verifier.Verify(c, raw)
+    if err != nil {
+        return oidcFailed(c, err)
+    }
+    if idToken.Nonce != nonce {
+        return oidcFailed(c, errors.New("nonce doesn't match"))
+    }
+
+    var claims struct {
+        Email         string `json:"email"`
+        EmailVerified bool   `json:"email_verified"`
+    }
+    if err := idToken.Claims(&claims); err != nil {
+        return oidcFailed(c, err)
+    }
+
+    // Get the DB connection from the context
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    identity := models.OIDCIdentity{
+        Issuer:        idToken.Issuer,
+        Subject:       idToken.Subject,
+        Email:         claims.Email,
+        EmailVerified: claims.EmailVerified,
+    }
+
+    if u := currentUser(c); u != nil {
+        err := models.LinkOIDC(tx, u, identity)
+        if errors.Is(err, models.ErrIdentityTaken) {
+            c.Flash().Add("danger", T.Translate(c, "auth.oidc.identity_taken"))
+            return c.Redirect(http.StatusSeeOther, "/account")
+        }
+        if err != nil {
+            return oidcFailed(c, err)
+        }
+        c.Flash().Add("success", T.Translate(c, "auth.oidc.linked"))
+        return c.Redirect(http.StatusSeeOther, "/account")
+    }
+
+    user, err := models.SignInWithOIDC(tx, identity)
+    if errors.Is(err, models.ErrEmailTaken) {
+        c.Flash().Add("danger", T.Translate(c, "auth.oidc.email_taken"))
+        return c.Redirect(http.StatusSeeOther, "/signin")
+    }
+    if errors.Is(err, models.ErrEmailUnverified) {
+        c.Flash().Add("danger", T.Translate(c, "auth.oidc.email_unverified"))
+        return c.Redirect(http.StatusSeeOther, "/signin")
+    }
+    if err != nil {
+        return oidcFailed(c, err)
+    }
+
+    return signIn(c, user)
+}
+
+// oidcFailed sends the browser back to sign in after single sign-on went
+// wrong, logging why.
+func oidcFailed(c buffalo.Context, err error) error {
+    c.Logger().Warnf("single sign-on failed: %v", err)
+    c.Flash().Add("danger", T.Translate(c, "auth.oidc.failed"))
+    return c.Redirect(http.StatusSeeOther, "/signin")
+}
@@ -0,0 +1,279 @@
+package actions
+
+import (
+  "crypto"
+  "crypto/rand"
+  "crypto/rsa"
+  "crypto/sha256"
+  "encoding/base64"
+  "encoding/json"
+  "fmt"
+  "math/big"
+  "net/http"
+  "net/http/httptest"
+  "net/url"
+  "time"
+
+  "completion_tracker/models"
+
+  "github.com/gobuffalo/envy"
+)
+
+// stubProvider is a minimal OpenID Connect provider to sign in against.
+// Its codes are handed out by authorize rather than a login page, and
+// each can only be exchanged with the PKCE verifier it was issued for.
+type stubProvider struct {
+  *httptest.Server
+  key   *rsa.PrivateKey
+  codes map[string]stubCode
+}
+
+// stubCode is an authorization code the stub has issued.
+type stubCode struct {
+  challenge string
+  nonce     string
+  claims    map[string]interface{}
+}
+
+func newStubProvider() *stubProvider {
+  key, err := rsa.GenerateKey(rand.Reader, 2048)
+  if err != nil {
+    panic(err)
+  }
+  p := &stubProvider{key: key, codes: map[string]stubCode{}}
+
+  mux := http.NewServeMux()
+  mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+    json.NewEncoder(w).Encode(map[string]interface{}{
+      "issuer":                                p.URL,
+      "authorization_endpoint":                p.URL + "/authorize",
+      "token_endpoint":                        p.URL + "/token",
+      "jwks_uri":                              p.URL + "/jwks",
+      "id_token_signing_alg_values_supported": []string{"RS256"},
+    })
+  })
+  mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+    json.NewEncoder(w).Encode(map[string]interface{}{
+      "keys": []map[string]string{{
+        "kty": "RSA",
+        "alg": "RS256",
+        "use": "sig",
+        "kid": "stub",
+        "n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+        "e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+      }},
+    })
+  })
+  mux.HandleFunc("/token", p.token)
+  p.Server = httptest.NewServer(mux)
+  return p
+}
+
+// authorize plays the provider's login page for the authorization URL the
+// app redirected to, signing in someone with claims. It returns the code
+// and state to send back to the callback.
+func (p *stubProvider) authorize(location string, claims map[string]interface{}) (code, state string) {
+  u, err := url.Parse(location)
+  if err != nil {
+    panic(err)
+  }
+  q := u.Query()
+  code = fmt.Sprintf("code-%d", len(p.codes))
+  p.codes[code] = stubCode{challenge: q.Get("code_challenge"), nonce: q.Get("nonce"), claims: claims}
+  return code, q.Get("state")
+}
+
+func (p *stubProvider) token(w http.ResponseWriter, r *http.Request) {
+  issued, ok := p.codes[r.FormValue("code")]
+  sum := sha256.Sum256([]byte(r.FormValue("code_verifier")))
+  if !ok || base64.RawURLEncoding.EncodeToString(sum[:]) != issued.challenge {
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(http.StatusBadRequest)
+    w.Write([]byte(`{"error":"invalid_grant"}`))
+    return
+  }
+
+  claims := map[string]interface{}{
+    "iss":   p.URL,
+    "aud":   "completion-tracker",
+    "iat":   time.Now().Unix(),
+    "exp":   time.Now().Add(time.Hour).Unix(),
+    "nonce": issued.nonce,
+  }
+  for k, v := range issued.claims {
+    claims[k] = v
+  }
+
+  w.Header().Set("Content-Type", "application/json")
+  json.NewEncoder(w).Encode(map[string]interface{}{
+    "access_token": "stub-access-token",
+    "token_type":   "Bearer",
+    "expires_in":   3600,
+    "id_token":     p.sign(claims),
+  })
+}
+
+// sign makes an RS256 JWT of claims.
+func (p *stubProvider) sign(claims map[string]interface{}) string {
+  header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": "stub"})
+  payload, _ := json.Marshal(claims)
+  signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+  sum := sha256.Sum256([]byte(signed))
+  sig, err := rsa.SignPKCS1v15(rand.Reader, p.key, crypto.SHA256, sum[:])
+  if err != nil {
+    panic(err)
+  }
+  return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+// withStubProvider runs fn with single sign-on configured against a stub
+// provider, signed out.
+func (as *ActionSuite) withStubProvider(fn func(p *stubProvider)) {
+  p := newStubProvider()
+  defer p.Close()
+
+  envy.Temp(func() {
+    envy.Set("OIDC_ISSUER", p.URL)
+    envy.Set("OIDC_CLIENT_ID", "completion-tracker")
+    envy.Set("OIDC_PROVIDER_NAME", "Stub")
+    oidcLoaded = nil
+    defer func() { oidcLoaded = nil }()
+
+    as.Session.Clear()
+    fn(p)
+  })
+}
+
+// oidcSignIn goes through single sign-on as someone with claims, returning
+// the callback's response.
+func (as *ActionSuite) oidcSignIn(p *stubProvider, claims map[string]interface{}) *httptest.ResponseRecorder {
+  res := as.HTML("/auth/oidc").Get()
+  as.Equal(http.StatusFound, res.Code)
+  code, state := p.authorize(res.Location(), claims)
+
+  return as.HTML("/auth/oidc/callback?code=%s&state=%s", code, state).Get().ResponseRecorder
+}
+
+func (as *ActionSuite) Test_OIDC_Disabled() {
+  as.Session.Clear()
+  as.Equal(http.StatusNotFound, as.HTML("/auth/oidc").Get().Code)
+
+  res := as.HTML("/signin").Get()
+  as.Equal(http.StatusOK, res.Code)
+  as.NotContains(res.Body.String(), "/auth/oidc")
+}
+
+func (as *ActionSuite) Test_OIDC_Start() {
+  as.withStubProvider(func(p *stubProvider) {
+    res := as.HTML("/signin").Get()
+    as.Contains(res.Body.String(), "Sign in with Stub")
+
+    res = as.HTML("/auth/oidc").Get()
+    as.Equal(http.StatusFound, res.Code)
+
+    u, err := url.Parse(res.Location())
+    as.NoError(err)
+    as.Equal(p.URL+"/authorize", u.Scheme+"://"+u.Host+u.Path)
+    as.Equal("code", u.Query().Get("response_type"))
+    as.Equal("S256", u.Query().Get("code_challenge_method"))
+    as.NotEmpty(u.Query().Get("code_challenge"))
+    as.NotEmpty(u.Query().Get("nonce"))
+    as.Equal(as.Session.Get("oidc_state"), u.Query().Get("state"))
+  })
+}
+
+func (as *ActionSuite) Test_OIDC_CreatesUser() {
+  as.withStubProvider(func(p *stubProvider) {
+    res := as.oidcSignIn(p, map[string]interface{}{"sub": "42", "email": "New@Example.com", "email_verified": true})
+    as.Equal(http.StatusSeeOther, res.Code)
+    as.Equal("/", res.Header().Get("Location"))
+
+    u := &models.User{}
+    as.NoError(as.DB.Where("email = ?", "new@example.com").First(u))
+    as.Equal(p.URL, u.OIDCIssuer.String)
+    as.Equal("42", u.OIDCSubject.String)
+    as.Empty(u.PasswordHash)
+    as.Equal(u.ID.String(), as.Session.Get("current_user_id"))
+  })
+}
+
+func (as *ActionSuite) Test_OIDC_LinksUser() {
+  as.withStubProvider(func(p *stubProvider) {
+    // A verified email doesn't sign in to the account that has it
+    res := as.oidcSignIn(p, map[string]interface{}{"sub": "42", "email": "reader@example.com", "email_verified": true})
+    as.Equal(http.StatusSeeOther, res.Code)
+    as.Equal("/signin", res.Header().Get("Location"))
+    as.Nil(as.Session.Get("current_user_id"))
+
+    // Its owner links the identity once signed in
+    as.signIn(as.user)
+    res = as.HTML("/account").Get().ResponseRecorder
+    as.Contains(res.Body.String(), "Link Stub")
+
+    res = as.oidcSignIn(p, map[string]interface{}{"sub": "42", "email": "reader@example.com", "email_verified": true})
+    as.Equal(http.StatusSeeOther, res.Code)
+    as.Equal("/account", res.Header().Get("Location"))
+
+    // and from then on it signs in to the same account whatever its
+    // email becomes
+    as.Session.Clear()
+    res = as.oidcSignIn(p, map[string]interface{}{"sub": "42", "email": "reader@work.example.com", "email_verified": true})
+    as.Equal(http.StatusSeeOther, res.Code)
+    as.Equal(as.user.ID.String(), as.Session.Get("current_user_id"))
+
+    count, err := as.DB.Count(&models.User{})
+    as.NoError(err)
+    as.Equal(1, count)
+
+    // The password still works too
+    _, err = models.AuthenticateUser(as.DB, "reader@example.com", testPassword)
+    as.NoError(err)
+  })
+}
+
+func (as *ActionSuite) Test_OIDC_UnverifiedEmail() {
+  as.withStubProvider(func(p *stubProvider) {
+    // An unverified email can't take over the account that has it
+    res := as.oidcSignIn(p, map[string]interface{}{"sub": "42", "email": "reader@example.com"})
+    as.Equal(http.StatusSeeOther, res.Code)
+    as.Equal("/signin", res.Header().Get("Location"))
+    as.Nil(as.Session.Get("current_user_id"))
+
+    // nor get an account of its own
+    res = as.oidcSignIn(p, map[string]interface{}{"sub": "43", "email": "new@example.com"})
+    as.Equal("/signin", res.Header().Get("Location"))
+    as.Nil(as.Session.Get("current_user_id"))
+  })
+}
+
+func (as *ActionSuite) Test_OIDC_Rejected() {
+  as.withStubProvider(func(p *stubProvider) {
+    claims := map[string]interface{}{"sub": "42", "email": "new@example.com", "email_verified": true}
+
+    // A state that doesn't match the session's is refused
+    res := as.HTML("/auth/oidc").Get()
+    code, _ := p.authorize(res.Location(), claims)
+    res = as.HTML("/auth/oidc/callback?code=%s&state=forged", code).Get()
+    as.Equal("/signin", res.Location())
+    as.Nil(as.Session.Get("current_user_id"))
+
+    // A code can't be exchanged without the verifier it was issued for
+    res = as.HTML("/auth/oidc").Get()
+    code, _ = p.authorize(res.Location(), claims)
+    res = as.HTML("/auth/oidc").Get()
+    _, state := p.authorize(res.Location(), claims)
+    res = as.HTML("/auth/oidc/callback?code=%s&state=%s", code, state).Get()
+    as.Equal("/signin", res.Location())
+    as.Nil(as.Session.Get("current_user_id"))
+
+    // The provider can refuse too
+    as.HTML("/auth/oidc").Get()
+    res = as.HTML("/auth/oidc/callback?error=access_denied&state=%s", as.Session.Get("oidc_state")).Get()
+    as.Equal("/signin", res.Location())
+
+    count, err := as.DB.Where("email = ?", "new@example.com").Count(&models.User{})
+    as.NoError(err)
+    as.Equal(0, count)
+  })
+}
@@ -0,0 +1,665 @@
+package actions
+
+import (
+    "net/http"
+    "reflect"
+    "sort"
+    "strconv"
+    "strings"
+    "time"
+
+    "completion_tracker/models"
+
+    "github.com/gobuffalo/buffalo"
+    "github.com/gobuffalo/nulls"
+    "github.com/gobuffalo/validate/v3"
+    "github.com/gofrs/uuid"
+)
+
+// completionRoots are the first segments of the paths of the completion
+// routes, and of the series, tags, groups and invites around them, with or
+// without apiPrefix, that the OpenAPI document describes. Every route under
+// apiPrefix must start with one, which the tests catch.
+var completionRoots = []string{"completions", "tv_shows", "video_games", "books", "audio_books", "events", "series", "tags", "groups", "invites", "search", "trash"}
+
+// apiOperation documents a completion route. Query names the query
+// params it takes from apiParameters, and Body and Response are the
+// types it binds and renders. Status is the status of a success, 200
+// unless set.
+type apiOperation struct{
+    Tag string
+    Summary string
+    Query []string
+    Body reflect.Type
+    Response reflect.Type
+    Status int
+}
+
+// apiOperations documents every completion route, keyed by its method
+// and its path outside apiPrefix. A route missing from here is left out
+// of the OpenAPI document, which the tests catch.
+var apiOperations = completionOperations()
+
+func completionOperations() map[string]apiOperation {
+    completion := reflect.TypeOf(models.Completion{})
+    completions := reflect.TypeOf(models.Completions{})
+    pages := []string{"page", "per_page"}
+    filters := []string{"status", "rating", "min_rating", "max_rating", "completed_from", "completed_to", "min_progress", "max_progress", "sort", "order", "page", "per_page", "cursor"}
+
+    ops := map[string]apiOperation{}
+    resource := func(tag, path, param, one, many string, item, list reflect.Type, query ...string) {
+        destroy := "Delete " + one
+        if item == completion {
+            destroy = "Move " + one + " to the trash"
+        }
+        single := path + "/{" + param + "}"
+        ops["GET "+path] = apiOperation{Tag: tag, Summary: "List " + many, Query: query, Response: list}
+        ops["POST "+path] = apiOperation{Tag: tag, Summary: "Create " + one, Body: item, Response: item, Status: http.StatusCreated}
+        ops["GET "+single] = apiOperation{Tag: tag, Summary: "Show " + one, Response: item}
+        ops["PUT "+single] = apiOperation{Tag: tag, Summary: "Update " + one, Body: item, Response: item}
+        ops["DELETE "+single] = apiOperation{Tag: tag, Summary: destroy, Response: item}
+    }
+
+    resource("Completions", "/completions", "completion_id", "a completion", "completions of every type", completion, completions, append([]string{"type"}, filters...)...)
+    resource("Progress entries", "/completions/{completion_id}/entries", "progress_entry_id", "a progress entry", "the progress entries of a run, newest first",
+        reflect.TypeOf(models.ProgressEntry{}), reflect.TypeOf(models.ProgressEntries{}), append([]string{"run"}, pages...)...)
+    resource("Runs", "/completions/{completion_id}/runs", "run_id", "a run", "the runs of a completion",
+        reflect.TypeOf(models.Run{}), reflect.TypeOf(models.Runs{}))
+    ops["GET /completions/{completion_id}/revisions"] = apiOperation{Tag: "Revisions", Summary: "List the revisions of a completion, newest first", Query: pages, Response: reflect.TypeOf(models.Revisions{})}
+
+    resource("TV shows", "/tv_shows", "tv_show_id", "a TV show", "TV shows", completion, completions, filters...)
+    resource("Seasons", "/tv_shows/{tv_show_id}/seasons", "season_id", "a season", "the seasons of a TV show",
+        reflect.TypeOf(models.Season{}), reflect.TypeOf(models.Seasons{}))
+    resource("Episodes", "/tv_shows/{tv_show_id}/seasons/{season_id}/episodes", "episode_id", "an episode", "the episodes of a season",
+        reflect.TypeOf(models.Episode{}), reflect.TypeOf(models.Episodes{}))
+    resource("Video games", "/video_games", "video_game_id", "a video game", "video games", completion, completions, filters...)
+    resource("Books", "/books", "book_id", "a book", "books", completion, completions, filters...)
+    resource("Audio books", "/audio_books", "audio_book_id", "an audio book", "audio books", completion, completions, filters...)
+    resource("Events", "/events", "event_id", "an event", "events", completion, completions, filters...)
+
+    resource("Series", "/series", "series_id", "a series", "your series, with their progress",
+        reflect.TypeOf(models.Series{}), reflect.TypeOf(models.SeriesList{}), pages...)
+
+    ops["GET /tags"] = apiOperation{Tag: "Tags", Summary: "List the tags on the completions you can see, most used first", Response: reflect.TypeOf(models.TagCounts{})}
+    ops["GET /tags/{slug}"] = apiOperation{Tag: "Tags", Summary: "Show a tag with the completions you can see that carry it", Query: pages, Response: reflect.TypeOf(models.Tag{})}
+
+    group := reflect.TypeOf(models.Group{})
+    membership := reflect.TypeOf(models.Membership{})
+    invite := reflect.TypeOf(models.GroupInvite{})
+    ops["GET /groups"] = apiOperation{Tag: "Groups", Summary: "List the groups you are a member of, with your role in each", Response: reflect.TypeOf(models.Memberships{})}
+    ops["POST /groups"] = apiOperation{Tag: "Groups", Summary: "Create a group", Body: group, Response: group, Status: http.StatusCreated}
+    ops["GET /groups/{group_id}"] = apiOperation{Tag: "Groups", Summary: "Show a group with its members, its completions and their progress", Response: group}
+    ops["PUT /groups/{group_id}"] = apiOperation{Tag: "Groups", Summary: "Update a group", Body: group, Response: group}
+    ops["DELETE /groups/{group_id}"] = apiOperation{Tag: "Groups", Summary: "Delete a group, keeping its completions", Response: group}
+    ops["POST /groups/{group_id}/invites"] = apiOperation{Tag: "Groups", Summary: "Invite an email to join a group", Body: invite, Response: invite, Status: http.StatusCreated}
+    ops["DELETE /groups/{group_id}/invites/{invite_id}"] = apiOperation{Tag: "Groups", Summary: "Withdraw an invite", Response: invite}
+    ops["PUT /groups/{group_id}/members/{membership_id}"] = apiOperation{Tag: "Groups", Summary: "Change a member's role", Body: membership, Response: membership}
+    ops["DELETE /groups/{group_id}/members/{membership_id}"] = apiOperation{Tag: "Groups", Summary: "Remove a member, or leave a group", Response: membership}
+
+    ops["GET /invites"] = apiOperation{Tag: "Invites", Summary: "List the invites waiting for you", Response: reflect.TypeOf(models.GroupInvites{})}
+    ops["POST /invites/{invite_id}/accept"] = apiOperation{Tag: "Invites", Summary: "Accept an invite, joining its group", Response: membership, Status: http.StatusCreated}
+    ops["DELETE /invites/{invite_id}"] = apiOperation{Tag: "Invites", Summary: "Decline an invite", Response: invite}
+
+    ops["GET /search"] = apiOperation{Tag: "Search", Summary: "Search the completions you can see, best match first", Query: []string{"q"}, Response: reflect.TypeOf(models.SearchResults{})}
+
+    ops["GET /trash"] = apiOperation{Tag: "Trash", Summary: "List the completions in the trash, most recently deleted first", Query: pages, Response: completions}
+    ops["DELETE /trash"] = apiOperation{Tag: "Trash", Summary: "Empty the trash", Response: reflect.TypeOf(purgeResult{})}
+    ops["POST /trash/{completion_id}/restore"] = apiOperation{Tag: "Trash", Summary: "Restore a completion from the trash", Response: completion}
+    ops["DELETE /trash/{completion_id}"] = apiOperation{Tag: "Trash", Summary: "Delete a completion in the trash for good", Response: completion}
+    return ops
+}
+
+// apiParameters are the query params of the completion routes.
+func apiParameters() map[string]*openAPIParameter {
+    query := func(name, description string, schema *openAPISchema) *openAPIParameter {
+        return &openAPIParameter{Name: name, In: "query", Description: description, Schema: schema}
+    }
+    number := func(kind string, least, most int) *openAPISchema {
+        return &openAPISchema{Type: kind, Minimum: &least, Maximum: &most}
+    }
+    stars := number("number", 0, models.MaxRating)
+    stars.MultipleOf = 0.5
+    date := &openAPISchema{Type: "string", Format: "date"}
+    first := 1
+
+    columns := make([]string, 0, len(sortColumns))
+    for column := range sortColumns {
+        columns = append(columns, column)
+    }
+    sort.Strings(columns)
+
+    params := map[string]*openAPIParameter{
+        "type": query("type", "The slug of a completion type, such as book.", &openAPISchema{Type: "string"}),
+        "status": query("status", "Only completions with this status.", &openAPISchema{Type: "string", Enum: enumValues(models.GetStatuses())}),
+        "rating": query("rating", "Only completions rated exactly this.", stars),
+        "min_rating": query("min_rating", "Only completions rated at least this.", stars),
+        "max_rating": query("max_rating", "Only completions rated at most this.", stars),
+        "completed_from": query("completed_from", "Only completions completed on or after this day.", date),
+        "completed_to": query("completed_to", "Only completions completed on or before this day.", date),
+        "min_progress": query("min_progress", "Only completions at least this percent complete.", number("integer", 0, 100)),
+        "max_progress": query("max_progress", "Only completions at most this percent complete.", number("integer", 0, 100)),
+        "sort": query("sort", "The column to sort by, completed_at by default.", &openAPISchema{Type: "string", Enum: columns}),
+        "order": query("order", "The order to sort in, ascending for names and descending for the rest by default.", &openAPISchema{Type: "string", Enum: []string{"asc", "desc"}}),
+        "page": query("page", "The page to show, counting from 1.", &openAPISchema{Type: "integer", Minimum: &first}),
+        "per_page": query("per_page", "How many to show on a page.", number("integer", 1, maxPerPage)),
+        "cursor": query("cursor", "Where a page sorted by completion date starts, from a link in the Link header.", &openAPISchema{Type: "string"}),
+        "run": query("run", "The number of the run, the current one by default.", &openAPISchema{Type: "integer"}),
+        "q": query("q", "What to search for.", &openAPISchema{Type: "string"}),
+    }
+    params["q"].Required = true
+    return params
+}
+
+// viewFields are the fields a model's MarshalJSON adds to its own, as a
+// struct the schema can be read from.
+var viewFields = map[reflect.Type]reflect.Type{
+    reflect.TypeOf(models.Completion{}): reflect.TypeOf(struct{
+        PercentComplete int `json:"percent_complete"`
+        Remaining int `json:"remaining"`
+    }{}),
+    reflect.TypeOf(models.Group{}): reflect.TypeOf(struct{
+        Finished int `json:"finished"`
+        PercentComplete int `json:"percent_complete"`
+        Totals []models.GroupTotal `json:"totals"`
+        MemberTotals []models.MemberTotal `json:"member_totals"`
+    }{}),
+    reflect.TypeOf(models.Series{}): reflect.TypeOf(struct{
+        Finished int `json:"finished"`
+        Total int `json:"total"`
+        PercentComplete int `json:"percent_complete"`
+        Next *models.Completion `json:"next"`
+    }{}),
+}
+
+// enums are the values the string types with a fixed set of them take.
+var enums = map[reflect.Type][]string{
+    reflect.TypeOf(models.Status("")): enumValues(models.GetStatuses()),
+    reflect.TypeOf(models.Privacy("")): enumValues(models.GetPrivacyLevels()),
+    reflect.TypeOf(models.Role("")): enumValues(models.GetRoles()),
+    reflect.TypeOf(models.AttendanceStatus("")): enumValues(models.GetAttendanceStatuses()),
+}
+
+// enumValues turns a slice of string types into strings.
+func enumValues(values interface{}) []string {
+    v := reflect.ValueOf(values)
+    out := make([]string, v.Len())
+    for i := range out {
+        out[i] = v.Index(i).String()
+    }
+    return out
+}
+
+// openAPIDocument is an OpenAPI 3 document.
+type openAPIDocument struct{
+    OpenAPI string `json:"openapi"`
+    Info openAPIInfo `json:"info"`
+    Paths map[string]map[string]*openAPIOperation `json:"paths"`
+    Components openAPIComponents `json:"components"`
+}
+
+type openAPIInfo struct{
+    Title string `json:"title"`
+    Description string `json:"description"`
+    Version string `json:"version"`
+}
+
+type openAPIComponents struct{
+    Schemas map[string]*openAPISchema `json:"schemas"`
+    SecuritySchemes map[string]openAPISecurityScheme `json:"securitySchemes"`
+}
+
+type openAPISecurityScheme struct{
+    Type string `json:"type"`
+    Scheme string `json:"scheme,omitempty"`
+    In string `json:"in,omitempty"`
+    Name string `json:"name,omitempty"`
+    Description string `json:"description,omitempty"`
+}
+
+type openAPIOperation struct{
+    OperationID string `json:"operationId"`
+    Summary string `json:"summary"`
+    Tags []string `json:"tags"`
+    Parameters []*openAPIParameter `json:"parameters,omitempty"`
+    RequestBody *openAPIRequestBody `json:"requestBody,omitempty"`
+    Responses map[string]*openAPIResponse `json:"responses"`
+    Security []map[string][]string `json:"security"`
+}
+
+// Body describes the schema of the request body, if there is one.
+func (o *openAPIOperation) Body() string {
+    if o.RequestBody == nil {
+        return ""
+    }
+    return o.RequestBody.Content["application/json"].Schema.Describe()
+}
+
+// Returns describes the status and schema of a success.
+func (o *openAPIOperation) Returns() string {
+    for status, res := range o.Responses {
+        if strings.HasPrefix(status, "2") {
+            return status + " " + res.Content["application/json"].Schema.Describe()
+        }
+    }
+    return ""
+}
+
+type openAPIParameter struct{
+    Name string `json:"name"`
+    In string `json:"in"`
+    Description string `json:"description,omitempty"`
+    Required bool `json:"required,omitempty"`
+    Schema *openAPISchema `json:"schema"`
+}
+
+type openAPIRequestBody struct{
+    Required bool `json:"required"`
+    Content map[string]openAPIMedia `json:"content"`
+}
+
+type openAPIMedia struct{
+    Schema *openAPISchema `json:"schema"`
+}
+
+type openAPIResponse struct{
+    Description string `json:"description"`
+    Headers map[string]openAPIHeader `json:"headers,omitempty"`
+    Content map[string]openAPIMedia `json:"content,omitempty"`
+}
+
+type openAPIHeader struct{
+    Description string `json:"description"`
+    Schema *openAPISchema `json:"schema"`
+}
+
+type openAPISchema struct{
+    Ref string `json:"$ref,omitempty"`
+    Type string `json:"type,omitempty"`
+    Format string `json:"format,omitempty"`
+    Nullable bool `json:"nullable,omitempty"`
+    Enum []string `json:"enum,omitempty"`
+    Minimum *int `json:"minimum,omitempty"`
+    Maximum *int `json:"maximum,omitempty"`
+    MultipleOf float64 `json:"multipleOf,omitempty"`
+    Items *openAPISchema `json:"items,omitempty"`
+    Properties map[string]*openAPISchema `json:"properties,omitempty"`
+    AdditionalProperties *openAPISchema `json:"additionalProperties,omitempty"`
+}
+
+// Describe names the schema's type for the docs page, such as
+// "Completion[]" or "string (date-time), nullable".
+func (s *openAPISchema) Describe() string {
+    if s == nil {
+        return ""
+    }
+    if s.Ref != "" {
+        return strings.TrimPrefix(s.Ref, schemaRef)
+    }
+
+    desc := s.Type
+    switch {
+    case s.Items != nil:
+        desc = s.Items.Describe() + "[]"
+    case s.AdditionalProperties != nil:
+        desc = "map of " + s.AdditionalProperties.Describe()
+    case s.Format != "":
+        desc += " (" + s.Format + ")"
+    }
+    if len(s.Enum) > 0 {
+        desc += ": " + strings.Join(s.Enum, ", ")
+    }
+    if s.Nullable {
+        desc += ", nullable"
+    }
+    return desc
+}
+
+// openAPIField is a property of an object schema.
+type openAPIField struct{
+    Name string
+    Schema *openAPISchema
+}
+
+// Fields lists the properties of the schema by name.
+func (s *openAPISchema) Fields() []openAPIField {
+    fields := make([]openAPIField, 0, len(s.Properties))
+    for name, schema := range s.Properties {
+        fields = append(fields, openAPIField{Name: name, Schema: schema})
+    }
+    sort.Slice(fields, func(i, j int) bool {
+        return fields[i].Name < fields[j].Name
+    })
+    return fields
+}
+
+// schemaRef is where a schema in the components is referred to.
+const schemaRef = "#/components/schemas/"
+
+var (
+    timeType = reflect.TypeOf(time.Time{})
+    uuidType = reflect.TypeOf(uuid.UUID{})
+    nullsPkg = reflect.TypeOf(nulls.Time{}).PkgPath()
+)
+
+// openAPIBuilder builds the OpenAPI document for the completion routes.
+// Types is the Go type each schema in the components was read from.
+type openAPIBuilder struct{
+    doc *openAPIDocument
+    params map[string]*openAPIParameter
+    types map[string]reflect.Type
+}
+
+// buildOpenAPI describes the completion routes among routes, both those
+// under apiPrefix and those the web pages share.
+func buildOpenAPI(routes buffalo.RouteList) (*openAPIDocument, map[string]reflect.Type) {
+    b := &openAPIBuilder{
+        doc: &openAPIDocument{
+            OpenAPI: "3.0.3",
+            Info: openAPIInfo{
+                Title: "Completion Tracker",
+                Description: "The completion routes. Those under " + apiPrefix + " take an API token and always answer JSON, with errors as application/problem+json. The rest also take the session cookie and answer JSON or XML as asked.",
+                Version: strings.TrimPrefix(apiPrefix, "/api/"),
+            },
+            Paths: map[string]map[string]*openAPIOperation{},
+            Components: openAPIComponents{
+                Schemas: map[string]*openAPISchema{},
+                SecuritySchemes: map[string]openAPISecurityScheme{
+                    "token": {Type: "http", Scheme: "bearer", Description: "A personal access token from /tokens."},
+                    "session": {Type: "apiKey", In: "cookie", Name: "_completion_tracker_session", Description: "The cookie set by signing in."},
+                },
+            },
+        },
+        params: apiParameters(),
+        types: map[string]reflect.Type{},
+    }
+
+    // Operations are named after the route outside apiPrefix, since
+    // those inside it are named by the group. Series are named the same
+    // whether there is one or many, so where a name is taken twice the one
+    // that finds a single record says so.
+    names := map[string]string{}
+    taken := map[string]int{}
+    for _, route := range routes {
+        if path, versioned, ok := completionRoute(route); ok && !versioned {
+            name := strings.ToLower(route.Method) + upperFirst(strings.TrimSuffix(route.PathName, "Path"))
+            names[route.Method+" "+path] = name
+            taken[name]++
+        }
+    }
+    for key, name := range names {
+        if taken[name] > 1 && strings.HasSuffix(key, "}") {
+            names[key] = name + "ByID"
+        }
+    }
+
+    for _, route := range routes {
+        path, versioned, ok := completionRoute(route)
+        if !ok {
+            continue
+        }
+        key := route.Method + " " + path
+        if op, ok := apiOperations[key]; ok {
+            b.addOperation(route, versioned, names[key], op)
+        }
+    }
+    return b.doc, b.types
+}
+
+// completionRoute reports whether route is a completion route, and if so
+// its path outside apiPrefix and whether it is under it. The pages that
+// only hold an HTML form aren't counted.
+func completionRoute(route *buffalo.RouteInfo) (string, bool, bool) {
+    path := strings.TrimSuffix(route.Path, "/")
+    versioned := strings.HasPrefix(path, apiPrefix+"/")
+    path = strings.TrimPrefix(path, apiPrefix)
+
+    segments := strings.Split(strings.TrimPrefix(path, "/"), "/")
+    if last := segments[len(segments)-1]; last == "new" || last == "edit" {
+        return "", false, false
+    }
+    for _, root := range completionRoots {
+        if segments[0] == root {
+            return path, versioned, true
+        }
+    }
+    return "", false, false
+}
+
+func (b *openAPIBuilder) addOperation(route *buffalo.RouteInfo, versioned bool, name string, op apiOperation) {
+    security := []map[string][]string{{"session": {}}, {"token": {}}}
+    if versioned {
+        name = "v1." + name
+        security = []map[string][]string{{"token": {}}}
+    }
+
+    o := &openAPIOperation{
+        OperationID: name,
+        Summary: op.Summary,
+        Tags: []string{op.Tag},
+        Responses: map[string]*openAPIResponse{},
+        Security: security,
+    }
+
+    path := strings.TrimSuffix(route.Path, "/")
+    for _, segment := range strings.Split(path, "/") {
+        if strings.HasPrefix(segment, "{") {
+            // Records are found by their id, and tags by their slug
+            name := strings.Trim(segment, "{}")
+            schema := &openAPISchema{Type: "string"}
+            if strings.HasSuffix(name, "_id") {
+                schema.Format = "uuid"
+            }
+            o.Parameters = append(o.Parameters, &openAPIParameter{
+                Name: name,
+                In: "path",
+                Required: true,
+                Schema: schema,
+            })
+        }
+    }
+    paged := false
+    for _, name := range op.Query {
+        o.Parameters = append(o.Parameters, b.params[name])
+        paged = paged || name == "per_page"
+    }
+
+    if op.Body != nil {
+        o.RequestBody = &openAPIRequestBody{Required: true, Content: b.content(op.Body, versioned)}
+    }
+
+    status := op.Status
+    if status == 0 {
+        status = http.StatusOK
+    }
+    success := &openAPIResponse{Description: http.StatusText(status), Content: b.content(op.Response, versioned)}
+    if paged {
+        success.Headers = map[string]openAPIHeader{
+            "Link": {Description: "RFC 8288 links to the first, previous, next and last pages, where there are any.", Schema: &openAPISchema{Type: "string"}},
+            "X-Total-Count": {Description: "How many there are on every page together.", Schema: &openAPISchema{Type: "integer"}},
+        }
+    }
+    o.Responses[strconv.Itoa(status)] = success
+
+    if versioned {
+        o.Responses["default"] = &openAPIResponse{
+            Description: "A problem with the request",
+            Content: map[string]openAPIMedia{"application/problem+json": {Schema: b.schemaFor(reflect.TypeOf(problem{}))}},
+        }
+    } else {
+        o.Responses["401"] = &openAPIResponse{Description: "Not signed in"}
+        if len(o.Parameters) > 0 && o.Parameters[0].In == "path" {
+            o.Responses["404"] = &openAPIResponse{Description: "Not found"}
+        }
+        if op.Body != nil {
+            o.Responses["422"] = &openAPIResponse{Description: "Not valid", Content: b.content(reflect.TypeOf(validate.Errors{}), false)}
+        }
+    }
+
+    if b.doc.Paths[path] == nil {
+        b.doc.Paths[path] = map[string]*openAPIOperation{}
+    }
+    b.doc.Paths[path][strings.ToLower(route.Method)] = o
+}
+
+// content is a body of type t in JSON, and in XML as well outside the
+// API.
+func (b *openAPIBuilder) content(t reflect.Type, versioned bool) map[string]openAPIMedia {
+    content := map[string]openAPIMedia{"application/json": {Schema: b.schemaFor(t)}}
+    if !versioned {
+        content["application/xml"] = openAPIMedia{Schema: b.schemaFor(t)}
+    }
+    return content
+}
+
+// schemaFor reads the schema of t from how encoding/json writes it. Named
+// structs are added to the components and referred to.
+func (b *openAPIBuilder) schemaFor(t reflect.Type) *openAPISchema {
+    switch t {
+    case timeType:
+        return &openAPISchema{Type: "string", Format: "date-time"}
+    case uuidType:
+        return &openAPISchema{Type: "string", Format: "uuid"}
+    }
+    if values, ok := enums[t]; ok {
+        return &openAPISchema{Type: "string", Enum: values}
+    }
+    if t.PkgPath() == nullsPkg && t.Kind() == reflect.Struct {
+        // A nulls type is its first field, or null
+        s := b.schemaFor(t.Field(0).Type)
+        s.Nullable = true
+        return s
+    }
+
+    switch t.Kind() {
+    case reflect.Ptr:
+        return b.schemaFor(t.Elem())
+    case reflect.Slice, reflect.Array:
+        return &openAPISchema{Type: "array", Items: b.schemaFor(t.Elem())}
+    case reflect.Map:
+        return &openAPISchema{Type: "object", AdditionalProperties: b.schemaFor(t.Elem())}
+    case reflect.Struct:
+        if t.Name() == "" {
+            return b.objectSchema(t)
+        }
+        return b.component(t)
+    case reflect.String:
+        return &openAPISchema{Type: "string"}
+    case reflect.Bool:
+        return &openAPISchema{Type: "boolean"}
+    case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+        reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+        return &openAPISchema{Type: "integer"}
+    case reflect.Float32, reflect.Float64:
+        return &openAPISchema{Type: "number"}
+    }
+    return &openAPISchema{}
+}
+
+// component adds the schema of the struct t to the components, under its
+// name, the first time it is met, and refers to it.
+func (b *openAPIBuilder) component(t reflect.Type) *openAPISchema {
+    name := upperFirst(t.Name())
+    if _, ok := b.doc.Components.Schemas[name]; !ok {
+        // The schema is added before it is read so that types which
+        // refer back to it, like a Series' Completions, find it
+        s := &openAPISchema{}
+        b.doc.Components.Schemas[name] = s
+        b.types[name] = t
+        *s = *b.objectSchema(t)
+    }
+    return &openAPISchema{Ref: schemaRef + name}
+}
+
+// objectSchema is the schema of the struct t with the fields encoding/json
+// writes, and those its MarshalJSON adds.
+func (b *openAPIBuilder) objectSchema(t reflect.Type) *openAPISchema {
+    s := &openAPISchema{Type: "object", Properties: map[string]*openAPISchema{}}
+    b.addFields(s, t)
+    if view, ok := viewFields[t]; ok {
+        b.addFields(s, view)
+    }
+    return s
+}
+
+func (b *openAPIBuilder) addFields(s *openAPISchema, t reflect.Type) {
+    for i := 0; i < t.NumField(); i++ {
+        f := t.Field(i)
+        tag := f.Tag.Get("json")
+        if f.Anonymous && tag == "" {
+            b.addFields(s, f.Type)
+            continue
+        }
+        if !f.IsExported() || tag == "-" {
+            continue
+        }
+        name, _, _ := strings.Cut(tag, ",")
+        if name == "" {
+            name = f.Name
+        }
+        s.Properties[name] = b.schemaFor(f.Type)
+    }
+}
+
+// upperFirst capitalizes the first letter of an identifier.
+func upperFirst(name string) string {
+    return strings.ToUpper(name[:1]) + name[1:]
+}
+
+// apiDocsSection is the operations on the docs page with one tag.
+type apiDocsSection struct{
+    Tag string
+    Operations []apiDocsOperation
+}
+
+// apiDocsOperation is an operation on the docs page. Color is the
+// Bootstrap color of its method.
+type apiDocsOperation struct{
+    Method string
+    Path string
+    Color string
+    Operation *openAPIOperation
+}
+
+var methodColors = map[string]string{
+    http.MethodGet: "primary",
+    http.MethodPost: "success",
+    http.MethodPut: "warning",
+    http.MethodDelete: "danger",
+}
+
+// OpenAPIHandler serves the OpenAPI document describing the completion
+// routes. This function is mapped to the path GET /api/openapi.json
+func OpenAPIHandler(c buffalo.Context) error {
+    doc, _ := buildOpenAPI(App().Routes())
+    return c.Render(http.StatusOK, r.JSON(doc))
+}
+
+// APIDocsHandler shows the OpenAPI document as a page, with the
+// operations in the order their routes are declared. This function is
+// mapped to the path GET /api/docs
+func APIDocsHandler(c buffalo.Context) error {
+    doc, _ := buildOpenAPI(App().Routes())
+
+    sections := []apiDocsSection{}
+    index := map[string]int{}
+    for _, route := range App().Routes() {
+        path := strings.TrimSuffix(route.Path, "/")
+        o, ok := doc.Paths[path][strings.ToLower(route.Method)]
+        if !ok {
+            continue
+        }
+        tag := o.Tags[0]
+        if _, ok := index[tag]; !ok {
+            index[tag] = len(sections)
+            sections = append(sections, apiDocsSection{Tag: tag})
+        }
+        section := &sections[index[tag]]
+        section.Operations = append(section.Operations, apiDocsOperation{Method: route.Method, Path: path, Color: methodColors[route.Method], Operation: o})
+    }
+
+    components := &openAPISchema{Properties: doc.Components.Schemas}
+
+    c.Set("sections", sections)
+    c.Set("schemas", components.Fields())
+    return c.Render(http.StatusOK, r.HTML("api_docs/index.plush.html"))
+}
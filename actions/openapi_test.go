@@ -0,0 +1,87 @@
+package actions
+
+import (
+  "encoding/json"
+  "net/http"
+  "reflect"
+  "strings"
+)
+
+func (as *ActionSuite) Test_OpenAPI_CoversRoutes() {
+  doc, _ := buildOpenAPI(App().Routes())
+
+  documented := map[string]bool{}
+  for _, route := range App().Routes() {
+    path, versioned, ok := completionRoute(route)
+    if !ok {
+      // Every API route is described, except the one answering the rest
+      api := strings.HasPrefix(route.Path, apiPrefix+"/") && !strings.Contains(route.Path, "{path:")
+      as.False(api, "%s %s is under %s but not in completionRoots", route.Method, route.Path, apiPrefix)
+      continue
+    }
+    _, ok = doc.Paths[strings.TrimSuffix(route.Path, "/")][strings.ToLower(route.Method)]
+    as.True(ok, "%s %s has no entry in the OpenAPI document; add it to apiOperations", route.Method, route.Path)
+    if !versioned {
+      documented[route.Method+" "+path] = true
+    }
+  }
+
+  // Nor may an entry outlive its route
+  for key := range apiOperations {
+    as.True(documented[key], "%s is in apiOperations but has no route", key)
+  }
+
+  // And no two operations share a name
+  named := map[string]string{}
+  for path, ops := range doc.Paths {
+    for method, op := range ops {
+      as.NotContains(named, op.OperationID, "%s %s and %s are both named %s", method, path, named[op.OperationID], op.OperationID)
+      named[op.OperationID] = method + " " + path
+    }
+  }
+}
+
+func (as *ActionSuite) Test_OpenAPI_SchemasMatchJSON() {
+  doc, types := buildOpenAPI(App().Routes())
+
+  for name, t := range types {
+    b, err := json.Marshal(reflect.New(t).Elem().Interface())
+    as.NoError(err)
+    fields := map[string]json.RawMessage{}
+    as.NoError(json.Unmarshal(b, &fields))
+    for field := range fields {
+      as.Contains(doc.Components.Schemas[name].Properties, field, "the %s schema is missing %q", name, field)
+    }
+  }
+}
+
+func (as *ActionSuite) Test_OpenAPIHandler() {
+  as.Session.Clear()
+
+  res := as.JSON("/api/openapi.json").Get()
+  as.Equal(http.StatusOK, res.Code)
+
+  doc := openAPIDocument{}
+  res.Bind(&doc)
+  as.Equal("3.0.3", doc.OpenAPI)
+  as.Contains(doc.Paths, "/books")
+  as.Contains(doc.Paths, apiPrefix+"/books/{book_id}")
+  as.Contains(doc.Paths["/completions"]["get"].Responses["200"].Headers, "X-Total-Count")
+  as.Contains(doc.Paths[apiPrefix+"/books"]["post"].Responses["default"].Content, "application/problem+json")
+  as.Contains(doc.Paths, apiPrefix+"/series/{series_id}")
+  as.Contains(doc.Paths, apiPrefix+"/groups/{group_id}/members/{membership_id}")
+  as.Equal("", doc.Paths["/tags/{slug}"]["get"].Parameters[0].Schema.Format)
+  as.Contains(doc.Components.Schemas["Completion"].Properties, "percent_complete")
+  as.Equal([]string{"planned", "in-progress", "paused", "completed", "abandoned"}, doc.Components.Schemas["Completion"].Properties["status"].Enum)
+}
+
+func (as *ActionSuite) Test_APIDocsHandler() {
+  as.Session.Clear()
+
+  res := as.HTML("/api/docs").Get()
+  as.Equal(http.StatusOK, res.Code)
+  body := res.Body.String()
+  as.Contains(body, "List books")
+  as.Contains(body, apiPrefix+"/tv_shows/{tv_show_id}/seasons")
+  as.Contains(body, `id="schema-Completion"`)
+}
@@ -0,0 +1,197 @@
+package actions
+
+import (
+    "encoding/base64"
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "strconv"
+    "strings"
+    "time"
+
+    "completion_tracker/models"
+
+    "github.com/gobuffalo/buffalo"
+    "github.com/gobuffalo/pop/v6"
+    "github.com/gofrs/uuid"
+)
+
+// maxPerPage is the most completions a page fetched by cursor can hold.
+const maxPerPage = 100
+
+// cursor marks a place in a list of completions sorted by completion date:
+// the completed_at and id of the completion a page starts after, or ends
+// before when Before is set. Clients get it as an opaque string in the
+// Link header and send it back in the "cursor" param.
+type cursor struct{
+    CompletedAt time.Time `json:"t"`
+    ID uuid.UUID `json:"id"`
+    Before bool `json:"b,omitempty"`
+}
+
+// String encodes the cursor for a URL.
+func (cur cursor) String() string {
+    b, _ := json.Marshal(cur)
+    return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// parseCursor decodes a cursor made by cursor.String.
+func parseCursor(value string) (*cursor, error) {
+    b, err := base64.RawURLEncoding.DecodeString(value)
+    if err != nil {
+        return nil, err
+    }
+
+    cur := &cursor{}
+    if err := json.Unmarshal(b, cur); err != nil {
+        return nil, err
+    }
+    if cur.ID == uuid.Nil || cur.CompletedAt.IsZero() {
+        return nil, fmt.Errorf("cursor is incomplete")
+    }
+    return cur, nil
+}
+
+// paginate loads the page of the completions q finds that the "page" and
+// "per_page" params ask for into completions, sorted by sortByParams. It
+// returns the Paginator for the page.
+func paginate(c buffalo.Context, q *pop.Query, completions *models.Completions) (*pop.Paginator, error) {
+    q, err := sortByParams(c, q.PaginateFromParams(c.Params()))
+    if err != nil {
+        return nil, err
+    }
+    if err := q.All(completions); err != nil {
+        return nil, err
+    }
+    return q.Paginator, nil
+}
+
+// paginateByCursor loads a page of the completions q finds into
+// completions for a JSON client. Unlike page numbers, a cursor never skips
+// or repeats a completion when others are added between requests, and
+// stays fast however far into the list it is, so lists sorted by
+// completion date, as they are by default, are paged by the "cursor"
+// param, ordered by completed_at and then id. Lists sorted by anything
+// else, or asked for by "page", are paged by number as before. Either way
+// the response gets a Link header to the neighbouring pages and an
+// X-Total-Count header with the number of completions in the list. A
+// "cursor" that wasn't made here, or a "per_page" that isn't from 1 to
+// maxPerPage, is a bad request.
+func paginateByCursor(c buffalo.Context, q *pop.Query, completions *models.Completions) error {
+    if column := c.Param("sort"); (column != "" && column != defaultSort) || c.Param("page") != "" {
+        paginator, err := paginate(c, q, completions)
+        if err != nil {
+            return err
+        }
+        setPageLinks(c, paginator)
+        return nil
+    }
+
+    order, err := sortOrder(c, defaultSort)
+    if err != nil {
+        return err
+    }
+
+    perPage := pop.PaginatorPerPageDefault
+    if value := c.Param("per_page"); value != "" {
+        perPage, err = strconv.Atoi(value)
+        if err != nil || perPage < 1 || perPage > maxPerPage {
+            return c.Error(http.StatusBadRequest, fmt.Errorf("per_page %q is not a number from 1 to %d", value, maxPerPage))
+        }
+    }
+
+    var cur *cursor
+    if value := c.Param("cursor"); value != "" {
+        if cur, err = parseCursor(value); err != nil {
+            return c.Error(http.StatusBadRequest, fmt.Errorf("cursor %q is not valid: %w", value, err))
+        }
+    }
+
+    total, err := q.Count(&models.Completion{})
+    if err != nil {
+        return err
+    }
+
+    // A page before the cursor is found by walking the list backwards
+    backward := cur != nil && cur.Before
+    direction := order
+    if backward {
+        direction = flipOrder(order)
+    }
+    if cur != nil {
+        op := "<"
+        if direction == "asc" {
+            op = ">"
+        }
+        q = q.Where(fmt.Sprintf("(completions.completed_at, completions.id) %s (?, ?)", op), cur.CompletedAt, cur.ID)
+    }
+
+    // One more than a page is loaded to tell whether there is another
+    q = q.Order(fmt.Sprintf("completions.completed_at %s, completions.id %s", direction, direction)).Limit(perPage + 1)
+    if err := q.All(completions); err != nil {
+        return err
+    }
+    more := len(*completions) > perPage
+    if more {
+        *completions = (*completions)[:perPage]
+    }
+    list := *completions
+    if backward {
+        for i, j := 0, len(list)-1; i < j; i, j = i+1, j-1 {
+            list[i], list[j] = list[j], list[i]
+        }
+    }
+
+    links := []string{pageLink(c, "first", nil)}
+    if len(list) > 0 {
+        first, last := list[0], list[len(list)-1]
+        if (backward && more) || (!backward && cur != nil) {
+            prev := cursor{CompletedAt: first.CompletedAt, ID: first.ID, Before: true}
+            links = append(links, pageLink(c, "prev", map[string]string{"cursor": prev.String()}))
+        }
+        if backward || more {
+            next := cursor{CompletedAt: last.CompletedAt, ID: last.ID}
+            links = append(links, pageLink(c, "next", map[string]string{"cursor": next.String()}))
+        }
+    }
+
+    c.Response().Header().Set("Link", strings.Join(links, ", "))
+    c.Response().Header().Set("X-Total-Count", strconv.Itoa(total))
+    return nil
+}
+
+// setPageLinks sets the Link and X-Total-Count headers for a list paged by
+// number.
+func setPageLinks(c buffalo.Context, p *pop.Paginator) {
+    page := func(n int) map[string]string {
+        return map[string]string{"page": strconv.Itoa(n)}
+    }
+
+    links := []string{pageLink(c, "first", page(1))}
+    if p.Page > 1 {
+        links = append(links, pageLink(c, "prev", page(p.Page-1)))
+    }
+    if p.Page < p.TotalPages {
+        links = append(links, pageLink(c, "next", page(p.Page+1)))
+    }
+    if p.TotalPages > 0 {
+        links = append(links, pageLink(c, "last", page(p.TotalPages)))
+    }
+
+    c.Response().Header().Set("Link", strings.Join(links, ", "))
+    c.Response().Header().Set("X-Total-Count", strconv.Itoa(p.TotalEntriesSize))
+}
+
+// pageLink is an RFC 8288 link with the relation rel to the current list
+// with the params in set, and without any other cursor or page.
+func pageLink(c buffalo.Context, rel string, set map[string]string) string {
+    u := *c.Request().URL
+    query := u.Query()
+    query.Del("cursor")
+    query.Del("page")
+    for name, value := range set {
+        query.Set(name, value)
+    }
+    u.RawQuery = query.Encode()
+    return fmt.Sprintf("<%s>; rel=%q", u.RequestURI(), rel)
+}
@@ -0,0 +1,79 @@
+package actions
+
+import (
+    "fmt"
+    "net/http"
+    "strconv"
+
+    "completion_tracker/models"
+
+    "github.com/gobuffalo/buffalo"
+    "github.com/gobuffalo/pop/v6"
+    "github.com/gobuffalo/x/responder"
+)
+
+// ProfilesResource is the public side of the app: each User's profile,
+// found by their username, and the completions they have shared. Nobody
+// needs to be signed in to see it, so it only ever loads public and
+// unlisted completions.
+type ProfilesResource struct{}
+
+// Show gets a User's public completions grouped by type, optionally only
+// those completed in the "year" param. This function is mapped to the
+// path GET /u/{username}
+func (v ProfilesResource) Show(c buffalo.Context) error {
+    // Get the DB connection from the context
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    year := 0
+    if value := c.Param("year"); value != "" {
+        var err error
+        if year, err = strconv.Atoi(value); err != nil {
+            return c.Error(http.StatusBadRequest, fmt.Errorf("year %q is not a number", value))
+        }
+    }
+
+    profile, err := models.LoadProfile(tx, c.Param("username"), year)
+    if err != nil {
+        return c.Error(http.StatusNotFound, err)
+    }
+
+    return responder.Wants("html", func(c buffalo.Context) error {
+        c.Set("profile", profile)
+        return c.Render(http.StatusOK, r.HTML("profiles/show.plush.html"))
+    }).Wants("json", func(c buffalo.Context) error {
+        return c.Render(200, r.JSON(profile))
+    }).Wants("xml", func(c buffalo.Context) error {
+        return c.Render(200, r.XML(profile))
+    }).Respond(c)
+}
+
+// ShowCompletion gets one Completion a User has shared, whether it is
+// public or unlisted. This function is mapped to the path
+// GET /u/{username}/{completion_id}
+func (v ProfilesResource) ShowCompletion(c buffalo.Context) error {
+    // Get the DB connection from the context
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    completion, err := models.FindSharedCompletion(tx, c.Param("username"), c.Param("completion_id"))
+    if err != nil {
+        return c.Error(http.StatusNotFound, err)
+    }
+    shared := completion.Public()
+
+    return responder.Wants("html", func(c buffalo.Context) error {
+        c.Set("username", c.Param("username"))
+        c.Set("completion", completion)
+        return c.Render(http.StatusOK, r.HTML("profiles/completion.plush.html"))
+    }).Wants("json", func(c buffalo.Context) error {
+        return c.Render(200, r.JSON(shared))
+    }).Wants("xml", func(c buffalo.Context) error {
+        return c.Render(200, r.XML(shared))
+    }).Respond(c)
+}
@@ -0,0 +1,104 @@
+package actions
+
+import (
+  "net/http"
+  "time"
+
+  "completion_tracker/models"
+
+  "github.com/gobuffalo/nulls"
+)
+
+// share records a completion of the signed in user's with privacy, and
+// gives them the username "reader".
+func (as *ActionSuite) share(name string, privacy models.Privacy) *models.Completion {
+  as.user.Username = "reader"
+  as.NoError(as.DB.Update(as.user))
+
+  c := &models.Completion{
+    Name:        name,
+    Type:        models.CompletionTypeBook,
+    CompletedAt: time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC),
+    Privacy:     privacy,
+    UserID:      nulls.NewUUID(as.user.ID),
+  }
+  verrs, err := as.DB.ValidateAndCreate(c)
+  as.NoError(err)
+  as.False(verrs.HasAny())
+  return c
+}
+
+func (as *ActionSuite) Test_ProfilesResource_Show() {
+  as.share("Dune", models.PrivacyPublic)
+  as.share("Draft", models.PrivacyUnlisted)
+  as.share("Diary", models.PrivacyPrivate)
+
+  // Anyone can see the profile, but only the public completions on it
+  as.Session.Clear()
+  res := as.HTML("/u/reader").Get()
+  as.Equal(http.StatusOK, res.Code)
+  body := res.Body.String()
+  as.Contains(body, "Dune")
+  as.NotContains(body, "Draft")
+  as.NotContains(body, "Diary")
+
+  jres := as.JSON("/u/reader").Get()
+  as.Equal(http.StatusOK, jres.Code)
+  as.Contains(jres.Body.String(), "Dune")
+  as.NotContains(jres.Body.String(), "Diary")
+  as.NotContains(jres.Body.String(), "user_id")
+
+  as.Equal(http.StatusOK, as.HTML("/u/reader?year=2025").Get().Code)
+  as.NotContains(as.HTML("/u/reader?year=2024").Get().Body.String(), "Dune")
+  as.Equal(http.StatusBadRequest, as.HTML("/u/reader?year=last").Get().Code)
+
+  as.Equal(http.StatusNotFound, as.HTML("/u/nobody").Get().Code)
+}
+
+func (as *ActionSuite) Test_ProfilesResource_ShowCompletion() {
+  public := as.share("Dune", models.PrivacyPublic)
+  unlisted := as.share("Draft", models.PrivacyUnlisted)
+  private := as.share("Diary", models.PrivacyPrivate)
+  trashed := as.share("Gone", models.PrivacyPublic)
+  as.NoError(trashed.Trash(as.DB))
+
+  as.Session.Clear()
+  as.Equal(http.StatusOK, as.HTML("/u/reader/%s", public.ID).Get().Code)
+
+  // Unlisted completions can be seen by anyone with the link
+  res := as.HTML("/u/reader/%s", unlisted.ID).Get()
+  as.Equal(http.StatusOK, res.Code)
+  as.Contains(res.Body.String(), "Draft")
+
+  jres := as.JSON("/u/reader/%s", unlisted.ID).Get()
+  as.Equal(http.StatusOK, jres.Code)
+  as.Contains(jres.Body.String(), "Draft")
+  as.NotContains(jres.Body.String(), "user_id")
+  as.NotContains(jres.Body.String(), "deleted_at")
+
+  as.Equal(http.StatusNotFound, as.HTML("/u/reader/%s", private.ID).Get().Code)
+  as.Equal(http.StatusNotFound, as.JSON("/u/reader/%s", private.ID).Get().Code)
+  as.Equal(http.StatusNotFound, as.HTML("/u/reader/%s", trashed.ID).Get().Code)
+  as.Equal(http.StatusNotFound, as.HTML("/u/someone/%s", public.ID).Get().Code)
+}
+
+func (as *ActionSuite) Test_AccountUpdate() {
+  as.Equal(http.StatusOK, as.HTML("/account").Get().Code)
+
+  res := as.HTML("/account").Put(map[string]interface{}{"Username": "Reader"})
+  as.Equal(http.StatusSeeOther, res.Code)
+  as.Equal("/account", res.Location())
+
+  u := &models.User{}
+  as.NoError(as.DB.Find(u, as.user.ID))
+  as.Equal("reader", u.Username)
+
+  // Usernames are taken first come, first served
+  other := as.createUser("other@example.com")
+  as.signIn(other)
+  res = as.HTML("/account").Put(map[string]interface{}{"Username": "reader"})
+  as.Equal(http.StatusUnprocessableEntity, res.Code)
+
+  res = as.HTML("/account").Put(map[string]interface{}{"Username": "no spaces"})
+  as.Equal(http.StatusUnprocessableEntity, res.Code)
+}
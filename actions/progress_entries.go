@@ -0,0 +1,339 @@
+package actions
+
+import (
+    "fmt"
+    "net/http"
+
+    "completion_tracker/models"
+
+    "github.com/gobuffalo/buffalo"
+    "github.com/gobuffalo/pop/v6"
+    "github.com/gobuffalo/x/responder"
+    "github.com/gofrs/uuid"
+)
+
+// ProgressEntriesResource is nested under a Completion and logs the
+// progress that makes up its Completions count. It is mounted at
+// /completions/{completion_id}/entries.
+type ProgressEntriesResource struct{
+    buffalo.Resource
+}
+
+// findParentCompletion loads the Completion named by completion_id. It has
+// to be one the signed in User can see to read it, and one they can
+// update to change it.
+func findParentCompletion(c buffalo.Context, tx *pop.Connection) (*models.Completion, error) {
+    scope := editableCompletions(c)
+    if c.Request().Method == http.MethodGet {
+        scope = visibleCompletions(c)
+    }
+
+    completion := &models.Completion{}
+    if err := tx.Scope(scope).Find(completion, c.Param("completion_id")); err != nil {
+        return nil, c.Error(http.StatusNotFound, err)
+    }
+    return completion, nil
+}
+
+// findProgressEntry loads the ProgressEntry named by progress_entry_id,
+// provided it belongs to completion.
+func findProgressEntry(c buffalo.Context, tx *pop.Connection, completion *models.Completion) (*models.ProgressEntry, error) {
+    entry := &models.ProgressEntry{}
+    if err := tx.Where("completion_id = ?", completion.ID).Find(entry, c.Param("progress_entry_id")); err != nil {
+        return nil, c.Error(http.StatusNotFound, err)
+    }
+    return entry, nil
+}
+
+// findLoggedRun loads the Run whose entries are listed: the one numbered
+// by the "run" param, or else the Completion's current Run.
+func findLoggedRun(c buffalo.Context, tx *pop.Connection, completion *models.Completion) (*models.Run, error) {
+    number := c.Param("run")
+    if number == "" {
+        return completion.CurrentRun(tx)
+    }
+
+    run := &models.Run{}
+    if err := tx.Where("completion_id = ? AND number = ?", completion.ID, number).First(run); err != nil {
+        return nil, c.Error(http.StatusNotFound, err)
+    }
+    return run, nil
+}
+
+// List gets the ProgressEntries of one Run of a Completion, newest first.
+// The "run" param picks the Run by number and defaults to the current one.
+// The html page also carries the form for logging a new entry. This
+// function is mapped to the path GET /completions/{completion_id}/entries
+func (v ProgressEntriesResource) List(c buffalo.Context) error {
+    // Get the DB connection from the context
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    completion, err := findParentCompletion(c, tx)
+    if err != nil {
+        return err
+    }
+
+    run, err := findLoggedRun(c, tx, completion)
+    if err != nil {
+        return err
+    }
+
+    entries := &models.ProgressEntries{}
+
+    // Paginate results. Params "page" and "per_page" control pagination.
+    // Default values are "page=1" and "per_page=20".
+    q := tx.PaginateFromParams(c.Params()).Where("run_id = ?", run.ID).Order("logged_at desc")
+
+    // Retrieve all ProgressEntries from the DB
+    if err := q.All(entries); err != nil {
+        return err
+    }
+
+    return responder.Wants("html", func(c buffalo.Context) error {
+        // Add the paginator to the context so it can be used in the template.
+        c.Set("pagination", q.Paginator)
+
+        c.Set("completion", completion)
+        c.Set("run", run)
+        c.Set("progressEntries", entries)
+        c.Set("progressEntry", &models.ProgressEntry{})
+        return c.Render(http.StatusOK, r.HTML("progress_entries/index.plush.html"))
+    }).Wants("json", func(c buffalo.Context) error {
+        return c.Render(200, r.JSON(entries))
+    }).Wants("xml", func(c buffalo.Context) error {
+        return c.Render(200, r.XML(entries))
+    }).Respond(c)
+}
+
+// Show gets the data for one ProgressEntry. This function is mapped to
+// the path GET /completions/{completion_id}/entries/{progress_entry_id}
+func (v ProgressEntriesResource) Show(c buffalo.Context) error {
+    // Get the DB connection from the context
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    completion, err := findParentCompletion(c, tx)
+    if err != nil {
+        return err
+    }
+
+    entry, err := findProgressEntry(c, tx, completion)
+    if err != nil {
+        return err
+    }
+
+    return responder.Wants("html", func(c buffalo.Context) error {
+        c.Set("completion", completion)
+        c.Set("progressEntry", entry)
+
+        return c.Render(http.StatusOK, r.HTML("progress_entries/show.plush.html"))
+    }).Wants("json", func(c buffalo.Context) error {
+        return c.Render(200, r.JSON(entry))
+    }).Wants("xml", func(c buffalo.Context) error {
+        return c.Render(200, r.XML(entry))
+    }).Respond(c)
+}
+
+// Create logs a ProgressEntry against the current Run of a Completion.
+// This function is mapped to the path POST /completions/{completion_id}/entries
+func (v ProgressEntriesResource) Create(c buffalo.Context) error {
+    // Get the DB connection from the context
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    completion, err := findParentCompletion(c, tx)
+    if err != nil {
+        return err
+    }
+
+    // Allocate an empty ProgressEntry
+    entry := &models.ProgressEntry{}
+
+    // Bind entry to the html form elements
+    if err := c.Bind(entry); err != nil {
+        return err
+    }
+    entry.CompletionID = completion.ID
+    entry.RunID = uuid.Nil
+
+    // Validate the data from the html form
+    verrs, err := tx.ValidateAndCreate(entry)
+    if err != nil {
+        return err
+    }
+
+    if verrs.HasAny() {
+        return responder.Wants("html", func(c buffalo.Context) error {
+            run, err := completion.CurrentRun(tx)
+            if err != nil {
+                return err
+            }
+            entries := &models.ProgressEntries{}
+            q := tx.PaginateFromParams(c.Params()).Where("run_id = ?", run.ID).Order("logged_at desc")
+            if err := q.All(entries); err != nil {
+                return err
+            }
+
+            // Make the errors available inside the html template
+            c.Set("errors", verrs)
+
+            // Render the log again so the user can correct the input.
+            c.Set("pagination", q.Paginator)
+            c.Set("completion", completion)
+            c.Set("run", run)
+            c.Set("progressEntries", entries)
+            c.Set("progressEntry", entry)
+
+            return c.Render(http.StatusUnprocessableEntity, r.HTML("progress_entries/index.plush.html"))
+        }).Wants("json", func(c buffalo.Context) error {
+            return renderValidationErrors(c, verrs)
+        }).Wants("xml", func(c buffalo.Context) error {
+            return c.Render(http.StatusUnprocessableEntity, r.XML(verrs))
+        }).Respond(c)
+    }
+
+    return responder.Wants("html", func(c buffalo.Context) error {
+        // If there are no errors set a success message
+        c.Flash().Add("success", T.Translate(c, "progress_entry.created.success"))
+
+        // and redirect back to the log
+        return c.Redirect(http.StatusSeeOther, "/completions/%v/entries", completion.ID)
+    }).Wants("json", func(c buffalo.Context) error {
+        return c.Render(http.StatusCreated, r.JSON(entry))
+    }).Wants("xml", func(c buffalo.Context) error {
+        return c.Render(http.StatusCreated, r.XML(entry))
+    }).Respond(c)
+}
+
+// Edit renders a edit form for a ProgressEntry. This function is mapped
+// to the path GET /completions/{completion_id}/entries/{progress_entry_id}/edit
+func (v ProgressEntriesResource) Edit(c buffalo.Context) error {
+    // Get the DB connection from the context
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    completion, err := findParentCompletion(c, tx)
+    if err != nil {
+        return err
+    }
+
+    entry, err := findProgressEntry(c, tx, completion)
+    if err != nil {
+        return err
+    }
+
+    c.Set("completion", completion)
+    c.Set("progressEntry", entry)
+    return c.Render(http.StatusOK, r.HTML("progress_entries/edit.plush.html"))
+}
+
+// Update changes a ProgressEntry in the DB. This function is mapped to
+// the path PUT /completions/{completion_id}/entries/{progress_entry_id}
+func (v ProgressEntriesResource) Update(c buffalo.Context) error {
+    // Get the DB connection from the context
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    completion, err := findParentCompletion(c, tx)
+    if err != nil {
+        return err
+    }
+
+    entry, err := findProgressEntry(c, tx, completion)
+    if err != nil {
+        return err
+    }
+
+    // Bind ProgressEntry to the html form elements, keeping which entry it
+    // is and its Run
+    persisted := *entry
+    if err := c.Bind(entry); err != nil {
+        return err
+    }
+    entry.ID = persisted.ID
+    entry.CreatedAt = persisted.CreatedAt
+    entry.CompletionID = completion.ID
+    entry.RunID = persisted.RunID
+
+    verrs, err := tx.ValidateAndUpdate(entry)
+    if err != nil {
+        return err
+    }
+
+    if verrs.HasAny() {
+        return responder.Wants("html", func(c buffalo.Context) error {
+            // Make the errors available inside the html template
+            c.Set("errors", verrs)
+
+            // Render again the edit.html template that the user can
+            // correct the input.
+            c.Set("completion", completion)
+            c.Set("progressEntry", entry)
+
+            return c.Render(http.StatusUnprocessableEntity, r.HTML("progress_entries/edit.plush.html"))
+        }).Wants("json", func(c buffalo.Context) error {
+            return renderValidationErrors(c, verrs)
+        }).Wants("xml", func(c buffalo.Context) error {
+            return c.Render(http.StatusUnprocessableEntity, r.XML(verrs))
+        }).Respond(c)
+    }
+
+    return responder.Wants("html", func(c buffalo.Context) error {
+        // If there are no errors set a success message
+        c.Flash().Add("success", T.Translate(c, "progress_entry.updated.success"))
+
+        // and redirect back to the log
+        return c.Redirect(http.StatusSeeOther, "/completions/%v/entries", completion.ID)
+    }).Wants("json", func(c buffalo.Context) error {
+        return c.Render(http.StatusOK, r.JSON(entry))
+    }).Wants("xml", func(c buffalo.Context) error {
+        return c.Render(http.StatusOK, r.XML(entry))
+    }).Respond(c)
+}
+
+// Destroy deletes a ProgressEntry from the DB. This function is mapped
+// to the path DELETE /completions/{completion_id}/entries/{progress_entry_id}
+func (v ProgressEntriesResource) Destroy(c buffalo.Context) error {
+    // Get the DB connection from the context
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    completion, err := findParentCompletion(c, tx)
+    if err != nil {
+        return err
+    }
+
+    entry, err := findProgressEntry(c, tx, completion)
+    if err != nil {
+        return err
+    }
+
+    if err := tx.Destroy(entry); err != nil {
+        return err
+    }
+
+    return responder.Wants("html", func(c buffalo.Context) error {
+        // If there are no errors set a flash message
+        c.Flash().Add("success", T.Translate(c, "progress_entry.destroyed.success"))
+
+        // Redirect back to the log
+        return c.Redirect(http.StatusSeeOther, "/completions/%v/entries", completion.ID)
+    }).Wants("json", func(c buffalo.Context) error {
+        return c.Render(http.StatusOK, r.JSON(entry))
+    }).Wants("xml", func(c buffalo.Context) error {
+        return c.Render(http.StatusOK, r.XML(entry))
+    }).Respond(c)
+}
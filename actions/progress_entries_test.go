@@ -0,0 +1,84 @@
+package actions
+
+import (
+  "net/http"
+
+  "completion_tracker/models"
+)
+
+func (as *ActionSuite) Test_ProgressEntriesResource_List() {
+  book := as.createBook("Dune", 50, 412)
+
+  res := as.HTML("/completions/%s/entries", book.ID).Get()
+  as.Equal(http.StatusOK, res.Code)
+  as.Contains(res.Body.String(), "Progress Log: Dune")
+  as.Contains(res.Body.String(), "50 pages")
+
+  entries := models.ProgressEntries{}
+  jres := as.JSON("/completions/%s/entries", book.ID).Get()
+  as.Equal(http.StatusOK, jres.Code)
+  jres.Bind(&entries)
+  as.Len(entries, 1)
+  as.Equal(50, entries[0].Units)
+
+  res = as.HTML("/completions/%s/entries", "00000000-0000-0000-0000-000000000000").Get()
+  as.Equal(http.StatusNotFound, res.Code)
+}
+
+func (as *ActionSuite) Test_ProgressEntriesResource_Create() {
+  book := as.createBook("Dune", 50, 412)
+
+  jres := as.JSON("/completions/%s/entries", book.ID).Post(map[string]interface{}{
+    "units": 30,
+    "note":  "Train ride",
+  })
+  as.Equal(http.StatusCreated, jres.Code)
+
+  as.NoError(as.DB.Reload(book))
+  as.Equal(80, book.Completions)
+
+  jres = as.JSON("/completions/%s/entries", book.ID).Post(map[string]interface{}{
+    "units": 400,
+  })
+  as.Equal(http.StatusUnprocessableEntity, jres.Code)
+
+  res := as.HTML("/completions/%s/entries", book.ID).Post(map[string]interface{}{
+    "Units": 20,
+  })
+  as.Equal(http.StatusSeeOther, res.Code)
+  as.Equal("/completions/"+book.ID.String()+"/entries", res.Location())
+
+  as.NoError(as.DB.Reload(book))
+  as.Equal(100, book.Completions)
+}
+
+func (as *ActionSuite) Test_ProgressEntriesResource_Update() {
+  book := as.createBook("Dune", 50, 412)
+  entry := &models.ProgressEntry{}
+  as.NoError(as.DB.Where("completion_id = ?", book.ID).First(entry))
+
+  jres := as.JSON("/completions/%s/entries/%s", book.ID, entry.ID).Put(map[string]interface{}{
+    "units":     60,
+    "logged_at": entry.LoggedAt,
+  })
+  as.Equal(http.StatusOK, jres.Code)
+
+  as.NoError(as.DB.Reload(book))
+  as.Equal(60, book.Completions)
+
+  other := as.createBook("Emma", 10, 0)
+  res := as.HTML("/completions/%s/entries/%s", other.ID, entry.ID).Get()
+  as.Equal(http.StatusNotFound, res.Code)
+}
+
+func (as *ActionSuite) Test_ProgressEntriesResource_Destroy() {
+  book := as.createBook("Dune", 50, 412)
+  entry := &models.ProgressEntry{}
+  as.NoError(as.DB.Where("completion_id = ?", book.ID).First(entry))
+
+  res := as.HTML("/completions/%s/entries/%s", book.ID, entry.ID).Delete()
+  as.Equal(http.StatusSeeOther, res.Code)
+
+  as.NoError(as.DB.Reload(book))
+  as.Equal(0, book.Completions)
+}
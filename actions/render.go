@@ -1,6 +1,9 @@
 package actions
 
 import (
+	"strconv"
+
+	"completion_tracker/models"
 	"completion_tracker/public"
 	"completion_tracker/templates"
 
@@ -26,6 +29,44 @@ func init() {
 			// below and import "github.com/gobuffalo/helpers/forms"
 			// forms.FormKey:     forms.Form,
 			// forms.FormForKey:  forms.FormFor,
+
+			// duration formats a number of minutes as "h:mm".
+			"duration": func(minutes int) string {
+				return models.Duration(minutes).String()
+			},
+
+			// formatRating writes a rating without trailing zeros, such as "3.5".
+			"formatRating": func(rating float64) string {
+				return strconv.FormatFloat(rating, 'f', -1, 64)
+			},
+
+			// completionTypes lists every completion type, built-in first.
+			"completionTypes": models.GetCompletionTypes,
+
+			// ratings lists every rating a completion can be given.
+			"ratings": models.GetRatings,
+
+			// privacyLevels lists who a completion can be shared with.
+			"privacyLevels": models.GetPrivacyLevels,
+
+			// sortPath links the current list sorted by a column.
+			"sortPath": sortPath,
+
+			// singleSignOn names the OIDC provider users can sign in with,
+			// or is empty when single sign-on is off.
+			"singleSignOn": oidcProviderName,
+
+			// ownsCompletion reports whether the signed in User owns a
+			// Completion, and so can change who it is shared with.
+			"ownsCompletion": ownsCompletion,
+
+			// showPathFor links a Completion to its type-specific page.
+			"showPathFor": func(completion interface{}) string {
+				if c, ok := completion.(*models.Completion); ok {
+					return showPathFor(*c)
+				}
+				return showPathFor(completion.(models.Completion))
+			},
 		},
 	})
 }
@@ -0,0 +1,92 @@
+package actions
+
+import (
+    "fmt"
+    "net"
+    "net/http"
+
+    "completion_tracker/models"
+
+    "github.com/gobuffalo/buffalo"
+    "github.com/gobuffalo/pop/v6"
+    "github.com/gobuffalo/x/responder"
+)
+
+// setActor names who is making the request's changes on its transaction,
+// so that the revisions they record can say who made them.
+func setActor(next buffalo.Handler) buffalo.Handler {
+    return func(c buffalo.Context) error {
+        tx, ok := c.Value("tx").(*pop.Connection)
+        if !ok {
+            return fmt.Errorf("no transaction found")
+        }
+
+        c.Set("tx", tx.WithContext(models.WithActor(c, actorFor(c))))
+        return next(c)
+    }
+}
+
+// actorFor identifies who is making a request: the signed in User, or
+// otherwise the address it came from.
+func actorFor(c buffalo.Context) string {
+    if u := currentUser(c); u != nil {
+        return u.Email
+    }
+
+    addr := c.Request().RemoteAddr
+    if host, _, err := net.SplitHostPort(addr); err == nil {
+        return host
+    }
+    return addr
+}
+
+// recentRevisions is how many of a Completion's latest Revisions its page
+// shows, rather than its whole history.
+const recentRevisions = 10
+
+// loadRecentRevisions loads completion's latest Revisions onto it, newest
+// first.
+func loadRecentRevisions(tx *pop.Connection, completion *models.Completion) error {
+    return tx.Where("completion_id = ?", completion.ID).Order("created_at desc").Limit(recentRevisions).All(&completion.Revisions)
+}
+
+// RevisionsResource is the edit history of a Completion. Revisions are
+// recorded as the Completion is saved and can't be changed, so they are
+// only listed. It is mounted at /completions/{completion_id}/revisions.
+type RevisionsResource struct{}
+
+// List gets the Revisions of a Completion, newest first. This function is
+// mapped to the path GET /completions/{completion_id}/revisions
+func (v RevisionsResource) List(c buffalo.Context) error {
+    // Get the DB connection from the context
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    completion, err := findParentCompletion(c, tx)
+    if err != nil {
+        return err
+    }
+
+    // Paginate results. Params "page" and "per_page" control pagination.
+    // Default values are "page=1" and "per_page=20".
+    q := tx.PaginateFromParams(c.Params()).Where("completion_id = ?", completion.ID).Order("created_at desc")
+
+    // Retrieve the Revisions from the DB
+    if err := q.All(&completion.Revisions); err != nil {
+        return err
+    }
+
+    return responder.Wants("html", func(c buffalo.Context) error {
+        // Add the paginator to the context so it can be used in the template.
+        c.Set("pagination", q.Paginator)
+
+        c.Set("completion", completion)
+        return c.Render(http.StatusOK, r.HTML("revisions/index.plush.html"))
+    }).Wants("json", func(c buffalo.Context) error {
+        return c.Render(200, r.JSON(completion.Revisions))
+    }).Wants("xml", func(c buffalo.Context) error {
+        return c.Render(200, r.XML(completion.Revisions))
+    }).Respond(c)
+}
@@ -0,0 +1,58 @@
+package actions
+
+import (
+  "net/http"
+
+  "completion_tracker/models"
+)
+
+func (as *ActionSuite) Test_RevisionsResource_List() {
+  book := as.createBook("Dune", 100, 412)
+
+  res := as.HTML("/books/%s", book.ID).Put(map[string]interface{}{
+    "Name":        "Dune Messiah",
+    "Completions": 100,
+    "Target":      412,
+    "CompletedAt": book.CompletedAt.Format("2006-01-02T15:04"),
+  })
+  as.Equal(http.StatusSeeOther, res.Code)
+
+  revisions := models.Revisions{}
+  jres := as.JSON("/completions/%s/revisions", book.ID).Get()
+  as.Equal(http.StatusOK, jres.Code)
+  jres.Bind(&revisions)
+  as.Len(revisions, 2)
+  as.Equal(models.RevisionUpdated, revisions[0].Action)
+  as.NotEqual("system", revisions[0].Actor)
+  as.Contains(revisions[0].Changes, models.FieldChange{Field: "name", From: "Dune", To: "Dune Messiah"})
+  as.Equal(models.RevisionCreated, revisions[1].Action)
+
+  res = as.HTML("/books/%s", book.ID).Get()
+  as.Equal(http.StatusOK, res.Code)
+  as.Contains(res.Body.String(), "History")
+  as.Contains(res.Body.String(), "Dune Messiah")
+
+  res = as.HTML("/completions/%s/revisions", book.ID).Get()
+  as.Equal(http.StatusOK, res.Code)
+  as.Contains(res.Body.String(), "<del>Dune</del>")
+}
+
+func (as *ActionSuite) Test_RevisionsResource_ShowOnlyRecent() {
+  book := as.createBook("Dune", 0, 412)
+  for pages := 1; pages <= recentRevisions+2; pages++ {
+    book.Completions = pages
+    as.NoError(as.DB.Update(book))
+  }
+
+  // Its page has only the latest changes, and the full history is paged
+  completion := models.Completion{}
+  res := as.JSON("/books/%s", book.ID).Get()
+  as.Equal(http.StatusOK, res.Code)
+  res.Bind(&completion)
+  as.Len(completion.Revisions, recentRevisions)
+  as.Equal(models.RevisionUpdated, completion.Revisions[0].Action)
+
+  revisions := models.Revisions{}
+  as.JSON("/completions/%s/revisions?per_page=50", book.ID).Get().Bind(&revisions)
+  as.Len(revisions, recentRevisions+3)
+}
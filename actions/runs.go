@@ -0,0 +1,276 @@
+package actions
+
+import (
+    "fmt"
+    "net/http"
+
+    "completion_tracker/models"
+
+    "github.com/gobuffalo/buffalo"
+    "github.com/gobuffalo/pop/v6"
+    "github.com/gobuffalo/x/responder"
+)
+
+// RunsResource is nested under a Completion and tracks each rewatch,
+// reread or replay of it. It is mounted at /completions/{completion_id}/runs.
+type RunsResource struct{
+    buffalo.Resource
+}
+
+// findRun loads the Run named by run_id, provided it belongs to completion.
+func findRun(c buffalo.Context, tx *pop.Connection, completion *models.Completion) (*models.Run, error) {
+    run := &models.Run{}
+    if err := tx.Where("completion_id = ?", completion.ID).Find(run, c.Param("run_id")); err != nil {
+        return nil, c.Error(http.StatusNotFound, err)
+    }
+    return run, nil
+}
+
+// List gets all Runs of a Completion, oldest first. This function is
+// mapped to the path GET /completions/{completion_id}/runs
+func (v RunsResource) List(c buffalo.Context) error {
+    // Get the DB connection from the context
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    completion, err := findParentCompletion(c, tx)
+    if err != nil {
+        return err
+    }
+
+    if err := tx.Load(completion, "Runs"); err != nil {
+        return err
+    }
+
+    return responder.Wants("html", func(c buffalo.Context) error {
+        c.Set("completion", completion)
+        return c.Render(http.StatusOK, r.HTML("runs/index.plush.html"))
+    }).Wants("json", func(c buffalo.Context) error {
+        return c.Render(200, r.JSON(completion.Runs))
+    }).Wants("xml", func(c buffalo.Context) error {
+        return c.Render(200, r.XML(completion.Runs))
+    }).Respond(c)
+}
+
+// Show gets the data for one Run. This function is mapped to the path
+// GET /completions/{completion_id}/runs/{run_id}
+func (v RunsResource) Show(c buffalo.Context) error {
+    // Get the DB connection from the context
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    completion, err := findParentCompletion(c, tx)
+    if err != nil {
+        return err
+    }
+
+    run, err := findRun(c, tx, completion)
+    if err != nil {
+        return err
+    }
+
+    return responder.Wants("html", func(c buffalo.Context) error {
+        c.Set("completion", completion)
+        c.Set("run", run)
+
+        return c.Render(http.StatusOK, r.HTML("runs/show.plush.html"))
+    }).Wants("json", func(c buffalo.Context) error {
+        return c.Render(200, r.JSON(run))
+    }).Wants("xml", func(c buffalo.Context) error {
+        return c.Render(200, r.XML(run))
+    }).Respond(c)
+}
+
+// Create starts the next Run of a finished Completion. StartedAt is
+// optional and defaults to now. This function is mapped to the path
+// POST /completions/{completion_id}/runs
+func (v RunsResource) Create(c buffalo.Context) error {
+    // Get the DB connection from the context
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    completion, err := findParentCompletion(c, tx)
+    if err != nil {
+        return err
+    }
+
+    // Allocate an empty Run
+    run := &models.Run{}
+
+    // Bind run to the html form elements
+    if err := c.Bind(run); err != nil {
+        return err
+    }
+
+    verrs, err := completion.StartRun(tx, run.StartedAt)
+    if err != nil {
+        return err
+    }
+
+    if verrs.HasAny() {
+        return responder.Wants("html", func(c buffalo.Context) error {
+            // The run is started from a button, so report the problem
+            // on the page it came from.
+            c.Flash().Add("danger", verrs.Error())
+            return c.Redirect(http.StatusSeeOther, showPathFor(*completion))
+        }).Wants("json", func(c buffalo.Context) error {
+            return renderValidationErrors(c, verrs)
+        }).Wants("xml", func(c buffalo.Context) error {
+            return c.Render(http.StatusUnprocessableEntity, r.XML(verrs))
+        }).Respond(c)
+    }
+
+    run = &completion.Runs[len(completion.Runs)-1]
+
+    return responder.Wants("html", func(c buffalo.Context) error {
+        // If there are no errors set a success message
+        c.Flash().Add("success", T.Translate(c, "run.created.success"))
+
+        // and redirect to the completion's show page
+        return c.Redirect(http.StatusSeeOther, showPathFor(*completion))
+    }).Wants("json", func(c buffalo.Context) error {
+        return c.Render(http.StatusCreated, r.JSON(run))
+    }).Wants("xml", func(c buffalo.Context) error {
+        return c.Render(http.StatusCreated, r.XML(run))
+    }).Respond(c)
+}
+
+// Edit renders a edit form for a Run's dates. This function is mapped
+// to the path GET /completions/{completion_id}/runs/{run_id}/edit
+func (v RunsResource) Edit(c buffalo.Context) error {
+    // Get the DB connection from the context
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    completion, err := findParentCompletion(c, tx)
+    if err != nil {
+        return err
+    }
+
+    run, err := findRun(c, tx, completion)
+    if err != nil {
+        return err
+    }
+
+    c.Set("completion", completion)
+    c.Set("run", run)
+    return c.Render(http.StatusOK, r.HTML("runs/edit.plush.html"))
+}
+
+// Update changes a Run's dates. Its number and progress are kept. This
+// function is mapped to the path PUT /completions/{completion_id}/runs/{run_id}
+func (v RunsResource) Update(c buffalo.Context) error {
+    // Get the DB connection from the context
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    completion, err := findParentCompletion(c, tx)
+    if err != nil {
+        return err
+    }
+
+    run, err := findRun(c, tx, completion)
+    if err != nil {
+        return err
+    }
+
+    // Bind Run to the html form elements, keeping what isn't a date
+    persisted := *run
+    if err := c.Bind(run); err != nil {
+        return err
+    }
+    run.ID = persisted.ID
+    run.CreatedAt = persisted.CreatedAt
+    run.CompletionID = completion.ID
+    run.Number, run.Completions = persisted.Number, persisted.Completions
+
+    verrs, err := tx.ValidateAndUpdate(run)
+    if err != nil {
+        return err
+    }
+
+    if verrs.HasAny() {
+        return responder.Wants("html", func(c buffalo.Context) error {
+            // Make the errors available inside the html template
+            c.Set("errors", verrs)
+
+            // Render again the edit.html template that the user can
+            // correct the input.
+            c.Set("completion", completion)
+            c.Set("run", run)
+
+            return c.Render(http.StatusUnprocessableEntity, r.HTML("runs/edit.plush.html"))
+        }).Wants("json", func(c buffalo.Context) error {
+            return renderValidationErrors(c, verrs)
+        }).Wants("xml", func(c buffalo.Context) error {
+            return c.Render(http.StatusUnprocessableEntity, r.XML(verrs))
+        }).Respond(c)
+    }
+
+    return responder.Wants("html", func(c buffalo.Context) error {
+        // If there are no errors set a success message
+        c.Flash().Add("success", T.Translate(c, "run.updated.success"))
+
+        // and redirect to the completion's show page
+        return c.Redirect(http.StatusSeeOther, showPathFor(*completion))
+    }).Wants("json", func(c buffalo.Context) error {
+        return c.Render(http.StatusOK, r.JSON(run))
+    }).Wants("xml", func(c buffalo.Context) error {
+        return c.Render(http.StatusOK, r.XML(run))
+    }).Respond(c)
+}
+
+// Destroy deletes an earlier Run along with its progress. The current Run
+// can't be deleted. This function is mapped to the path
+// DELETE /completions/{completion_id}/runs/{run_id}
+func (v RunsResource) Destroy(c buffalo.Context) error {
+    // Get the DB connection from the context
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    completion, err := findParentCompletion(c, tx)
+    if err != nil {
+        return err
+    }
+
+    run, err := findRun(c, tx, completion)
+    if err != nil {
+        return err
+    }
+
+    current, err := completion.CurrentRun(tx)
+    if err != nil {
+        return err
+    }
+    if current.ID == run.ID {
+        return c.Error(http.StatusUnprocessableEntity, fmt.Errorf("run %d is the current run of %s", run.Number, completion.ID))
+    }
+
+    if err := tx.Destroy(run); err != nil {
+        return err
+    }
+
+    return responder.Wants("html", func(c buffalo.Context) error {
+        // If there are no errors set a flash message
+        c.Flash().Add("success", T.Translate(c, "run.destroyed.success"))
+
+        // Redirect to the completion's show page
+        return c.Redirect(http.StatusSeeOther, showPathFor(*completion))
+    }).Wants("json", func(c buffalo.Context) error {
+        return c.Render(http.StatusOK, r.JSON(run))
+    }).Wants("xml", func(c buffalo.Context) error {
+        return c.Render(http.StatusOK, r.XML(run))
+    }).Respond(c)
+}
@@ -0,0 +1,101 @@
+package actions
+
+import (
+  "net/http"
+  "time"
+
+  "completion_tracker/models"
+)
+
+func (as *ActionSuite) finishBook(book *models.Completion) {
+  book.Status = models.StatusCompleted
+  verrs, err := as.DB.ValidateAndUpdate(book)
+  as.NoError(err)
+  as.False(verrs.HasAny())
+}
+
+func (as *ActionSuite) Test_RunsResource_List() {
+  book := as.createBook("Dune", 412, 412)
+
+  runs := models.Runs{}
+  jres := as.JSON("/completions/%s/runs", book.ID).Get()
+  as.Equal(http.StatusOK, jres.Code)
+  jres.Bind(&runs)
+  as.Len(runs, 1)
+  as.Equal(412, runs[0].Completions)
+
+  res := as.HTML("/books/%s", book.ID).Get()
+  as.Equal(http.StatusOK, res.Code)
+  as.Contains(res.Body.String(), "#1")
+  as.NotContains(res.Body.String(), "Start Another Run")
+}
+
+func (as *ActionSuite) Test_RunsResource_Create() {
+  book := as.createBook("Dune", 412, 412)
+
+  jres := as.JSON("/completions/%s/runs", book.ID).Post(map[string]interface{}{})
+  as.Equal(http.StatusUnprocessableEntity, jres.Code)
+
+  as.finishBook(book)
+  res := as.HTML("/books/%s", book.ID).Get()
+  as.Contains(res.Body.String(), "Start Another Run")
+
+  res = as.HTML("/completions/%s/runs", book.ID).Post(map[string]interface{}{})
+  as.Equal(http.StatusSeeOther, res.Code)
+  as.Equal("/books/"+book.ID.String(), res.Location())
+
+  as.NoError(as.DB.Reload(book))
+  as.Equal(models.StatusInProgress, book.Status)
+  as.Equal(0, book.Completions)
+
+  res = as.HTML("/books/%s", book.ID).Get()
+  as.Contains(res.Body.String(), "#2")
+  as.Contains(res.Body.String(), "412 pages")
+}
+
+func (as *ActionSuite) Test_RunsResource_Destroy() {
+  book := as.createBook("Dune", 412, 412)
+  first, err := book.CurrentRun(as.DB)
+  as.NoError(err)
+
+  res := as.HTML("/completions/%s/runs/%s", book.ID, first.ID).Delete()
+  as.Equal(http.StatusUnprocessableEntity, res.Code)
+
+  as.finishBook(book)
+  verrs, err := book.StartRun(as.DB, first.StartedAt)
+  as.NoError(err)
+  as.False(verrs.HasAny())
+
+  res = as.HTML("/completions/%s/runs/%s", book.ID, first.ID).Delete()
+  as.Equal(http.StatusSeeOther, res.Code)
+
+  count, err := as.DB.Where("completion_id = ?", book.ID).Count(&models.Run{})
+  as.NoError(err)
+  as.Equal(1, count)
+}
+
+func (as *ActionSuite) Test_RunsResource_Update_OtherUsers() {
+  book := as.createBook("Dune", 412, 412)
+  run, err := book.CurrentRun(as.DB)
+  as.NoError(err)
+
+  // Posting its id in an update of someone else's run leaves it alone
+  as.user = as.createUser("stranger@example.com")
+  as.signIn(as.user)
+  theirs := as.createBook("Hyperion", 0, 482)
+  theirRun, err := theirs.CurrentRun(as.DB)
+  as.NoError(err)
+
+  startedAt := time.Now().AddDate(-1, 0, 0)
+  jres := as.JSON("/completions/%s/runs/%s", theirs.ID, theirRun.ID).Put(map[string]interface{}{
+    "id":         run.ID,
+    "started_at": startedAt,
+  })
+  as.Equal(http.StatusOK, jres.Code)
+
+  as.NoError(as.DB.Reload(theirRun))
+  as.WithinDuration(startedAt, theirRun.StartedAt, time.Second)
+
+  as.NoError(as.DB.Reload(run))
+  as.True(run.StartedAt.After(startedAt.AddDate(0, 6, 0)))
+}
@@ -0,0 +1,44 @@
+package actions
+
+import (
+    "fmt"
+    "net/http"
+
+    "completion_tracker/models"
+
+    "github.com/gobuffalo/buffalo"
+    "github.com/gobuffalo/pop/v6"
+    "github.com/gobuffalo/x/responder"
+)
+
+// SearchResource finds completions of every type by their names, tags,
+// reviews and progress notes, among those the signed in User can see.
+type SearchResource struct{}
+
+// List gets the completions that match the "q" param, best first. Words
+// can be quoted, joined with "or" or excluded with a leading "-", and
+// typos in them are forgiven. This function is mapped to the path
+// GET /search
+func (v SearchResource) List(c buffalo.Context) error {
+    // Get the DB connection from the context
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    q := c.Param("q")
+    results, err := models.Search(tx, currentUser(c), q)
+    if err != nil {
+        return err
+    }
+
+    return responder.Wants("html", func(c buffalo.Context) error {
+        c.Set("q", q)
+        c.Set("results", results)
+        return c.Render(http.StatusOK, r.HTML("search/index.plush.html"))
+    }).Wants("json", func(c buffalo.Context) error {
+        return c.Render(200, r.JSON(results))
+    }).Wants("xml", func(c buffalo.Context) error {
+        return c.Render(200, r.XML(results))
+    }).Respond(c)
+}
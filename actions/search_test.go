@@ -0,0 +1,33 @@
+package actions
+
+import (
+  "net/http"
+  "time"
+
+  "completion_tracker/models"
+
+  "github.com/gobuffalo/nulls"
+)
+
+func (as *ActionSuite) Test_SearchResource_List() {
+  as.createBook("The Hobbit", 10, 300)
+  stranger := as.createUser("stranger@example.com")
+  as.NoError(as.DB.Create(&models.Completion{Name: "The Hobbit Returns", Type: models.CompletionTypeBook, CompletedAt: time.Now(), UserID: nulls.NewUUID(stranger.ID)}))
+
+  res := as.HTML("/search?q=hobit").Get()
+  as.Equal(http.StatusOK, res.Code)
+  as.Contains(res.Body.String(), "<mark>Hobbit</mark>")
+  as.NotContains(res.Body.String(), "Returns")
+
+  jres := as.JSON("/search?q=hobbit").Get()
+  as.Equal(http.StatusOK, jres.Code)
+  results := models.SearchResults{}
+  jres.Bind(&results)
+  as.Len(results, 1)
+  as.Equal("The Hobbit", results[0].Completion.Name)
+  as.Equal([]string{"name"}, results[0].Matches)
+
+  res = as.HTML("/search").Get()
+  as.Equal(http.StatusOK, res.Code)
+  as.NotContains(res.Body.String(), "Nothing matches")
+}
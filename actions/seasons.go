@@ -0,0 +1,256 @@
+package actions
+
+import (
+    "fmt"
+    "net/http"
+
+    "completion_tracker/models"
+
+    "github.com/gobuffalo/buffalo"
+    "github.com/gobuffalo/pop/v6"
+    "github.com/gobuffalo/x/responder"
+)
+
+// SeasonsResource manages the Seasons of a TV Show. It is mounted at
+// /tv_shows/{tv_show_id}/seasons, and its HTML lives in the season grid on
+// the TV Show's page.
+type SeasonsResource struct{
+    buffalo.Resource
+}
+
+// findTvShow loads the TV Show completion named by tv_show_id. It has to be
+// one the signed in User can see to read it, and one they can update to
+// change it.
+func findTvShow(c buffalo.Context, tx *pop.Connection) (*models.Completion, error) {
+    scope := editableCompletions(c)
+    if c.Request().Method == http.MethodGet {
+        scope = visibleCompletions(c)
+    }
+
+    completion := &models.Completion{}
+    if err := tx.Scope(scope).Find(completion, c.Param("tv_show_id")); err != nil {
+        return nil, c.Error(http.StatusNotFound, err)
+    }
+
+    if completion.Type != models.CompletionTypeTVShow {
+        return nil, c.Error(http.StatusNotFound, fmt.Errorf("completion is not a TV show"))
+    }
+    return completion, nil
+}
+
+// findSeason loads the Season named by season_id, provided it belongs to
+// completion.
+func findSeason(c buffalo.Context, tx *pop.Connection, completion *models.Completion) (*models.Season, error) {
+    season := &models.Season{}
+    if err := tx.Where("completion_id = ?", completion.ID).Find(season, c.Param("season_id")); err != nil {
+        return nil, c.Error(http.StatusNotFound, err)
+    }
+    return season, nil
+}
+
+// List gets all Seasons of a TV Show with their Episodes. This function is
+// mapped to the path GET /tv_shows/{tv_show_id}/seasons
+func (v SeasonsResource) List(c buffalo.Context) error {
+    // Get the DB connection from the context
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    completion, err := findTvShow(c, tx)
+    if err != nil {
+        return err
+    }
+
+    seasons := &models.Seasons{}
+    if err := tx.Eager("Episodes").Where("completion_id = ?", completion.ID).Order("number asc").All(seasons); err != nil {
+        return err
+    }
+
+    return responder.Wants("html", func(c buffalo.Context) error {
+        return c.Redirect(http.StatusSeeOther, "/tv_shows/%v#seasons", completion.ID)
+    }).Wants("json", func(c buffalo.Context) error {
+        return c.Render(200, r.JSON(seasons))
+    }).Wants("xml", func(c buffalo.Context) error {
+        return c.Render(200, r.XML(seasons))
+    }).Respond(c)
+}
+
+// Show gets the data for one Season with its Episodes. This function is
+// mapped to the path GET /tv_shows/{tv_show_id}/seasons/{season_id}
+func (v SeasonsResource) Show(c buffalo.Context) error {
+    // Get the DB connection from the context
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    completion, err := findTvShow(c, tx)
+    if err != nil {
+        return err
+    }
+
+    season, err := findSeason(c, tx, completion)
+    if err != nil {
+        return err
+    }
+    if err := tx.Load(season, "Episodes"); err != nil {
+        return err
+    }
+
+    return responder.Wants("html", func(c buffalo.Context) error {
+        return c.Redirect(http.StatusSeeOther, "/tv_shows/%v#season-%d", completion.ID, season.Number)
+    }).Wants("json", func(c buffalo.Context) error {
+        return c.Render(200, r.JSON(season))
+    }).Wants("xml", func(c buffalo.Context) error {
+        return c.Render(200, r.XML(season))
+    }).Respond(c)
+}
+
+// Create adds a Season to a TV Show, along with EpisodeCount episodes.
+// This function is mapped to the path POST /tv_shows/{tv_show_id}/seasons
+func (v SeasonsResource) Create(c buffalo.Context) error {
+    // Get the DB connection from the context
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    completion, err := findTvShow(c, tx)
+    if err != nil {
+        return err
+    }
+
+    // Allocate an empty Season
+    season := &models.Season{}
+
+    // Bind season to the html form elements
+    if err := c.Bind(season); err != nil {
+        return err
+    }
+    season.CompletionID = completion.ID
+
+    // Validate the data from the html form
+    verrs, err := tx.ValidateAndCreate(season)
+    if err != nil {
+        return err
+    }
+
+    if verrs.HasAny() {
+        return responder.Wants("html", func(c buffalo.Context) error {
+            // The season is added from the grid, so report the problem
+            // on the TV Show's page.
+            c.Flash().Add("danger", verrs.Error())
+            return c.Redirect(http.StatusSeeOther, "/tv_shows/%v#seasons", completion.ID)
+        }).Wants("json", func(c buffalo.Context) error {
+            return renderValidationErrors(c, verrs)
+        }).Wants("xml", func(c buffalo.Context) error {
+            return c.Render(http.StatusUnprocessableEntity, r.XML(verrs))
+        }).Respond(c)
+    }
+
+    return responder.Wants("html", func(c buffalo.Context) error {
+        // If there are no errors set a success message
+        c.Flash().Add("success", T.Translate(c, "season.created.success"))
+
+        // and redirect to the TV Show's season grid
+        return c.Redirect(http.StatusSeeOther, "/tv_shows/%v#season-%d", completion.ID, season.Number)
+    }).Wants("json", func(c buffalo.Context) error {
+        return c.Render(http.StatusCreated, r.JSON(season))
+    }).Wants("xml", func(c buffalo.Context) error {
+        return c.Render(http.StatusCreated, r.XML(season))
+    }).Respond(c)
+}
+
+// Update changes a Season's number or title. This function is mapped to
+// the path PUT /tv_shows/{tv_show_id}/seasons/{season_id}
+func (v SeasonsResource) Update(c buffalo.Context) error {
+    // Get the DB connection from the context
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    completion, err := findTvShow(c, tx)
+    if err != nil {
+        return err
+    }
+
+    season, err := findSeason(c, tx, completion)
+    if err != nil {
+        return err
+    }
+
+    // Bind Season to the html form elements, keeping which season it is
+    persisted := *season
+    if err := c.Bind(season); err != nil {
+        return err
+    }
+    season.ID = persisted.ID
+    season.CreatedAt = persisted.CreatedAt
+    season.CompletionID = completion.ID
+
+    verrs, err := tx.ValidateAndUpdate(season)
+    if err != nil {
+        return err
+    }
+
+    if verrs.HasAny() {
+        return responder.Wants("html", func(c buffalo.Context) error {
+            c.Flash().Add("danger", verrs.Error())
+            return c.Redirect(http.StatusSeeOther, "/tv_shows/%v#seasons", completion.ID)
+        }).Wants("json", func(c buffalo.Context) error {
+            return renderValidationErrors(c, verrs)
+        }).Wants("xml", func(c buffalo.Context) error {
+            return c.Render(http.StatusUnprocessableEntity, r.XML(verrs))
+        }).Respond(c)
+    }
+
+    return responder.Wants("html", func(c buffalo.Context) error {
+        // If there are no errors set a success message
+        c.Flash().Add("success", T.Translate(c, "season.updated.success"))
+
+        // and redirect to the TV Show's season grid
+        return c.Redirect(http.StatusSeeOther, "/tv_shows/%v#season-%d", completion.ID, season.Number)
+    }).Wants("json", func(c buffalo.Context) error {
+        return c.Render(http.StatusOK, r.JSON(season))
+    }).Wants("xml", func(c buffalo.Context) error {
+        return c.Render(http.StatusOK, r.XML(season))
+    }).Respond(c)
+}
+
+// Destroy deletes a Season and its Episodes. This function is mapped to
+// the path DELETE /tv_shows/{tv_show_id}/seasons/{season_id}
+func (v SeasonsResource) Destroy(c buffalo.Context) error {
+    // Get the DB connection from the context
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    completion, err := findTvShow(c, tx)
+    if err != nil {
+        return err
+    }
+
+    season, err := findSeason(c, tx, completion)
+    if err != nil {
+        return err
+    }
+
+    if err := tx.Destroy(season); err != nil {
+        return err
+    }
+
+    return responder.Wants("html", func(c buffalo.Context) error {
+        // If there are no errors set a flash message
+        c.Flash().Add("success", T.Translate(c, "season.destroyed.success"))
+
+        // Redirect to the TV Show's season grid
+        return c.Redirect(http.StatusSeeOther, "/tv_shows/%v#seasons", completion.ID)
+    }).Wants("json", func(c buffalo.Context) error {
+        return c.Render(http.StatusOK, r.JSON(season))
+    }).Wants("xml", func(c buffalo.Context) error {
+        return c.Render(http.StatusOK, r.XML(season))
+    }).Respond(c)
+}
@@ -0,0 +1,71 @@
+package actions
+
+import (
+  "net/http"
+
+  "completion_tracker/models"
+)
+
+func (as *ActionSuite) Test_SeasonsResource_Create() {
+  tvShow := as.createTvShow("Severance", 0, 0)
+
+  jres := as.JSON("/tv_shows/%s/seasons", tvShow.ID).Post(map[string]interface{}{
+    "number":        1,
+    "title":         "Good News About Hell",
+    "episode_count": 9,
+  })
+  as.Equal(http.StatusCreated, jres.Code)
+
+  as.NoError(as.DB.Reload(tvShow))
+  as.Equal(9, tvShow.Target)
+
+  jres = as.JSON("/tv_shows/%s/seasons", tvShow.ID).Post(map[string]interface{}{
+    "number": 1,
+  })
+  as.Equal(http.StatusUnprocessableEntity, jres.Code)
+
+  res := as.HTML("/tv_shows/%s", tvShow.ID).Get()
+  as.Equal(http.StatusOK, res.Code)
+  as.Contains(res.Body.String(), "Season 1")
+  as.Contains(res.Body.String(), "0 of 9 watched")
+
+  book := as.createBook("Dune", 10, 0)
+  jres = as.JSON("/tv_shows/%s/seasons", book.ID).Post(map[string]interface{}{
+    "number": 1,
+  })
+  as.Equal(http.StatusNotFound, jres.Code)
+}
+
+func (as *ActionSuite) Test_SeasonsResource_Destroy() {
+  tvShow := as.createTvShow("Severance", 0, 0)
+  season := &models.Season{CompletionID: tvShow.ID, Number: 1, EpisodeCount: 3}
+  as.NoError(as.DB.Create(season))
+
+  res := as.HTML("/tv_shows/%s/seasons/%s", tvShow.ID, season.ID).Delete()
+  as.Equal(http.StatusSeeOther, res.Code)
+
+  as.NoError(as.DB.Reload(tvShow))
+  as.Equal(0, tvShow.Target)
+}
+
+func (as *ActionSuite) Test_SeasonsResource_Update_OtherUsers() {
+  season := as.createSeason(as.createTvShow("Severance", 0, 0), 1, 0)
+
+  // Posting its id in an update of someone else's season leaves it alone
+  as.user = as.createUser("stranger@example.com")
+  as.signIn(as.user)
+  tvShow := as.createTvShow("Andor", 0, 0)
+  theirs := as.createSeason(tvShow, 1, 0)
+
+  jres := as.JSON("/tv_shows/%s/seasons/%s", tvShow.ID, theirs.ID).Put(map[string]interface{}{
+    "id":    season.ID,
+    "title": "Ferrix",
+  })
+  as.Equal(http.StatusOK, jres.Code)
+
+  as.NoError(as.DB.Reload(theirs))
+  as.Equal("Ferrix", theirs.Title)
+
+  as.NoError(as.DB.Reload(season))
+  as.Equal("", season.Title)
+}
@@ -0,0 +1,306 @@
+package actions
+
+import (
+    "fmt"
+    "net/http"
+
+    "completion_tracker/models"
+
+    "github.com/gobuffalo/buffalo"
+    "github.com/gobuffalo/nulls"
+    "github.com/gobuffalo/pop/v6"
+    "github.com/gobuffalo/x/responder"
+)
+
+// setCompletionOptions makes the signed in User's series, and the groups
+// they can share with, available to the completion forms, after "No
+// series" and "No group" options.
+func setCompletionOptions(c buffalo.Context) error {
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    series := models.SeriesList{}
+    if err := tx.Scope(models.SeriesOwnedBy(currentUser(c))).Order("name asc").All(&series); err != nil {
+        return err
+    }
+
+    c.Set("seriesOptions", append(models.SeriesList{{Name: "No series"}}, series...))
+    return setGroupOptions(c, tx)
+}
+
+// checkSeries makes sure completion only joins a Series that belongs to
+// the same User as it does.
+func checkSeries(c buffalo.Context, tx *pop.Connection, completion *models.Completion) error {
+    if !completion.SeriesID.Valid {
+        return nil
+    }
+
+    owned, err := tx.Where("id = ? AND user_id = ?", completion.SeriesID.UUID, completion.UserID).Exists(&models.Series{})
+    if err != nil {
+        return err
+    }
+    if !owned {
+        return c.Error(http.StatusForbidden, fmt.Errorf("can't add to series %s", completion.SeriesID.UUID))
+    }
+    return nil
+}
+
+// SeriesResource groups completions into series and franchises.
+type SeriesResource struct{
+    buffalo.Resource
+}
+
+// List gets the signed in User's Series. This function is mapped to the path
+// GET /series
+func (v SeriesResource) List(c buffalo.Context) error {
+    // Get the DB connection from the context
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    series := &models.SeriesList{}
+
+    // Paginate results. Params "page" and "per_page" control pagination.
+    // Default values are "page=1" and "per_page=20".
+    q := tx.PaginateFromParams(c.Params()).Scope(models.SeriesOwnedBy(currentUser(c))).Order("name asc")
+
+    // Retrieve all Series from the DB
+    if err := q.All(series); err != nil {
+        return err
+    }
+
+    // Load each Series' completions, leaving out those in the trash
+    for i := range *series {
+        if err := (*series)[i].LoadCompletions(tx, currentUser(c)); err != nil {
+            return err
+        }
+    }
+
+    return responder.Wants("html", func(c buffalo.Context) error {
+        // Add the paginator to the context so it can be used in the template.
+        c.Set("pagination", q.Paginator)
+
+        c.Set("seriesList", series)
+        return c.Render(http.StatusOK, r.HTML("series/index.plush.html"))
+    }).Wants("json", func(c buffalo.Context) error {
+        return c.Render(200, r.JSON(series))
+    }).Wants("xml", func(c buffalo.Context) error {
+        return c.Render(200, r.XML(series))
+    }).Respond(c)
+}
+
+// Show gets one Series with its completions in order, how far through it
+// the user is and what comes next. This function is mapped to the path
+// GET /series/{series_id}
+func (v SeriesResource) Show(c buffalo.Context) error {
+    // Get the DB connection from the context
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    // Allocate an empty Series
+    series := &models.Series{}
+
+    // To find the Series the parameter series_id is used.
+    if err := tx.Scope(models.SeriesOwnedBy(currentUser(c))).Find(series, c.Param("series_id")); err != nil {
+        return c.Error(http.StatusNotFound, err)
+    }
+
+    if err := series.LoadCompletions(tx, currentUser(c)); err != nil {
+        return err
+    }
+
+    return responder.Wants("html", func(c buffalo.Context) error {
+        c.Set("series", series)
+
+        return c.Render(http.StatusOK, r.HTML("series/show.plush.html"))
+    }).Wants("json", func(c buffalo.Context) error {
+        return c.Render(200, r.JSON(series))
+    }).Wants("xml", func(c buffalo.Context) error {
+        return c.Render(200, r.XML(series))
+    }).Respond(c)
+}
+
+// New renders the form for creating a new Series.
+// This function is mapped to the path GET /series/new
+func (v SeriesResource) New(c buffalo.Context) error {
+    c.Set("series", &models.Series{})
+
+    return c.Render(http.StatusOK, r.HTML("series/new.plush.html"))
+}
+
+// Create adds a Series to the DB. This function is mapped to the
+// path POST /series
+func (v SeriesResource) Create(c buffalo.Context) error {
+    // Allocate an empty Series
+    series := &models.Series{}
+
+    // Bind series to the html form elements
+    if err := c.Bind(series); err != nil {
+        return err
+    }
+
+    // It always belongs to the signed in User, whatever was posted
+    series.UserID = nulls.NewUUID(currentUser(c).ID)
+
+    // Get the DB connection from the context
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    // Validate the data from the html form
+    verrs, err := tx.ValidateAndCreate(series)
+    if err != nil {
+        return err
+    }
+
+    if verrs.HasAny() {
+        return responder.Wants("html", func(c buffalo.Context) error {
+            // Make the errors available inside the html template
+            c.Set("errors", verrs)
+
+            // Render again the new.html template that the user can
+            // correct the input.
+            c.Set("series", series)
+
+            return c.Render(http.StatusUnprocessableEntity, r.HTML("series/new.plush.html"))
+        }).Wants("json", func(c buffalo.Context) error {
+            return renderValidationErrors(c, verrs)
+        }).Wants("xml", func(c buffalo.Context) error {
+            return c.Render(http.StatusUnprocessableEntity, r.XML(verrs))
+        }).Respond(c)
+    }
+
+    return responder.Wants("html", func(c buffalo.Context) error {
+        // If there are no errors set a success message
+        c.Flash().Add("success", T.Translate(c, "series.created.success"))
+
+        // and redirect to the show page
+        return c.Redirect(http.StatusSeeOther, "/series/%v", series.ID)
+    }).Wants("json", func(c buffalo.Context) error {
+        return c.Render(http.StatusCreated, r.JSON(series))
+    }).Wants("xml", func(c buffalo.Context) error {
+        return c.Render(http.StatusCreated, r.XML(series))
+    }).Respond(c)
+}
+
+// Edit renders a edit form for a Series. This function is
+// mapped to the path GET /series/{series_id}/edit
+func (v SeriesResource) Edit(c buffalo.Context) error {
+    // Get the DB connection from the context
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    // Allocate an empty Series
+    series := &models.Series{}
+
+    if err := tx.Scope(models.SeriesOwnedBy(currentUser(c))).Find(series, c.Param("series_id")); err != nil {
+        return c.Error(http.StatusNotFound, err)
+    }
+
+    c.Set("series", series)
+    return c.Render(http.StatusOK, r.HTML("series/edit.plush.html"))
+}
+
+// Update changes a Series in the DB. This function is mapped to
+// the path PUT /series/{series_id}
+func (v SeriesResource) Update(c buffalo.Context) error {
+    // Get the DB connection from the context
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    // Allocate an empty Series
+    series := &models.Series{}
+
+    if err := tx.Scope(models.SeriesOwnedBy(currentUser(c))).Find(series, c.Param("series_id")); err != nil {
+        return c.Error(http.StatusNotFound, err)
+    }
+
+    // Bind Series to the html form elements, keeping which Series it is
+    // and who it belongs to
+    persisted := *series
+    if err := c.Bind(series); err != nil {
+        return err
+    }
+    series.ID = persisted.ID
+    series.CreatedAt = persisted.CreatedAt
+    series.UserID = persisted.UserID
+
+    verrs, err := tx.ValidateAndUpdate(series)
+    if err != nil {
+        return err
+    }
+
+    if verrs.HasAny() {
+        return responder.Wants("html", func(c buffalo.Context) error {
+            // Make the errors available inside the html template
+            c.Set("errors", verrs)
+
+            // Render again the edit.html template that the user can
+            // correct the input.
+            c.Set("series", series)
+
+            return c.Render(http.StatusUnprocessableEntity, r.HTML("series/edit.plush.html"))
+        }).Wants("json", func(c buffalo.Context) error {
+            return renderValidationErrors(c, verrs)
+        }).Wants("xml", func(c buffalo.Context) error {
+            return c.Render(http.StatusUnprocessableEntity, r.XML(verrs))
+        }).Respond(c)
+    }
+
+    return responder.Wants("html", func(c buffalo.Context) error {
+        // If there are no errors set a success message
+        c.Flash().Add("success", T.Translate(c, "series.updated.success"))
+
+        // and redirect to the show page
+        return c.Redirect(http.StatusSeeOther, "/series/%v", series.ID)
+    }).Wants("json", func(c buffalo.Context) error {
+        return c.Render(http.StatusOK, r.JSON(series))
+    }).Wants("xml", func(c buffalo.Context) error {
+        return c.Render(http.StatusOK, r.XML(series))
+    }).Respond(c)
+}
+
+// Destroy deletes a Series from the DB. Its completions are kept and
+// simply leave the series. This function is mapped to the path
+// DELETE /series/{series_id}
+func (v SeriesResource) Destroy(c buffalo.Context) error {
+    // Get the DB connection from the context
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    // Allocate an empty Series
+    series := &models.Series{}
+
+    // To find the Series the parameter series_id is used.
+    if err := tx.Scope(models.SeriesOwnedBy(currentUser(c))).Find(series, c.Param("series_id")); err != nil {
+        return c.Error(http.StatusNotFound, err)
+    }
+
+    if err := tx.Destroy(series); err != nil {
+        return err
+    }
+
+    return responder.Wants("html", func(c buffalo.Context) error {
+        // If there are no errors set a flash message
+        c.Flash().Add("success", T.Translate(c, "series.destroyed.success"))
+
+        // Redirect to the index page
+        return c.Redirect(http.StatusSeeOther, "/series")
+    }).Wants("json", func(c buffalo.Context) error {
+        return c.Render(http.StatusOK, r.JSON(series))
+    }).Wants("xml", func(c buffalo.Context) error {
+        return c.Render(http.StatusOK, r.XML(series))
+    }).Respond(c)
+}
@@ -0,0 +1,133 @@
+package actions
+
+import (
+  "net/http"
+  "time"
+
+  "completion_tracker/models"
+
+  "github.com/gobuffalo/nulls"
+)
+
+func (as *ActionSuite) createSeries(name string) *models.Series {
+  series := &models.Series{Name: name, UserID: nulls.NewUUID(as.user.ID)}
+  verrs, err := as.DB.ValidateAndCreate(series)
+  as.NoError(err)
+  as.False(verrs.HasAny())
+  return series
+}
+
+func (as *ActionSuite) addToSeries(completion *models.Completion, series *models.Series, position int) {
+  completion.SeriesID = nulls.NewUUID(series.ID)
+  completion.SeriesPosition = position
+  verrs, err := as.DB.ValidateAndUpdate(completion)
+  as.NoError(err)
+  as.False(verrs.HasAny())
+}
+
+func (as *ActionSuite) Test_SeriesResource_List() {
+  as.createSeries("Dune Chronicles")
+
+  res := as.HTML("/series").Get()
+  as.Equal(http.StatusOK, res.Code)
+  as.Contains(res.Body.String(), "Dune Chronicles")
+}
+
+func (as *ActionSuite) Test_SeriesResource_Show() {
+  series := as.createSeries("Dune Chronicles")
+  first := as.createBook("Dune", 412, 412)
+  as.finishBook(first)
+  as.addToSeries(first, series, 1)
+  second := as.createBook("Dune Messiah", 10, 256)
+  as.addToSeries(second, series, 2)
+
+  res := as.HTML("/series/%s", series.ID).Get()
+  as.Equal(http.StatusOK, res.Code)
+  as.Contains(res.Body.String(), "1 of 2 completed")
+  as.Contains(res.Body.String(), "/books/"+second.ID.String())
+
+  jres := as.JSON("/series/%s", series.ID).Get()
+  as.Equal(http.StatusOK, jres.Code)
+  as.Contains(jres.Body.String(), `"percent_complete":50`)
+  as.Contains(jres.Body.String(), `"next":{`)
+  as.Contains(jres.Body.String(), `"name":"Dune Messiah"`)
+
+  res = as.HTML("/books/%s", second.ID).Get()
+  as.Contains(res.Body.String(), "Dune Chronicles")
+}
+
+func (as *ActionSuite) Test_SeriesResource_Create() {
+  res := as.HTML("/series").Post(&models.Series{Name: "Halo"})
+  as.Equal(http.StatusSeeOther, res.Code)
+
+  series := &models.Series{}
+  as.NoError(as.DB.Where("name = ?", "Halo").First(series))
+  as.Equal(nulls.NewUUID(as.user.ID), series.UserID)
+
+  jres := as.JSON("/series").Post(map[string]interface{}{})
+  as.Equal(http.StatusUnprocessableEntity, jres.Code)
+}
+
+func (as *ActionSuite) Test_SeriesResource_OtherUsers() {
+  series := as.createSeries("Dune Chronicles")
+
+  // Someone else signed in can't see, change or use it
+  as.signIn(as.createUser("stranger@example.com"))
+
+  res := as.HTML("/series").Get()
+  as.Equal(http.StatusOK, res.Code)
+  as.NotContains(res.Body.String(), "Dune Chronicles")
+
+  res = as.HTML("/books/new").Get()
+  as.NotContains(res.Body.String(), "Dune Chronicles")
+
+  as.Equal(http.StatusNotFound, as.JSON("/series/%s", series.ID).Get().Code)
+  as.Equal(http.StatusNotFound, as.JSON("/series/%s", series.ID).Put(map[string]interface{}{"name": "Foundation"}).Code)
+  as.Equal(http.StatusNotFound, as.JSON("/series/%s", series.ID).Delete().Code)
+
+  jres := as.JSON("/books").Post(map[string]interface{}{
+    "name":            "Dune",
+    "completed_at":    time.Now(),
+    "series_id":       series.ID,
+    "series_position": 1,
+  })
+  as.Equal(http.StatusForbidden, jres.Code)
+
+  as.NoError(as.DB.Reload(series))
+  as.Equal("Dune Chronicles", series.Name)
+}
+
+func (as *ActionSuite) Test_SeriesResource_Destroy() {
+  series := as.createSeries("Dune Chronicles")
+  book := as.createBook("Dune", 10, 412)
+  as.addToSeries(book, series, 1)
+
+  res := as.HTML("/series/%s", series.ID).Delete()
+  as.Equal(http.StatusSeeOther, res.Code)
+
+  as.NoError(as.DB.Reload(book))
+  as.False(book.SeriesID.Valid)
+}
+
+func (as *ActionSuite) Test_BooksResource_SeriesField() {
+  series := as.createSeries("Dune Chronicles")
+
+  res := as.HTML("/books/new").Get()
+  as.Equal(http.StatusOK, res.Code)
+  as.Contains(res.Body.String(), "Dune Chronicles")
+
+  book := as.createBook("Dune", 10, 412)
+  res = as.HTML("/books/%s", book.ID).Put(map[string]interface{}{
+    "Name":           "Dune",
+    "Completions":    10,
+    "Target":         412,
+    "CompletedAt":    book.CompletedAt.Format("2006-01-02T15:04"),
+    "Status":         "planned",
+    "SeriesID":       series.ID.String(),
+    "SeriesPosition": 1,
+  })
+  as.Equal(http.StatusSeeOther, res.Code)
+
+  as.NoError(as.DB.Reload(book))
+  as.Equal(series.ID, book.SeriesID.UUID)
+}
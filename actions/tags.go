@@ -0,0 +1,76 @@
+package actions
+
+import (
+    "fmt"
+    "net/http"
+
+    "completion_tracker/models"
+
+    "github.com/gobuffalo/buffalo"
+    "github.com/gobuffalo/pop/v6"
+    "github.com/gobuffalo/x/responder"
+)
+
+// TagsResource browses completions of every type by Tag. Tags are created
+// and removed through the tag list on each completion's form, so there is
+// only a list of tags and a page for each, found by its slug.
+type TagsResource struct{}
+
+// List gets the Tags on the completions the User can see with the number
+// of them that carry each. This function is mapped to the path GET /tags
+func (v TagsResource) List(c buffalo.Context) error {
+    // Get the DB connection from the context
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    counts, err := models.CountTags(tx, currentUser(c))
+    if err != nil {
+        return err
+    }
+
+    return responder.Wants("html", func(c buffalo.Context) error {
+        c.Set("tagCounts", counts)
+        return c.Render(http.StatusOK, r.HTML("tags/index.plush.html"))
+    }).Wants("json", func(c buffalo.Context) error {
+        return c.Render(200, r.JSON(counts))
+    }).Wants("xml", func(c buffalo.Context) error {
+        return c.Render(200, r.XML(counts))
+    }).Respond(c)
+}
+
+// Show gets one Tag with the completions of every type that carry it.
+// This function is mapped to the path GET /tags/{slug}
+func (v TagsResource) Show(c buffalo.Context) error {
+    // Get the DB connection from the context
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    tag := &models.Tag{}
+    if err := tx.Where("slug = ?", c.Param("slug")).First(tag); err != nil {
+        return c.Error(http.StatusNotFound, err)
+    }
+
+    // Paginate the tagged completions the User can see, which may be of
+    // any type
+    q := tx.PaginateFromParams(c.Params()).Scope(visibleCompletions(c))
+    q = q.Join("completion_tags", "completion_tags.completion_id = completions.id")
+    q = q.Where("completion_tags.tag_id = ?", tag.ID).Order("completions.type asc, completions.name asc")
+
+    if err := q.All(&tag.Completions); err != nil {
+        return err
+    }
+
+    return responder.Wants("html", func(c buffalo.Context) error {
+        c.Set("pagination", q.Paginator)
+        c.Set("tag", tag)
+        return c.Render(http.StatusOK, r.HTML("tags/show.plush.html"))
+    }).Wants("json", func(c buffalo.Context) error {
+        return c.Render(200, r.JSON(tag))
+    }).Wants("xml", func(c buffalo.Context) error {
+        return c.Render(200, r.XML(tag))
+    }).Respond(c)
+}
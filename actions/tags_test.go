@@ -0,0 +1,102 @@
+package actions
+
+import (
+  "net/http"
+  "time"
+
+  "completion_tracker/models"
+
+  "github.com/gobuffalo/nulls"
+)
+
+func (as *ActionSuite) tag(completion *models.Completion, names ...string) {
+  as.NoError(completion.SetTags(as.DB, names))
+}
+
+func (as *ActionSuite) Test_TagsResource_List() {
+  as.tag(as.createBook("Dune", 206, 412), "sci-fi", "book club")
+  game := &models.Completion{Name: "Halo", Type: models.CompletionTypeVideoGame, CompletedAt: time.Now(), UserID: nulls.NewUUID(as.user.ID)}
+  as.NoError(as.DB.Create(game))
+  as.tag(game, "Sci-Fi", "co-op")
+
+  res := as.HTML("/tags").Get()
+  as.Equal(http.StatusOK, res.Code)
+  as.Contains(res.Body.String(), "book club")
+
+  counts := models.TagCounts{}
+  jres := as.JSON("/tags").Get()
+  as.Equal(http.StatusOK, jres.Code)
+  jres.Bind(&counts)
+  as.Len(counts, 3)
+  as.Equal("sci-fi", counts[0].Slug)
+  as.Equal(2, counts[0].Count)
+
+  // Tags on completions shared with the user's groups are listed, but
+  // not those only strangers use
+  friend := as.createUser("friend@example.com")
+  g := as.createGroup(map[*models.User]models.Role{friend: models.RoleEditor})
+  shared := &models.Completion{Name: "Severance", Type: models.CompletionTypeTVShow, CompletedAt: time.Now(), UserID: nulls.NewUUID(friend.ID), GroupID: nulls.NewUUID(g.ID)}
+  as.NoError(as.DB.Create(shared))
+  as.tag(shared, "mystery")
+
+  theirs := &models.Completion{Name: "Solaris", Type: models.CompletionTypeBook, CompletedAt: time.Now(), UserID: nulls.NewUUID(as.createUser("stranger@example.com").ID)}
+  as.NoError(as.DB.Create(theirs))
+  as.tag(theirs, "russian")
+
+  jres = as.JSON("/tags").Get()
+  as.Equal(http.StatusOK, jres.Code)
+  as.Contains(jres.Body.String(), `"slug":"mystery"`)
+  as.NotContains(jres.Body.String(), "russian")
+}
+
+func (as *ActionSuite) Test_TagsResource_Show() {
+  as.tag(as.createBook("Dune", 206, 412), "sci-fi")
+  game := &models.Completion{Name: "Halo", Type: models.CompletionTypeVideoGame, CompletedAt: time.Now(), UserID: nulls.NewUUID(as.user.ID)}
+  as.NoError(as.DB.Create(game))
+  as.tag(game, "sci-fi")
+  as.createBook("Emma", 10, 474)
+
+  res := as.HTML("/tags/sci-fi").Get()
+  as.Equal(http.StatusOK, res.Code)
+  as.Contains(res.Body.String(), "Dune")
+  as.Contains(res.Body.String(), "Halo")
+  as.NotContains(res.Body.String(), "Emma")
+
+  tag := &models.Tag{}
+  jres := as.JSON("/tags/sci-fi").Get()
+  as.Equal(http.StatusOK, jres.Code)
+  jres.Bind(tag)
+  as.Len(tag.Completions, 2)
+
+  // Completions shared with the user's groups are listed too
+  friend := as.createUser("friend@example.com")
+  g := as.createGroup(map[*models.User]models.Role{friend: models.RoleEditor})
+  shared := &models.Completion{Name: "Solaris", Type: models.CompletionTypeBook, CompletedAt: time.Now(), UserID: nulls.NewUUID(friend.ID), GroupID: nulls.NewUUID(g.ID)}
+  as.NoError(as.DB.Create(shared))
+  as.tag(shared, "sci-fi")
+
+  res = as.HTML("/tags/sci-fi").Get()
+  as.Equal(http.StatusOK, res.Code)
+  as.Contains(res.Body.String(), "Solaris")
+
+  as.Equal(http.StatusNotFound, as.HTML("/tags/unknown").Get().Code)
+}
+
+func (as *ActionSuite) Test_TagsResource_FormTagging() {
+  book := as.createBook("Dune", 100, 412)
+
+  res := as.HTML("/books/%s", book.ID).Put(map[string]interface{}{
+    "Name":        "Dune",
+    "Completions": 100,
+    "Target":      412,
+    "CompletedAt": book.CompletedAt.Format("2006-01-02T15:04"),
+    "TagList":     "sci-fi, desert",
+  })
+  as.Equal(http.StatusSeeOther, res.Code)
+
+  as.NoError(as.DB.Load(book, "Tags"))
+  as.Equal("desert, sci-fi", book.TagNames())
+
+  show := as.HTML("/books/%s", book.ID).Get()
+  as.Contains(show.Body.String(), "/tags/desert")
+}
@@ -0,0 +1,153 @@
+package actions
+
+import (
+    "encoding/xml"
+    "fmt"
+    "net/http"
+
+    "completion_tracker/models"
+
+    "github.com/gobuffalo/buffalo"
+    "github.com/gobuffalo/pop/v6"
+    "github.com/gobuffalo/x/responder"
+)
+
+// TrashResource lists the signed in User's completions of every type that
+// have been deleted, and restores or purges them. A deleted completion is only moved
+// to the trash, so it can be brought back as it was.
+type TrashResource struct{}
+
+// findTrashedCompletion loads the trashed Completion named by completion_id.
+func findTrashedCompletion(c buffalo.Context, tx *pop.Connection) (*models.Completion, error) {
+    completion := &models.Completion{}
+    if err := tx.Scope(models.OwnedBy(currentUser(c))).Scope(models.Trashed).Find(completion, c.Param("completion_id")); err != nil {
+        return nil, c.Error(http.StatusNotFound, err)
+    }
+    return completion, nil
+}
+
+// List gets the completions in the trash, the most recently deleted
+// first. This function is mapped to the path GET /trash
+func (v TrashResource) List(c buffalo.Context) error {
+    // Get the DB connection from the context
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    completions := &models.Completions{}
+
+    // Paginate results. Params "page" and "per_page" control pagination.
+    // Default values are "page=1" and "per_page=20".
+    q := tx.PaginateFromParams(c.Params()).Scope(models.OwnedBy(currentUser(c))).Scope(models.Trashed).Order("deleted_at desc")
+
+    if err := q.All(completions); err != nil {
+        return err
+    }
+
+    return responder.Wants("html", func(c buffalo.Context) error {
+        c.Set("pagination", q.Paginator)
+        c.Set("completions", completions)
+        return c.Render(http.StatusOK, r.HTML("trash/index.plush.html"))
+    }).Wants("json", func(c buffalo.Context) error {
+        return c.Render(200, r.JSON(completions))
+    }).Wants("xml", func(c buffalo.Context) error {
+        return c.Render(200, r.XML(completions))
+    }).Respond(c)
+}
+
+// Restore takes a Completion back out of the trash. This function is
+// mapped to the path POST /trash/{completion_id}/restore
+func (v TrashResource) Restore(c buffalo.Context) error {
+    // Get the DB connection from the context
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    completion, err := findTrashedCompletion(c, tx)
+    if err != nil {
+        return err
+    }
+
+    if err := completion.Restore(tx); err != nil {
+        return err
+    }
+
+    return responder.Wants("html", func(c buffalo.Context) error {
+        // If there are no errors set a success message
+        c.Flash().Add("success", T.Translate(c, "trash.restored.success"))
+
+        // and redirect to the restored completion
+        return c.Redirect(http.StatusSeeOther, showPathFor(*completion))
+    }).Wants("json", func(c buffalo.Context) error {
+        return c.Render(http.StatusOK, r.JSON(completion))
+    }).Wants("xml", func(c buffalo.Context) error {
+        return c.Render(http.StatusOK, r.XML(completion))
+    }).Respond(c)
+}
+
+// Purge deletes a Completion in the trash for good, along with its runs,
+// progress and tags. This function is mapped to the path
+// DELETE /trash/{completion_id}
+func (v TrashResource) Purge(c buffalo.Context) error {
+    // Get the DB connection from the context
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    completion, err := findTrashedCompletion(c, tx)
+    if err != nil {
+        return err
+    }
+
+    if err := tx.Destroy(completion); err != nil {
+        return err
+    }
+
+    return responder.Wants("html", func(c buffalo.Context) error {
+        // If there are no errors set a flash message
+        c.Flash().Add("success", T.Translate(c, "trash.purged.success"))
+
+        // Redirect to the trash
+        return c.Redirect(http.StatusSeeOther, "/trash")
+    }).Wants("json", func(c buffalo.Context) error {
+        return c.Render(http.StatusOK, r.JSON(completion))
+    }).Wants("xml", func(c buffalo.Context) error {
+        return c.Render(http.StatusOK, r.XML(completion))
+    }).Respond(c)
+}
+
+// Empty purges every Completion in the User's trash. This function is mapped to
+// the path DELETE /trash
+func (v TrashResource) Empty(c buffalo.Context) error {
+    // Get the DB connection from the context
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    purged, err := models.EmptyTrash(tx, currentUser(c))
+    if err != nil {
+        return err
+    }
+
+    return responder.Wants("html", func(c buffalo.Context) error {
+        // If there are no errors set a flash message
+        c.Flash().Add("success", T.Translate(c, "trash.emptied.success"))
+
+        // Redirect to the now empty trash
+        return c.Redirect(http.StatusSeeOther, "/trash")
+    }).Wants("json", func(c buffalo.Context) error {
+        return c.Render(http.StatusOK, r.JSON(purgeResult{Purged: purged}))
+    }).Wants("xml", func(c buffalo.Context) error {
+        return c.Render(http.StatusOK, r.XML(purgeResult{Purged: purged}))
+    }).Respond(c)
+}
+
+// purgeResult reports how many completions emptying the trash purged.
+type purgeResult struct{
+    XMLName xml.Name `json:"-" xml:"trash"`
+    Purged  int      `json:"purged" xml:"purged"`
+}
@@ -0,0 +1,102 @@
+package actions
+
+import (
+  "net/http"
+
+  "completion_tracker/models"
+)
+
+func (as *ActionSuite) trash(completion *models.Completion) {
+  as.NoError(completion.Trash(as.DB))
+}
+
+func (as *ActionSuite) Test_TrashResource_List() {
+  dune := as.createBook("Dune", 206, 412)
+  as.trash(dune)
+  as.createBook("Emma", 10, 474)
+
+  res := as.HTML("/trash").Get()
+  as.Equal(http.StatusOK, res.Code)
+  as.Contains(res.Body.String(), "Dune")
+  as.NotContains(res.Body.String(), "Emma")
+
+  // Trashed completions are left out of the lists and pages
+  res = as.HTML("/books").Get()
+  as.Equal(http.StatusOK, res.Code)
+  as.NotContains(res.Body.String(), "Dune")
+  as.Contains(res.Body.String(), "Emma")
+
+  as.Equal(http.StatusNotFound, as.HTML("/books/%s", dune.ID).Get().Code)
+  as.Equal(http.StatusNotFound, as.HTML("/books/%s/edit", dune.ID).Get().Code)
+
+  completions := models.Completions{}
+  jres := as.JSON("/trash").Get()
+  as.Equal(http.StatusOK, jres.Code)
+  jres.Bind(&completions)
+  as.Len(completions, 1)
+  as.True(completions[0].IsTrashed())
+}
+
+func (as *ActionSuite) Test_TrashResource_Restore() {
+  book := as.createBook("Dune", 206, 412)
+  as.trash(book)
+
+  res := as.HTML("/trash/%s/restore", book.ID).Post(map[string]interface{}{})
+  as.Equal(http.StatusSeeOther, res.Code)
+  as.Equal("/books/"+book.ID.String(), res.Location())
+
+  as.Equal(http.StatusOK, as.HTML("/books/%s", book.ID).Get().Code)
+
+  // Only a trashed completion can be restored
+  as.Equal(http.StatusNotFound, as.HTML("/trash/%s/restore", book.ID).Post(map[string]interface{}{}).Code)
+}
+
+func (as *ActionSuite) Test_TrashResource_Purge() {
+  book := as.createBook("Dune", 206, 412)
+
+  // A completion has to be trashed before it can be purged
+  as.Equal(http.StatusNotFound, as.HTML("/trash/%s", book.ID).Delete().Code)
+
+  as.trash(book)
+  res := as.HTML("/trash/%s", book.ID).Delete()
+  as.Equal(http.StatusSeeOther, res.Code)
+  as.Equal("/trash", res.Location())
+
+  count, err := as.DB.Where("id = ?", book.ID).Count(&models.Completion{})
+  as.NoError(err)
+  as.Equal(0, count)
+}
+
+func (as *ActionSuite) Test_TrashResource_OtherUsers() {
+  book := as.createBook("Dune", 206, 412)
+  as.trash(book)
+
+  as.signIn(as.createUser("stranger@example.com"))
+
+  res := as.HTML("/trash").Get()
+  as.Equal(http.StatusOK, res.Code)
+  as.NotContains(res.Body.String(), "Dune")
+
+  as.Equal(http.StatusNotFound, as.HTML("/trash/%s/restore", book.ID).Post(map[string]interface{}{}).Code)
+  as.Equal(http.StatusNotFound, as.HTML("/trash/%s", book.ID).Delete().Code)
+
+  // Emptying their own trash leaves everyone else's alone
+  as.Equal(http.StatusOK, as.JSON("/trash").Delete().Code)
+  count, err := as.DB.Count(&models.Completion{})
+  as.NoError(err)
+  as.Equal(1, count)
+}
+
+func (as *ActionSuite) Test_TrashResource_Empty() {
+  as.trash(as.createBook("Dune", 206, 412))
+  as.trash(as.createEvent("Gophercon", models.AttendanceAttended))
+  as.createBook("Emma", 10, 474)
+
+  res := as.JSON("/trash").Delete()
+  as.Equal(http.StatusOK, res.Code)
+  as.Contains(res.Body.String(), `"purged":2`)
+
+  count, err := as.DB.Count(&models.Completion{})
+  as.NoError(err)
+  as.Equal(1, count)
+}
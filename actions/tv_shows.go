@@ -15,6 +15,25 @@ type TvShowsResource struct{
     buffalo.Resource
 }
 
+// keepEpisodeCounts puts back a TV Show's episodes watched, which are
+// counted from its season grid, and its total once it has episodes, so
+// neither is taken from a form. Other completions are left alone.
+func keepEpisodeCounts(tx *pop.Connection, completion *models.Completion, persisted models.Completion) error {
+    if persisted.Type != models.CompletionTypeTVShow {
+        return nil
+    }
+
+    episodes, err := persisted.EpisodeCount(tx)
+    if err != nil {
+        return err
+    }
+
+    completion.Completions = persisted.Completions
+    if episodes > 0 {
+        completion.Target = persisted.Target
+    }
+    return nil
+}
 
 // List gets all TV Show completions. This function is mapped to the path
 // GET /tv_shows
@@ -27,22 +46,38 @@ func (v TvShowsResource) List(c buffalo.Context) error {
 
     completions := &models.Completions{}
 
-    // Paginate results and filter by TV Show type
-    q := tx.PaginateFromParams(c.Params())
+    // The TV Show completions the User can see, leaving out the trash
+    q := tx.Scope(visibleCompletions(c))
     q = q.Where("type = ?", models.CompletionTypeTVShow)
 
-    // Retrieve all TV Show Completions from the DB
-    if err := q.All(completions); err != nil {
+    // Optionally filter by lifecycle status, rating, completion date and
+    // progress
+    q, err := filterCompletions(c, q)
+    if err != nil {
         return err
     }
 
     return responder.Wants("html", func(c buffalo.Context) error {
-        c.Set("pagination", q.Paginator)
+        // Sort and retrieve the page asked for by the "page" and
+        // "per_page" params
+        pagination, err := paginate(c, q, completions)
+        if err != nil {
+            return err
+        }
+        c.Set("pagination", pagination)
+        c.Set("statuses", models.GetStatuses())
         c.Set("completions", completions)
         return c.Render(http.StatusOK, r.HTML("tv_shows/index.plush.html"))
     }).Wants("json", func(c buffalo.Context) error {
+        // JSON clients page through by cursor
+        if err := paginateByCursor(c, q, completions); err != nil {
+            return err
+        }
         return c.Render(200, r.JSON(completions))
     }).Wants("xml", func(c buffalo.Context) error {
+        if _, err := paginate(c, q, completions); err != nil {
+            return err
+        }
         return c.Render(200, r.XML(completions))
     }).Respond(c)
 }
@@ -56,7 +91,7 @@ func (v TvShowsResource) Show(c buffalo.Context) error {
     }
 
     completion := &models.Completion{}
-    if err := tx.Find(completion, c.Param("tv_show_id")); err != nil {
+    if err := tx.Eager("StatusTransitions", "Runs", "Series", "Group", "Tags", "Seasons.Episodes").Scope(visibleCompletions(c)).Find(completion, c.Param("tv_show_id")); err != nil {
         return c.Error(http.StatusNotFound, err)
     }
 
@@ -65,6 +100,11 @@ func (v TvShowsResource) Show(c buffalo.Context) error {
         return c.Error(http.StatusNotFound, fmt.Errorf("completion is not a TV show"))
     }
 
+    // Only the latest changes, the rest are paged through at its revisions path
+    if err := loadRecentRevisions(tx, completion); err != nil {
+        return err
+    }
+
     return responder.Wants("html", func(c buffalo.Context) error {
         c.Set("completion", completion)
         return c.Render(http.StatusOK, r.HTML("tv_shows/show.plush.html"))
@@ -89,11 +129,19 @@ func (v TvShowsResource) Create(c buffalo.Context) error {
     // Ensure type is set correctly
     completion.Type = models.CompletionTypeTVShow
 
+    // Episodes watched are counted from the season grid, not typed in
+    completion.Completions = 0
+
     tx, ok := c.Value("tx").(*pop.Connection)
     if !ok {
         return fmt.Errorf("no transaction found")
     }
 
+    // It always belongs to the signed in User, whatever was posted
+    if err := setOwner(c, tx, completion); err != nil {
+        return err
+    }
+
     verrs, err := tx.ValidateAndCreate(completion)
     if err != nil {
         return err
@@ -103,9 +151,13 @@ func (v TvShowsResource) Create(c buffalo.Context) error {
         return responder.Wants("html", func(c buffalo.Context) error {
             c.Set("errors", verrs)
             c.Set("completion", completion)
+            c.Set("statuses", models.GetStatuses())
+            if err := setCompletionOptions(c); err != nil {
+                return err
+            }
             return c.Render(http.StatusUnprocessableEntity, r.HTML("tv_shows/new.plush.html"))
         }).Wants("json", func(c buffalo.Context) error {
-            return c.Render(http.StatusUnprocessableEntity, r.JSON(verrs))
+            return renderValidationErrors(c, verrs)
         }).Wants("xml", func(c buffalo.Context) error {
             return c.Render(http.StatusUnprocessableEntity, r.XML(verrs))
         }).Respond(c)
@@ -130,7 +182,7 @@ func (v TvShowsResource) Update(c buffalo.Context) error {
     }
 
     completion := &models.Completion{}
-    if err := tx.Find(completion, c.Param("tv_show_id")); err != nil {
+    if err := tx.Scope(editableCompletions(c)).Find(completion, c.Param("tv_show_id")); err != nil {
         return c.Error(http.StatusNotFound, err)
     }
 
@@ -138,6 +190,10 @@ func (v TvShowsResource) Update(c buffalo.Context) error {
         return c.Error(http.StatusNotFound, fmt.Errorf("completion is not a TV show"))
     }
 
+    // Remember which it is, and how it was shared, which only its owner
+    // can change
+    persisted := *completion
+
     if err := c.Bind(completion); err != nil {
         return err
     }
@@ -145,6 +201,19 @@ func (v TvShowsResource) Update(c buffalo.Context) error {
     // Ensure type remains TV Show
     completion.Type = models.CompletionTypeTVShow
 
+    // Its counts come from the season grid, not the form
+    if err := keepEpisodeCounts(tx, completion, persisted); err != nil {
+        return err
+    }
+
+    // It stays the Completion that was found, whatever id was posted
+    keepIdentity(completion, persisted)
+
+    // It keeps its owner, whoever edits it
+    if err := keepOwner(c, tx, completion, persisted); err != nil {
+        return err
+    }
+
     verrs, err := tx.ValidateAndUpdate(completion)
     if err != nil {
         return err
@@ -154,9 +223,13 @@ func (v TvShowsResource) Update(c buffalo.Context) error {
         return responder.Wants("html", func(c buffalo.Context) error {
             c.Set("errors", verrs)
             c.Set("completion", completion)
+            c.Set("statuses", models.GetStatuses())
+            if err := setCompletionOptions(c); err != nil {
+                return err
+            }
             return c.Render(http.StatusUnprocessableEntity, r.HTML("tv_shows/edit.plush.html"))
         }).Wants("json", func(c buffalo.Context) error {
-            return c.Render(http.StatusUnprocessableEntity, r.JSON(verrs))
+            return renderValidationErrors(c, verrs)
         }).Wants("xml", func(c buffalo.Context) error {
             return c.Render(http.StatusUnprocessableEntity, r.XML(verrs))
         }).Respond(c)
@@ -172,7 +245,7 @@ func (v TvShowsResource) Update(c buffalo.Context) error {
     }).Respond(c)
 }
 
-// Destroy deletes a TV Show completion from the DB. This function is mapped
+// Destroy moves a TV Show completion to the trash. This function is mapped
 // to the path DELETE /tv_shows/{tv_show_id}
 func (v TvShowsResource) Destroy(c buffalo.Context) error {
     tx, ok := c.Value("tx").(*pop.Connection)
@@ -181,7 +254,7 @@ func (v TvShowsResource) Destroy(c buffalo.Context) error {
     }
 
     completion := &models.Completion{}
-    if err := tx.Find(completion, c.Param("tv_show_id")); err != nil {
+    if err := tx.Scope(ownCompletions(c)).Find(completion, c.Param("tv_show_id")); err != nil {
         return c.Error(http.StatusNotFound, err)
     }
 
@@ -189,7 +262,7 @@ func (v TvShowsResource) Destroy(c buffalo.Context) error {
         return c.Error(http.StatusNotFound, fmt.Errorf("completion is not a TV show"))
     }
 
-    if err := tx.Destroy(completion); err != nil {
+    if err := completion.Trash(tx); err != nil {
         return err
     }
 
@@ -210,6 +283,10 @@ func (v TvShowsResource) New(c buffalo.Context) error {
         Type: models.CompletionTypeTVShow,
     }
     c.Set("completion", completion)
+    c.Set("statuses", models.GetStatuses())
+    if err := setCompletionOptions(c); err != nil {
+        return err
+    }
 
     return c.Render(http.StatusOK, r.HTML("tv_shows/new.plush.html"))
 }
@@ -223,7 +300,7 @@ func (v TvShowsResource) Edit(c buffalo.Context) error {
     }
 
     completion := &models.Completion{}
-    if err := tx.Find(completion, c.Param("tv_show_id")); err != nil {
+    if err := tx.Eager("Tags").Scope(editableCompletions(c)).Find(completion, c.Param("tv_show_id")); err != nil {
         return c.Error(http.StatusNotFound, err)
     }
 
@@ -232,6 +309,10 @@ func (v TvShowsResource) Edit(c buffalo.Context) error {
     }
 
     c.Set("completion", completion)
+    c.Set("statuses", models.GetStatuses())
+    if err := setCompletionOptions(c); err != nil {
+        return err
+    }
     return c.Render(http.StatusOK, r.HTML("tv_shows/edit.plush.html"))
 }
 
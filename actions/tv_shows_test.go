@@ -1,36 +1,111 @@
 package actions
 
 import (
-  "testing"
+  "net/http"
+  "time"
 
-  "github.com/stretchr/testify/require"
+  "completion_tracker/models"
+
+  "github.com/gobuffalo/nulls"
 )
 
+func (as *ActionSuite) createTvShow(name string, watched, episodes int) *models.Completion {
+  tvShow := &models.Completion{
+    Name:        name,
+    Type:        models.CompletionTypeTVShow,
+    Completions: watched,
+    Target:      episodes,
+    CompletedAt: time.Now(),
+    UserID:      nulls.NewUUID(as.user.ID),
+  }
+  verrs, err := as.DB.ValidateAndCreate(tvShow)
+  as.NoError(err)
+  as.False(verrs.HasAny())
+  return tvShow
+}
+
 func (as *ActionSuite) Test_TvShowsResource_List() {
-  as.Fail("Not Implemented!")
+  as.createTvShow("Severance", 6, 9)
+
+  res := as.HTML("/tv_shows").Get()
+  as.Equal(http.StatusOK, res.Code)
+  as.Contains(res.Body.String(), "Severance")
+  as.Contains(res.Body.String(), "width: 66%;")
+  as.Contains(res.Body.String(), "3 episodes to go")
+
+  xres := as.XML("/tv_shows").Get()
+  as.Equal(http.StatusOK, xres.Code)
+  as.Contains(xres.Body.String(), "<PercentComplete>66</PercentComplete>")
 }
 
 func (as *ActionSuite) Test_TvShowsResource_Show() {
-  as.Fail("Not Implemented!")
+  tvShow := as.createTvShow("Severance", 9, 9)
+
+  res := as.HTML("/tv_shows/%s", tvShow.ID).Get()
+  as.Equal(http.StatusOK, res.Code)
+  as.Contains(res.Body.String(), "Target reached")
+
+  jres := as.JSON("/tv_shows/%s", tvShow.ID).Get()
+  as.Equal(http.StatusOK, jres.Code)
+  as.Contains(jres.Body.String(), `"percent_complete":100`)
 }
 
 func (as *ActionSuite) Test_TvShowsResource_Create() {
-  as.Fail("Not Implemented!")
+  res := as.JSON("/tv_shows").Post(map[string]interface{}{
+    "name":         "Severance",
+    "completions":  3,
+    "target":       9,
+    "completed_at": time.Now(),
+  })
+  as.Equal(http.StatusCreated, res.Code)
+
+  tvShow := &models.Completion{}
+  as.NoError(as.DB.Where("name = ?", "Severance").First(tvShow))
+  as.Equal(models.CompletionTypeTVShow, tvShow.Type)
+  as.Equal(9, tvShow.Target)
+  as.Equal(0, tvShow.Completions)
 }
 
 func (as *ActionSuite) Test_TvShowsResource_Update() {
-  as.Fail("Not Implemented!")
+  tvShow := as.createTvShow("Severance", 3, 9)
+
+  res := as.JSON("/tv_shows/%s", tvShow.ID).Put(map[string]interface{}{
+    "name":         "Severance",
+    "completions":  12,
+    "target":       9,
+    "completed_at": tvShow.CompletedAt,
+  })
+  as.Equal(http.StatusOK, res.Code)
+
+  as.NoError(as.DB.Reload(tvShow))
+  as.Equal(3, tvShow.Completions)
+
+  res = as.JSON("/tv_shows/%s", tvShow.ID).Put(map[string]interface{}{
+    "name":         "Severance",
+    "target":       2,
+    "completed_at": tvShow.CompletedAt,
+  })
+  as.Equal(http.StatusUnprocessableEntity, res.Code)
 }
 
 func (as *ActionSuite) Test_TvShowsResource_Destroy() {
-  as.Fail("Not Implemented!")
+  tvShow := as.createTvShow("Severance", 3, 9)
+
+  res := as.HTML("/tv_shows/%s", tvShow.ID).Delete()
+  as.Equal(http.StatusSeeOther, res.Code)
+  as.Equal("/tv_shows", res.Location())
 }
 
 func (as *ActionSuite) Test_TvShowsResource_New() {
-  as.Fail("Not Implemented!")
+  res := as.HTML("/tv_shows/new").Get()
+  as.Equal(http.StatusOK, res.Code)
+  as.Contains(res.Body.String(), "Total Episodes")
 }
 
 func (as *ActionSuite) Test_TvShowsResource_Edit() {
-  as.Fail("Not Implemented!")
-}
+  tvShow := as.createTvShow("Severance", 3, 9)
 
+  res := as.HTML("/tv_shows/%s/edit", tvShow.ID).Get()
+  as.Equal(http.StatusOK, res.Code)
+  as.Contains(res.Body.String(), "Severance")
+}
@@ -0,0 +1,124 @@
+package actions
+
+import (
+    "fmt"
+    "net/http"
+
+    "completion_tracker/models"
+
+    "github.com/gobuffalo/buffalo"
+    "github.com/gobuffalo/pop/v6"
+    "github.com/gobuffalo/x/responder"
+)
+
+// UsersNew renders the signup form. This function is mapped to the path
+// GET /signup
+func UsersNew(c buffalo.Context) error {
+    c.Set("user", &models.User{})
+    return c.Render(http.StatusOK, r.HTML("users/new.plush.html"))
+}
+
+// UsersCreate signs a new User up and signs them in. This function is
+// mapped to the path POST /users
+func UsersCreate(c buffalo.Context) error {
+    // Only the email, username and password come from the form
+    form := &models.User{}
+    if err := c.Bind(form); err != nil {
+        return err
+    }
+    u := &models.User{
+        Email:                form.Email,
+        Username:             form.Username,
+        Password:             form.Password,
+        PasswordConfirmation: form.PasswordConfirmation,
+    }
+
+    // Get the DB connection from the context
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    // Validate the data from the html form
+    verrs, err := tx.ValidateAndCreate(u)
+    if err != nil {
+        return err
+    }
+
+    if verrs.HasAny() {
+        return responder.Wants("html", func(c buffalo.Context) error {
+            // Make the errors available inside the html template
+            c.Set("errors", verrs)
+
+            // Render again the new.html template that the user can
+            // correct the input, without the passwords.
+            u.Password, u.PasswordConfirmation = "", ""
+            c.Set("user", u)
+
+            return c.Render(http.StatusUnprocessableEntity, r.HTML("users/new.plush.html"))
+        }).Wants("json", func(c buffalo.Context) error {
+            return renderValidationErrors(c, verrs)
+        }).Wants("xml", func(c buffalo.Context) error {
+            return c.Render(http.StatusUnprocessableEntity, r.XML(verrs))
+        }).Respond(c)
+    }
+
+    return signIn(c, u)
+}
+
+// AccountEdit renders the form for the signed in User's account settings.
+// This function is mapped to the path GET /account
+func AccountEdit(c buffalo.Context) error {
+    c.Set("user", currentUser(c))
+    return c.Render(http.StatusOK, r.HTML("users/edit.plush.html"))
+}
+
+// AccountUpdate changes the signed in User's username, which names their
+// public profile. This function is mapped to the path PUT /account
+func AccountUpdate(c buffalo.Context) error {
+    u := currentUser(c)
+
+    // Only the username can be changed here
+    form := &models.User{}
+    if err := c.Bind(form); err != nil {
+        return err
+    }
+    u.Username = form.Username
+
+    // Get the DB connection from the context
+    tx, ok := c.Value("tx").(*pop.Connection)
+    if !ok {
+        return fmt.Errorf("no transaction found")
+    }
+
+    verrs, err := tx.ValidateAndUpdate(u)
+    if err != nil {
+        return err
+    }
+
+    if verrs.HasAny() {
+        return responder.Wants("html", func(c buffalo.Context) error {
+            // Make the errors available inside the html template
+            c.Set("errors", verrs)
+            c.Set("user", u)
+
+            return c.Render(http.StatusUnprocessableEntity, r.HTML("users/edit.plush.html"))
+        }).Wants("json", func(c buffalo.Context) error {
+            return renderValidationErrors(c, verrs)
+        }).Wants("xml", func(c buffalo.Context) error {
+            return c.Render(http.StatusUnprocessableEntity, r.XML(verrs))
+        }).Respond(c)
+    }
+
+    return responder.Wants("html", func(c buffalo.Context) error {
+        // If there are no errors set a success message
+        c.Flash().Add("success", T.Translate(c, "account.updated.success"))
+
+        // and redirect back to the settings
+        return c.Redirect(http.StatusSeeOther, "/account")
+    }).Wants("json", func(c buffalo.Context) error {
+        return c.Render(http.StatusOK, r.JSON(u))
+    }).Wants("xml", func(c buffalo.Context) error {
+        return c.Render(http.StatusOK, r.XML(u))
+    }).Respond(c)
+}
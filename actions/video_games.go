@@ -23,19 +23,38 @@ func (v VideoGamesResource) List(c buffalo.Context) error {
     }
 
     completions := &models.Completions{}
-    q := tx.PaginateFromParams(c.Params())
+    q := tx.Scope(visibleCompletions(c))
     q = q.Where("type = ?", models.CompletionTypeVideoGame)
 
-    if err := q.All(completions); err != nil {
+    // Optionally filter by lifecycle status, rating, completion date and
+    // progress
+    q, err := filterCompletions(c, q)
+    if err != nil {
         return err
     }
 
     return responder.Wants("html", func(c buffalo.Context) error {
-        c.Set("pagination", q.Paginator)
+        // Sort and retrieve the page asked for by the "page" and
+        // "per_page" params
+        pagination, err := paginate(c, q, completions)
+        if err != nil {
+            return err
+        }
+        c.Set("pagination", pagination)
+        c.Set("statuses", models.GetStatuses())
         c.Set("completions", completions)
         return c.Render(http.StatusOK, r.HTML("video_games/index.plush.html"))
     }).Wants("json", func(c buffalo.Context) error {
+        // JSON clients page through by cursor
+        if err := paginateByCursor(c, q, completions); err != nil {
+            return err
+        }
         return c.Render(200, r.JSON(completions))
+    }).Wants("xml", func(c buffalo.Context) error {
+        if _, err := paginate(c, q, completions); err != nil {
+            return err
+        }
+        return c.Render(200, r.XML(completions))
     }).Respond(c)
 }
 
@@ -47,7 +66,7 @@ func (v VideoGamesResource) Show(c buffalo.Context) error {
     }
 
     completion := &models.Completion{}
-    if err := tx.Find(completion, c.Param("video_game_id")); err != nil {
+    if err := tx.Eager("StatusTransitions", "Runs", "Series", "Group", "Tags").Scope(visibleCompletions(c)).Find(completion, c.Param("video_game_id")); err != nil {
         return c.Error(http.StatusNotFound, err)
     }
 
@@ -55,11 +74,18 @@ func (v VideoGamesResource) Show(c buffalo.Context) error {
         return c.Error(http.StatusNotFound, fmt.Errorf("completion is not a video game"))
     }
 
+    // Only the latest changes, the rest are paged through at its revisions path
+    if err := loadRecentRevisions(tx, completion); err != nil {
+        return err
+    }
+
     return responder.Wants("html", func(c buffalo.Context) error {
         c.Set("completion", completion)
         return c.Render(http.StatusOK, r.HTML("video_games/show.plush.html"))
     }).Wants("json", func(c buffalo.Context) error {
         return c.Render(200, r.JSON(completion))
+    }).Wants("xml", func(c buffalo.Context) error {
+        return c.Render(200, r.XML(completion))
     }).Respond(c)
 }
 
@@ -69,6 +95,10 @@ func (v VideoGamesResource) New(c buffalo.Context) error {
         Type: models.CompletionTypeVideoGame,
     }
     c.Set("completion", completion)
+    c.Set("statuses", models.GetStatuses())
+    if err := setCompletionOptions(c); err != nil {
+        return err
+    }
     return c.Render(http.StatusOK, r.HTML("video_games/new.plush.html"))
 }
 
@@ -88,19 +118,40 @@ func (v VideoGamesResource) Create(c buffalo.Context) error {
         return fmt.Errorf("no transaction found")
     }
 
+    // It always belongs to the signed in User, whatever was posted
+    if err := setOwner(c, tx, completion); err != nil {
+        return err
+    }
+
     verrs, err := tx.ValidateAndCreate(completion)
     if err != nil {
         return err
     }
 
     if verrs.HasAny() {
-        c.Set("errors", verrs)
-        c.Set("completion", completion)
-        return c.Render(http.StatusUnprocessableEntity, r.HTML("video_games/new.plush.html"))
+        return responder.Wants("html", func(c buffalo.Context) error {
+            c.Set("errors", verrs)
+            c.Set("completion", completion)
+            c.Set("statuses", models.GetStatuses())
+            if err := setCompletionOptions(c); err != nil {
+                return err
+            }
+            return c.Render(http.StatusUnprocessableEntity, r.HTML("video_games/new.plush.html"))
+        }).Wants("json", func(c buffalo.Context) error {
+            return renderValidationErrors(c, verrs)
+        }).Wants("xml", func(c buffalo.Context) error {
+            return c.Render(http.StatusUnprocessableEntity, r.XML(verrs))
+        }).Respond(c)
     }
 
-    c.Flash().Add("success", T.Translate(c, "completion.created.success"))
-    return c.Redirect(http.StatusSeeOther, "/video_games/%v", completion.ID)
+    return responder.Wants("html", func(c buffalo.Context) error {
+        c.Flash().Add("success", T.Translate(c, "completion.created.success"))
+        return c.Redirect(http.StatusSeeOther, "/video_games/%v", completion.ID)
+    }).Wants("json", func(c buffalo.Context) error {
+        return c.Render(http.StatusCreated, r.JSON(completion))
+    }).Wants("xml", func(c buffalo.Context) error {
+        return c.Render(http.StatusCreated, r.XML(completion))
+    }).Respond(c)
 }
 
 // Edit renders a edit form for a Video Game completion
@@ -111,7 +162,7 @@ func (v VideoGamesResource) Edit(c buffalo.Context) error {
     }
 
     completion := &models.Completion{}
-    if err := tx.Find(completion, c.Param("video_game_id")); err != nil {
+    if err := tx.Eager("Tags").Scope(editableCompletions(c)).Find(completion, c.Param("video_game_id")); err != nil {
         return c.Error(http.StatusNotFound, err)
     }
 
@@ -120,6 +171,10 @@ func (v VideoGamesResource) Edit(c buffalo.Context) error {
     }
 
     c.Set("completion", completion)
+    c.Set("statuses", models.GetStatuses())
+    if err := setCompletionOptions(c); err != nil {
+        return err
+    }
     return c.Render(http.StatusOK, r.HTML("video_games/edit.plush.html"))
 }
 
@@ -131,7 +186,7 @@ func (v VideoGamesResource) Update(c buffalo.Context) error {
     }
 
     completion := &models.Completion{}
-    if err := tx.Find(completion, c.Param("video_game_id")); err != nil {
+    if err := tx.Scope(editableCompletions(c)).Find(completion, c.Param("video_game_id")); err != nil {
         return c.Error(http.StatusNotFound, err)
     }
 
@@ -139,27 +194,55 @@ func (v VideoGamesResource) Update(c buffalo.Context) error {
         return c.Error(http.StatusNotFound, fmt.Errorf("completion is not a video game"))
     }
 
+    // Remember which it is, and how it was shared, which only its owner
+    // can change
+    persisted := *completion
+
     if err := c.Bind(completion); err != nil {
         return err
     }
     completion.Type = models.CompletionTypeVideoGame
 
+    // It stays the Completion that was found, whatever id was posted
+    keepIdentity(completion, persisted)
+
+    // It keeps its owner, whoever edits it
+    if err := keepOwner(c, tx, completion, persisted); err != nil {
+        return err
+    }
+
     verrs, err := tx.ValidateAndUpdate(completion)
     if err != nil {
         return err
     }
 
     if verrs.HasAny() {
-        c.Set("errors", verrs)
-        c.Set("completion", completion)
-        return c.Render(http.StatusUnprocessableEntity, r.HTML("video_games/edit.plush.html"))
+        return responder.Wants("html", func(c buffalo.Context) error {
+            c.Set("errors", verrs)
+            c.Set("completion", completion)
+            c.Set("statuses", models.GetStatuses())
+            if err := setCompletionOptions(c); err != nil {
+                return err
+            }
+            return c.Render(http.StatusUnprocessableEntity, r.HTML("video_games/edit.plush.html"))
+        }).Wants("json", func(c buffalo.Context) error {
+            return renderValidationErrors(c, verrs)
+        }).Wants("xml", func(c buffalo.Context) error {
+            return c.Render(http.StatusUnprocessableEntity, r.XML(verrs))
+        }).Respond(c)
     }
 
-    c.Flash().Add("success", T.Translate(c, "completion.updated.success"))
-    return c.Redirect(http.StatusSeeOther, "/video_games/%v", completion.ID)
+    return responder.Wants("html", func(c buffalo.Context) error {
+        c.Flash().Add("success", T.Translate(c, "completion.updated.success"))
+        return c.Redirect(http.StatusSeeOther, "/video_games/%v", completion.ID)
+    }).Wants("json", func(c buffalo.Context) error {
+        return c.Render(http.StatusOK, r.JSON(completion))
+    }).Wants("xml", func(c buffalo.Context) error {
+        return c.Render(http.StatusOK, r.XML(completion))
+    }).Respond(c)
 }
 
-// Destroy deletes a Video Game completion from the DB
+// Destroy moves a Video Game completion to the trash
 func (v VideoGamesResource) Destroy(c buffalo.Context) error {
     tx, ok := c.Value("tx").(*pop.Connection)
     if !ok {
@@ -167,7 +250,7 @@ func (v VideoGamesResource) Destroy(c buffalo.Context) error {
     }
 
     completion := &models.Completion{}
-    if err := tx.Find(completion, c.Param("video_game_id")); err != nil {
+    if err := tx.Scope(ownCompletions(c)).Find(completion, c.Param("video_game_id")); err != nil {
         return c.Error(http.StatusNotFound, err)
     }
 
@@ -175,11 +258,17 @@ func (v VideoGamesResource) Destroy(c buffalo.Context) error {
         return c.Error(http.StatusNotFound, fmt.Errorf("completion is not a video game"))
     }
 
-    if err := tx.Destroy(completion); err != nil {
+    if err := completion.Trash(tx); err != nil {
         return err
     }
 
-    c.Flash().Add("success", T.Translate(c, "completion.destroyed.success"))
-    return c.Redirect(http.StatusSeeOther, "/video_games")
+    return responder.Wants("html", func(c buffalo.Context) error {
+        c.Flash().Add("success", T.Translate(c, "completion.destroyed.success"))
+        return c.Redirect(http.StatusSeeOther, "/video_games")
+    }).Wants("json", func(c buffalo.Context) error {
+        return c.Render(http.StatusOK, r.JSON(completion))
+    }).Wants("xml", func(c buffalo.Context) error {
+        return c.Render(http.StatusOK, r.XML(completion))
+    }).Respond(c)
 }
 
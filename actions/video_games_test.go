@@ -1,36 +1,119 @@
 package actions
 
 import (
-  "testing"
+  "net/http"
+  "time"
 
-  "github.com/stretchr/testify/require"
+  "completion_tracker/models"
+
+  "github.com/gobuffalo/nulls"
 )
 
+func (as *ActionSuite) createVideoGame(name string, hours, target int) *models.Completion {
+  videoGame := &models.Completion{
+    Name:        name,
+    Type:        models.CompletionTypeVideoGame,
+    Completions: hours,
+    Target:      target,
+    CompletedAt: time.Now(),
+    UserID:      nulls.NewUUID(as.user.ID),
+  }
+  verrs, err := as.DB.ValidateAndCreate(videoGame)
+  as.NoError(err)
+  as.False(verrs.HasAny())
+  return videoGame
+}
+
 func (as *ActionSuite) Test_VideoGamesResource_List() {
-  as.Fail("Not Implemented!")
+  as.createVideoGame("Hades", 30, 40)
+
+  res := as.HTML("/video_games").Get()
+  as.Equal(http.StatusOK, res.Code)
+  as.Contains(res.Body.String(), "Hades")
+  as.Contains(res.Body.String(), "10 hours to go")
+
+  xres := as.XML("/video_games").Get()
+  as.Equal(http.StatusOK, xres.Code)
+  as.Contains(xres.Body.String(), "Hades")
 }
 
 func (as *ActionSuite) Test_VideoGamesResource_Show() {
-  as.Fail("Not Implemented!")
+  videoGame := as.createVideoGame("Hades", 30, 40)
+
+  res := as.HTML("/video_games/%s", videoGame.ID).Get()
+  as.Equal(http.StatusOK, res.Code)
+  as.Contains(res.Body.String(), "75%")
+
+  jres := as.JSON("/video_games/%s", videoGame.ID).Get()
+  as.Equal(http.StatusOK, jres.Code)
+  as.Contains(jres.Body.String(), `"remaining":10`)
+
+  xres := as.XML("/video_games/%s", videoGame.ID).Get()
+  as.Equal(http.StatusOK, xres.Code)
+  as.Contains(xres.Body.String(), "Hades")
 }
 
 func (as *ActionSuite) Test_VideoGamesResource_Create() {
-  as.Fail("Not Implemented!")
+  res := as.HTML("/video_games").Post(map[string]interface{}{
+    "Name":        "Hades",
+    "Completions": 12,
+    "Target":      40,
+    "CompletedAt": time.Now().Format("2006-01-02T15:04"),
+  })
+  as.Equal(http.StatusSeeOther, res.Code)
+
+  videoGame := &models.Completion{}
+  as.NoError(as.DB.Where("name = ?", "Hades").First(videoGame))
+  as.Equal(models.CompletionTypeVideoGame, videoGame.Type)
+  as.Equal(40, videoGame.Target)
+
+  jres := as.JSON("/video_games").Post(map[string]interface{}{
+    "name":         "Celeste",
+    "completions":  3,
+    "target":       10,
+    "completed_at": time.Now(),
+  })
+  as.Equal(http.StatusCreated, jres.Code)
+  as.Contains(jres.Body.String(), `"type":"Video Game"`)
+
+  jres = as.JSON("/video_games").Post(map[string]interface{}{"name": ""})
+  as.Equal(http.StatusUnprocessableEntity, jres.Code)
+  as.Contains(jres.Body.String(), "name")
 }
 
 func (as *ActionSuite) Test_VideoGamesResource_Update() {
-  as.Fail("Not Implemented!")
+  videoGame := as.createVideoGame("Hades", 12, 40)
+
+  res := as.HTML("/video_games/%s", videoGame.ID).Put(map[string]interface{}{
+    "Name":        "Hades",
+    "Completions": 40,
+    "Target":      40,
+    "CompletedAt": videoGame.CompletedAt.Format("2006-01-02T15:04"),
+  })
+  as.Equal(http.StatusSeeOther, res.Code)
+
+  as.NoError(as.DB.Reload(videoGame))
+  as.Equal(100, videoGame.PercentComplete())
 }
 
 func (as *ActionSuite) Test_VideoGamesResource_Destroy() {
-  as.Fail("Not Implemented!")
+  videoGame := as.createVideoGame("Hades", 12, 40)
+
+  res := as.HTML("/video_games/%s", videoGame.ID).Delete()
+  as.Equal(http.StatusSeeOther, res.Code)
+  as.Equal("/video_games", res.Location())
 }
 
 func (as *ActionSuite) Test_VideoGamesResource_New() {
-  as.Fail("Not Implemented!")
+  res := as.HTML("/video_games/new").Get()
+  as.Equal(http.StatusOK, res.Code)
+  as.Contains(res.Body.String(), "Target Hours")
 }
 
 func (as *ActionSuite) Test_VideoGamesResource_Edit() {
-  as.Fail("Not Implemented!")
-}
+  videoGame := as.createVideoGame("Hades", 12, 40)
 
+  res := as.HTML("/video_games/%s/edit", videoGame.ID).Get()
+  as.Equal(http.StatusOK, res.Code)
+  as.Contains(res.Body.String(), "Hades")
+}
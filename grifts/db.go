@@ -1,6 +1,8 @@
 package grifts
 
 import (
+	"completion_tracker/models"
+
 	"github.com/gobuffalo/grift/grift"
 )
 
@@ -8,8 +10,8 @@ var _ = grift.Namespace("db", func() {
 
 	grift.Desc("seed", "Seeds a database")
 	grift.Add("seed", func(c *grift.Context) error {
-		// Add DB seeding stuff here
-		return nil
+		// Put back any built-in completion type that has gone missing
+		return models.SeedCompletionTypes(models.DB)
 	})
 
 })
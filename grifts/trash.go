@@ -0,0 +1,39 @@
+package grifts
+
+import (
+	"fmt"
+	"time"
+
+	"completion_tracker/models"
+
+	"github.com/gobuffalo/envy"
+	"github.com/gobuffalo/grift/grift"
+)
+
+var _ = grift.Namespace("trash", func() {
+
+	grift.Desc("purge", "Deletes completions that have been in the trash for longer than a given age, e.g. 'buffalo task trash:purge 168h'. The age defaults to TRASH_MAX_AGE, or 720h (30 days).")
+	grift.Add("purge", func(c *grift.Context) error {
+		age := envy.Get("TRASH_MAX_AGE", "720h")
+		if len(c.Args) > 0 {
+			age = c.Args[0]
+		}
+
+		olderThan, err := time.ParseDuration(age)
+		if err != nil {
+			return fmt.Errorf("invalid trash age %q: %w", age, err)
+		}
+		// A negative age would reach into the future and purge everything
+		if olderThan < 0 {
+			return fmt.Errorf("invalid trash age %q: must not be negative", age)
+		}
+
+		purged, err := models.PurgeTrash(models.DB, olderThan)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Purged %d completions from the trash\n", purged)
+		return nil
+	})
+
+})
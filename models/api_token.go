@@ -0,0 +1,119 @@
+package models
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gobuffalo/nulls"
+	"github.com/gobuffalo/pop/v6"
+	"github.com/gobuffalo/validate/v3"
+	"github.com/gobuffalo/validate/v3/validators"
+	"github.com/gofrs/uuid"
+)
+
+// APITokenPrefix starts every personal access token, so that a leaked one
+// is easy to recognise.
+const APITokenPrefix = "ct_"
+
+// ErrInvalidToken is returned by AuthenticateToken for a token that was
+// never issued or has been revoked.
+var ErrInvalidToken = errors.New("invalid or revoked token")
+
+// APIToken is a personal access token that lets a User's scripts call the
+// JSON and XML API without a browser session. Only a SHA-256 hash of the
+// token is stored; Token holds it just after it is created, which is the
+// only time it can be shown. Revoking a token deletes it.
+type APIToken struct {
+	ID         uuid.UUID  `json:"id" db:"id"`
+	UserID     uuid.UUID  `json:"-" xml:"-" db:"user_id"`
+	Name       string     `json:"name" db:"name"`
+	TokenHash  string     `json:"-" xml:"-" db:"token_hash"`
+	LastUsedAt nulls.Time `json:"last_used_at" db:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at" db:"updated_at"`
+
+	Token string `json:"token,omitempty" xml:"Token,omitempty" db:"-" form:"-"`
+}
+
+// TableName overrides the table name pop would derive from APIToken.
+func (t APIToken) TableName() string {
+	return "api_tokens"
+}
+
+// String is not required by pop and may be deleted
+func (t APIToken) String() string {
+	jt, _ := json.Marshal(t)
+	return string(jt)
+}
+
+// APITokens is not required by pop and may be deleted
+type APITokens []APIToken
+
+// String is not required by pop and may be deleted
+func (t APITokens) String() string {
+	jt, _ := json.Marshal(t)
+	return string(jt)
+}
+
+// Validate gets run every time you call a "pop.Validate*" (pop.ValidateAndSave, pop.ValidateAndCreate, pop.ValidateAndUpdate) method.
+func (t *APIToken) Validate(tx *pop.Connection) (*validate.Errors, error) {
+	return validate.Validate(
+		&validators.StringIsPresent{Field: t.Name, Name: "Name"},
+		&validators.StringLengthInRange{Field: t.Name, Name: "Name", Max: 100},
+	), nil
+}
+
+// BeforeValidate trims the Name.
+func (t *APIToken) BeforeValidate(tx *pop.Connection) error {
+	t.Name = strings.TrimSpace(t.Name)
+	return nil
+}
+
+// BeforeCreate generates the Token and keeps its hash.
+func (t *APIToken) BeforeCreate(tx *pop.Connection) error {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return err
+	}
+	t.Token = APITokenPrefix + base64.RawURLEncoding.EncodeToString(b)
+	t.TokenHash = hashToken(t.Token)
+	return nil
+}
+
+// hashToken is how a token is stored and looked up. Tokens are long and
+// random, so a fast unsalted hash is enough to keep them secret.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// AuthenticateToken loads the User that token belongs to, and records
+// that the token was used.
+func AuthenticateToken(tx *pop.Connection, token string) (*User, error) {
+	t := &APIToken{}
+	err := tx.Where("token_hash = ?", hashToken(token)).First(t)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrInvalidToken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	t.LastUsedAt = nulls.NewTime(time.Now())
+	if err := tx.UpdateColumns(t, "last_used_at"); err != nil {
+		return nil, err
+	}
+
+	u := &User{}
+	if err := tx.Find(u, t.UserID); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
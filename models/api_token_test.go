@@ -0,0 +1,41 @@
+package models
+
+import "strings"
+
+func (ms *ModelSuite) Test_APIToken_Create() {
+	u := ms.createUser("reader@example.com")
+
+	t := &APIToken{UserID: u.ID, Name: "  backup script "}
+	verrs, err := ms.DB.ValidateAndCreate(t)
+	ms.NoError(err)
+	ms.False(verrs.HasAny())
+	ms.Equal("backup script", t.Name)
+	ms.True(strings.HasPrefix(t.Token, APITokenPrefix))
+	ms.Equal(hashToken(t.Token), t.TokenHash)
+
+	verrs, err = ms.DB.ValidateAndCreate(&APIToken{UserID: u.ID})
+	ms.NoError(err)
+	ms.NotEmpty(verrs.Get("name"))
+}
+
+func (ms *ModelSuite) Test_AuthenticateToken() {
+	u := ms.createUser("reader@example.com")
+	t := &APIToken{UserID: u.ID, Name: "backup script"}
+	ms.NoError(ms.DB.Create(t))
+	token := t.Token
+
+	found, err := AuthenticateToken(ms.DB, token)
+	ms.NoError(err)
+	ms.Equal(u.ID, found.ID)
+
+	ms.NoError(ms.DB.Reload(t))
+	ms.True(t.LastUsedAt.Valid)
+
+	_, err = AuthenticateToken(ms.DB, APITokenPrefix+"nope")
+	ms.ErrorIs(err, ErrInvalidToken)
+
+	// Revoking a token deletes it
+	ms.NoError(ms.DB.Destroy(t))
+	_, err = AuthenticateToken(ms.DB, token)
+	ms.ErrorIs(err, ErrInvalidToken)
+}
@@ -2,15 +2,26 @@ package models
 
 import (
 	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"html/template"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/gobuffalo/github_flavored_markdown"
+	"github.com/gobuffalo/nulls"
 	"github.com/gobuffalo/pop/v6"
 	"github.com/gobuffalo/validate/v3"
 	"github.com/gobuffalo/validate/v3/validators"
 	"github.com/gofrs/uuid"
 )
 
-// CompletionType represents the different types of completions
+// CompletionType is the name of the kind of thing a Completion is. The
+// types themselves are TypeDefinitions stored in the database; these are
+// the built-in ones that have pages of their own.
 type CompletionType string
 
 const (
@@ -21,26 +32,278 @@ const (
 	CompletionTypeEvent     CompletionType = "Event"
 )
 
-// GetCompletionTypes returns all available completion types
-func GetCompletionTypes() []CompletionType {
-	return []CompletionType{
-		CompletionTypeTVShow,
-		CompletionTypeVideoGame,
-		CompletionTypeBook,
-		CompletionTypeAudioBook,
-		CompletionTypeEvent,
+// GetCompletionTypes returns all loaded completion types, the built-in
+// ones first and then the rest by name.
+func GetCompletionTypes() TypeDefinitions {
+	typeDefinitionsMu.RLock()
+	defs := make(TypeDefinitions, 0, len(typeDefinitions))
+	for _, d := range typeDefinitions {
+		defs = append(defs, d)
+	}
+	typeDefinitionsMu.RUnlock()
+
+	sort.Slice(defs, func(i, j int) bool {
+		if defs[i].BuiltIn != defs[j].BuiltIn {
+			return defs[i].BuiltIn
+		}
+		return defs[i].Name < defs[j].Name
+	})
+	return defs
+}
+
+// Units returns the singular and plural names of the units that progress
+// is counted in for the type. Audio books count minutes, but they are
+// written as durations rather than with a unit name.
+func (t CompletionType) Units() (singular, plural string) {
+	if d, ok := lookupTypeDefinition(t); ok {
+		return d.UnitSingular, d.UnitPlural
+	}
+	return "completion", "completions"
+}
+
+// Label is the type's name after its icon, if it has one.
+func (t CompletionType) Label() string {
+	if d, ok := lookupTypeDefinition(t); ok && d.Icon != "" {
+		return d.Icon + " " + string(t)
+	}
+	return string(t)
+}
+
+// MaxRating is the most stars a Completion can be rated.
+const MaxRating = 5
+
+// GetRatings returns every Rating a Completion can be given, from no
+// stars up to MaxRating in half stars.
+func GetRatings() []float64 {
+	ratings := make([]float64, 0, MaxRating*2+1)
+	for halves := 0; halves <= MaxRating*2; halves++ {
+		ratings = append(ratings, float64(halves)/2)
+	}
+	return ratings
+}
+
+// IsValidRating reports whether rating is a whole or half number of stars
+// between 0 and MaxRating.
+func IsValidRating(rating float64) bool {
+	return rating >= 0 && rating <= MaxRating && rating*2 == math.Trunc(rating*2)
+}
+
+// AttendanceStatus records whether an Event was attended
+type AttendanceStatus string
+
+const (
+	AttendanceRegistered AttendanceStatus = "registered"
+	AttendanceAttended   AttendanceStatus = "attended"
+	AttendanceMissed     AttendanceStatus = "missed"
+)
+
+// GetAttendanceStatuses returns all available attendance statuses
+func GetAttendanceStatuses() []AttendanceStatus {
+	return []AttendanceStatus{
+		AttendanceRegistered,
+		AttendanceAttended,
+		AttendanceMissed,
 	}
 }
 
 // Completion is used by pop to map your completions database table to your go code.
+//
+// Completions counts progress in the unit of the completion's type:
+// episodes, hours, pages, or minutes listened for audio books. An Event
+// counts one completion once it has been attended. Target is the optional
+// total in the same unit, such as the pages in a book; zero means unknown.
+//
+// Rating is an optional score from 0 to MaxRating stars in half stars, and
+// Review is a write-up of it in GitHub flavored markdown.
+//
+// Status moves through the lifecycle allowed by Status.CanTransitionTo,
+// and every change is recorded as a StatusTransition.
+//
+// Creating, updating, trashing and restoring a Completion each record a
+// Revision of the fields that changed and who changed them.
+//
+// Every Completion belongs to the User who created it, and is only shown
+// to them unless its Privacy shares it: an unlisted Completion can be
+// seen by anyone with its link, and a public one is also listed on the
+// User's profile.
+//
+// A Completion can also be shared with a Group through its GroupID, so
+// that every member can see it and the Group's editors can update it.
+//
+// A Completion is worked through in one or more Runs, so a reread or
+// replay keeps the history of earlier passes. Completions is the progress
+// of the latest Run, summed from its ProgressEntries, and CompletedAt
+// follows the latest of them. Changing Completions directly logs the
+// difference as a new entry, so the two always agree.
 type Completion struct {
-	ID          uuid.UUID      `json:"id" db:"id"`
-	Name        string         `json:"name" db:"name"`
-	Type        CompletionType `json:"type" db:"type"`
-	Completions int            `json:"completions" db:"completions"`
-	CompletedAt time.Time      `json:"completed_at" db:"completed_at"`
-	CreatedAt   time.Time      `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time      `json:"updated_at" db:"updated_at"`
+	ID          uuid.UUID        `json:"id" db:"id"`
+	Name        string           `json:"name" db:"name"`
+	Type        CompletionType   `json:"type" db:"type"`
+	Completions int              `json:"completions" db:"completions"`
+	Target      int              `json:"target" db:"target"`
+	ScheduledAt nulls.Time       `json:"scheduled_at" db:"scheduled_at"`
+	Venue       string           `json:"venue" db:"venue"`
+	Attendance  AttendanceStatus `json:"attendance" db:"attendance"`
+	Companions  string           `json:"companions" db:"companions"`
+	Status      Status           `json:"status" db:"status"`
+	CompletedAt time.Time        `json:"completed_at" db:"completed_at"`
+	CreatedAt   time.Time        `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at" db:"updated_at"`
+
+	Rating nulls.Float64 `json:"rating" db:"rating"`
+	Review string        `json:"review" db:"review"`
+
+	// DeletedAt is when the Completion was moved to the trash. A trashed
+	// Completion is left out of every list and page until it is restored
+	// or purged.
+	DeletedAt nulls.Time `json:"deleted_at" db:"deleted_at"`
+
+	// UserID is the User the Completion belongs to. It is only empty for
+	// completions recorded before there were accounts, until the first
+	// User signs up and claims them.
+	UserID nulls.UUID `json:"user_id" db:"user_id"`
+
+	Privacy Privacy `json:"privacy" db:"privacy"`
+
+	GroupID nulls.UUID `json:"group_id" db:"group_id"`
+	Group   *Group     `json:"group,omitempty" db:"-" belongs_to:"groups"`
+
+	SeriesID       nulls.UUID `json:"series_id" db:"series_id"`
+	SeriesPosition int        `json:"series_position" db:"series_position"`
+	Series         *Series    `json:"series,omitempty" db:"-" belongs_to:"series"`
+
+	StatusTransitions StatusTransitions `json:"status_transitions,omitempty" db:"-" has_many:"status_transitions" order_by:"created_at asc"`
+
+	ProgressEntries ProgressEntries `json:"progress_entries,omitempty" db:"-" has_many:"progress_entries" order_by:"logged_at desc"`
+
+	Runs Runs `json:"runs,omitempty" db:"-" has_many:"runs" order_by:"number asc"`
+
+	Seasons Seasons `json:"seasons,omitempty" db:"-" has_many:"seasons" order_by:"number asc"`
+
+	Tags Tags `json:"tags,omitempty" db:"-" many_to_many:"completion_tags" order_by:"name asc"`
+
+	Revisions Revisions `json:"revisions,omitempty" db:"-" has_many:"revisions" order_by:"created_at desc"`
+
+	// TagList is the comma separated tag names bound from a form or
+	// request. When it is given, saving the Completion replaces its Tags
+	// with them.
+	TagList *string `json:"tag_list,omitempty" xml:"-" db:"-" form:"TagList"`
+
+	// previousStatus and previousCompletions are the persisted values
+	// before an update, and previousFields all of those it records in a
+	// Revision.
+	previousStatus      Status          `db:"-"`
+	previousCompletions int             `db:"-"`
+	previousFields      []revisionField `db:"-"`
+}
+
+// completionAlias has Completion's fields without its methods, so it can
+// be marshaled without recursing into MarshalJSON or MarshalXML.
+type completionAlias Completion
+
+// completionView is the JSON and XML representation of a Completion,
+// including the progress figures derived from its Target.
+type completionView struct {
+	completionAlias
+	PercentComplete int `json:"percent_complete"`
+	Remaining       int `json:"remaining"`
+
+	// Rating replaces the embedded nulls.Float64 so that it is written
+	// as a plain number, or left out of the XML when unrated.
+	Rating *float64 `json:"rating" xml:"Rating,omitempty"`
+}
+
+func (c Completion) view() completionView {
+	v := completionView{
+		completionAlias: completionAlias(c),
+		PercentComplete: c.PercentComplete(),
+		Remaining:       c.Remaining(),
+	}
+	if c.Rating.Valid {
+		v.Rating = &c.Rating.Float64
+	}
+	return v
+}
+
+// MarshalJSON includes the derived progress figures in the JSON output.
+func (c Completion) MarshalJSON() ([]byte, error) {
+	return json.Marshal(c.view())
+}
+
+// MarshalXML includes the derived progress figures in the XML output.
+func (c Completion) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return e.EncodeElement(c.view(), start)
+}
+
+// HasTarget reports whether the Completion has a known total to work towards.
+func (c Completion) HasTarget() bool {
+	return c.Target > 0
+}
+
+// PercentComplete returns progress towards the Target as a whole percentage
+// between 0 and 100. It is 0 when there is no Target.
+func (c Completion) PercentComplete() int {
+	if !c.HasTarget() {
+		return 0
+	}
+	if c.Completions >= c.Target {
+		return 100
+	}
+	return c.Completions * 100 / c.Target
+}
+
+// Remaining returns how many units are left before the Target is reached.
+// It is 0 when there is no Target or the Target has been reached.
+func (c Completion) Remaining() int {
+	if !c.HasTarget() || c.Completions >= c.Target {
+		return 0
+	}
+	return c.Target - c.Completions
+}
+
+// FormatUnits formats n units of the Completion's type for display,
+// such as "12 episodes", or "1:30" for an audio book.
+func (c Completion) FormatUnits(n int) string {
+	if c.Type == CompletionTypeAudioBook {
+		return Duration(n).String()
+	}
+
+	singular, plural := c.Type.Units()
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, singular)
+	}
+	return fmt.Sprintf("%d %s", n, plural)
+}
+
+// IsRated reports whether the Completion has been given a Rating.
+func (c Completion) IsRated() bool {
+	return c.Rating.Valid
+}
+
+// Stars describes each of the MaxRating stars of the Completion's Rating
+// as "full", "half" or "empty". It is empty when the Completion is unrated.
+func (c Completion) Stars() []string {
+	if !c.IsRated() {
+		return nil
+	}
+
+	stars := make([]string, 0, MaxRating)
+	for i := 1.0; i <= MaxRating; i++ {
+		switch {
+		case c.Rating.Float64 >= i:
+			stars = append(stars, "full")
+		case c.Rating.Float64 >= i-0.5:
+			stars = append(stars, "half")
+		default:
+			stars = append(stars, "empty")
+		}
+	}
+	return stars
+}
+
+// ReviewHTML renders the Review's markdown as sanitized HTML.
+func (c Completion) ReviewHTML() template.HTML {
+	return template.HTML(github_flavored_markdown.Markdown([]byte(c.Review)))
 }
 
 // String is not required by pop and may be deleted
@@ -49,6 +312,22 @@ func (c Completion) String() string {
 	return string(jc)
 }
 
+// IsTrashed reports whether the Completion is in the trash.
+func (c Completion) IsTrashed() bool {
+	return c.DeletedAt.Valid
+}
+
+// CompanionList splits the comma separated Companions of an Event.
+func (c Completion) CompanionList() []string {
+	var companions []string
+	for _, name := range strings.Split(c.Companions, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			companions = append(companions, name)
+		}
+	}
+	return companions
+}
+
 // Completions is not required by pop and may be deleted
 type Completions []Completion
 
@@ -58,22 +337,137 @@ func (c Completions) String() string {
 	return string(jc)
 }
 
+// NotTrashed scopes a query to the completions that are not in the trash.
+func NotTrashed(q *pop.Query) *pop.Query {
+	return q.Where("completions.deleted_at IS NULL")
+}
+
+// Trashed scopes a query to the completions in the trash.
+func Trashed(q *pop.Query) *pop.Query {
+	return q.Where("completions.deleted_at IS NOT NULL")
+}
+
+// OwnedBy scopes a query to the completions that belong to user.
+func OwnedBy(user *User) pop.ScopeFunc {
+	return func(q *pop.Query) *pop.Query {
+		return q.Where("completions.user_id = ?", user.ID)
+	}
+}
+
+// visibleToSQL is the condition VisibleTo adds, for raw queries. It takes
+// the User's ID twice.
+const visibleToSQL = "(completions.user_id = ? OR completions.group_id IN (SELECT group_id FROM memberships WHERE user_id = ?))"
+
+// VisibleTo scopes a query to the completions user can see: their own and
+// those shared with any Group they are a member of.
+func VisibleTo(user *User) pop.ScopeFunc {
+	return func(q *pop.Query) *pop.Query {
+		return q.Where(visibleToSQL, user.ID, user.ID)
+	}
+}
+
+// EditableBy scopes a query to the completions user can update: their own
+// and those shared with a Group they are an owner or editor of.
+func EditableBy(user *User) pop.ScopeFunc {
+	return func(q *pop.Query) *pop.Query {
+		return q.Where("(completions.user_id = ? OR completions.group_id IN (SELECT group_id FROM memberships WHERE user_id = ? AND role IN (?, ?)))", user.ID, user.ID, RoleOwner, RoleEditor)
+	}
+}
+
+// PurgeTrash deletes the completions that were moved to the trash more
+// than olderThan ago, along with their runs, progress and tags. It returns
+// how many were deleted.
+func PurgeTrash(tx *pop.Connection, olderThan time.Duration) (int, error) {
+	cutoff := time.Now().Add(-olderThan)
+	return tx.RawQuery("DELETE FROM completions WHERE deleted_at IS NOT NULL AND deleted_at < ?", cutoff).ExecWithCount()
+}
+
+// EmptyTrash deletes every completion user has in the trash, returning
+// how many were deleted.
+func EmptyTrash(tx *pop.Connection, user *User) (int, error) {
+	return tx.RawQuery("DELETE FROM completions WHERE deleted_at IS NOT NULL AND user_id = ?", user.ID).ExecWithCount()
+}
+
 // Validate gets run every time you call a "pop.Validate*" (pop.ValidateAndSave, pop.ValidateAndCreate, pop.ValidateAndUpdate) method.
 // This method is not required and may be deleted.
 func (c *Completion) Validate(tx *pop.Connection) (*validate.Errors, error) {
-	return validate.Validate(
+	// The Type must be one of the completion_types.
+	known, err := tx.Where("name = ?", c.Type).Exists(&TypeDefinition{})
+	if err != nil {
+		return validate.NewErrors(), err
+	}
+
+	checks := []validate.Validator{
 		&validators.StringIsPresent{Field: c.Name, Name: "Name"},
 		&validators.StringIsPresent{Field: string(c.Type), Name: "Type"},
-		&validators.StringInclusion{Field: string(c.Type), Name: "Type", List: []string{
-			string(CompletionTypeTVShow),
-			string(CompletionTypeVideoGame),
-			string(CompletionTypeBook),
-			string(CompletionTypeAudioBook),
-			string(CompletionTypeEvent),
-		}},
-		&validators.IntIsPresent{Field: c.Completions, Name: "Completions"},
+		&validators.FuncValidator{
+			Fn:      func() bool { return c.Type == "" || known },
+			Field:   string(c.Type),
+			Name:    "Type",
+			Message: "%s is not a known completion type.",
+		},
 		&validators.TimeIsPresent{Field: c.CompletedAt, Name: "CompletedAt"},
-	), nil
+		&validators.IntIsGreaterThan{Field: c.Target, Name: "Target", Compared: -1},
+		&validators.FuncValidator{
+			Fn:      func() bool { return c.Status.IsValid() },
+			Field:   string(c.Status),
+			Name:    "Status",
+			Message: "%s is not a valid status.",
+		},
+		&validators.FuncValidator{
+			Fn:      func() bool { return c.Privacy.IsValid() },
+			Field:   string(c.Privacy),
+			Name:    "Privacy",
+			Message: "%s is not a valid privacy level.",
+		},
+	}
+
+	// An Event's progress is its attendance; every other type counts up
+	// from zero at the start of each run.
+	if c.Type == CompletionTypeEvent {
+		checks = append(checks,
+			&validators.TimeIsPresent{Field: c.ScheduledAt.Time, Name: "ScheduledAt"},
+			&validators.StringInclusion{Field: string(c.Attendance), Name: "Attendance", List: []string{
+				string(AttendanceRegistered),
+				string(AttendanceAttended),
+				string(AttendanceMissed),
+			}},
+		)
+	} else {
+		checks = append(checks, &validators.IntIsGreaterThan{Field: c.Completions, Name: "Completions", Compared: -1})
+	}
+
+	// A Rating, when there is one, is in whole or half stars.
+	if c.Rating.Valid {
+		checks = append(checks, &validators.FuncValidator{
+			Fn:      func() bool { return IsValidRating(c.Rating.Float64) },
+			Field:   fmt.Sprint(c.Rating.Float64),
+			Name:    "Rating",
+			Message: "%s is not a rating from 0 to 5 in half stars.",
+		})
+	}
+
+	// A Completion in a Series needs its place in the reading order.
+	if c.SeriesID.Valid {
+		checks = append(checks, &validators.IntIsGreaterThan{
+			Field:    c.SeriesPosition,
+			Name:     "SeriesPosition",
+			Compared: 0,
+			Message:  "SeriesPosition must be 1 or more for a completion in a series.",
+		})
+	}
+
+	// Progress can't run past the target, when there is one.
+	if c.Target > 0 {
+		checks = append(checks, &validators.IntIsLessThan{
+			Field:    c.Completions,
+			Name:     "Completions",
+			Compared: c.Target + 1,
+			Message:  "Completions can't be more than the target.",
+		})
+	}
+
+	return validate.Validate(checks...), nil
 }
 
 // ValidateCreate gets run every time you call "pop.ValidateAndCreate" method.
@@ -83,7 +477,382 @@ func (c *Completion) ValidateCreate(tx *pop.Connection) (*validate.Errors, error
 }
 
 // ValidateUpdate gets run every time you call "pop.ValidateAndUpdate" method.
-// This method is not required and may be deleted.
+// It checks that the status change is one the lifecycle allows, and that
+// the Completion doesn't become or stop being a TV Show, whose count comes
+// from its episodes.
 func (c *Completion) ValidateUpdate(tx *pop.Connection) (*validate.Errors, error) {
-	return validate.NewErrors(), nil
+	verrs := validate.NewErrors()
+
+	previous, err := c.persisted(tx)
+	if err != nil {
+		return verrs, err
+	}
+	if c.Status.IsValid() && !previous.Status.CanTransitionTo(c.Status) {
+		verrs.Add("status", fmt.Sprintf("Status can't change from %s to %s.", previous.Status, c.Status))
+	}
+	if (previous.Type == CompletionTypeTVShow) != (c.Type == CompletionTypeTVShow) {
+		verrs.Add("type", fmt.Sprintf("Type can't change from %s to %s.", previous.Type, c.Type))
+	}
+	return verrs, nil
+}
+
+// BeforeValidate starts new completions off as planned, and private.
+func (c *Completion) BeforeValidate(tx *pop.Connection) error {
+	if c.Status == "" {
+		c.Status = StatusPlanned
+	}
+	if c.Privacy == "" {
+		c.Privacy = PrivacyPrivate
+	}
+	return nil
+}
+
+// AfterCreate starts the Completion's first Run, records the status it
+// was created with, logs any starting progress as its first
+// ProgressEntry, tags it with any bound TagList and records its first
+// Revision.
+func (c *Completion) AfterCreate(tx *pop.Connection) error {
+	run := &Run{
+		CompletionID: c.ID,
+		Number:       1,
+		StartedAt:    c.CreatedAt,
+	}
+	if c.Status.IsFinished() {
+		run.FinishedAt = nulls.NewTime(c.CompletedAt)
+	}
+	if err := tx.Create(run); err != nil {
+		return err
+	}
+	c.Runs = Runs{*run}
+
+	if err := c.recordTransition(tx, ""); err != nil {
+		return err
+	}
+	if err := c.recordProgress(tx, c.Completions); err != nil {
+		return err
+	}
+	if err := c.saveTagList(tx); err != nil {
+		return err
+	}
+
+	withTags := c.TagList != nil
+	return c.recordRevision(tx, RevisionCreated, diffRevisionFields(Completion{}.revisionFields(withTags), c.revisionFields(withTags)))
+}
+
+// BeforeUpdate remembers the persisted Completion so AfterUpdate can tell
+// what changed.
+func (c *Completion) BeforeUpdate(tx *pop.Connection) error {
+	previous, err := c.persisted(tx)
+	if err != nil {
+		return err
+	}
+	c.previousStatus = previous.Status
+	c.previousCompletions = previous.Completions
+
+	// Tags are only compared when a TagList is about to replace them
+	withTags := c.TagList != nil
+	if withTags {
+		if err := tx.Load(previous, "Tags"); err != nil {
+			return err
+		}
+	}
+	c.previousFields = previous.revisionFields(withTags)
+	return nil
+}
+
+// AfterUpdate records a StatusTransition when the status has changed,
+// and a ProgressEntry for any change to the count. Finishing or
+// abandoning the Completion also finishes its latest Run. A bound TagList
+// replaces its Tags. Whatever changed is recorded as a Revision, which is
+// a deletion or restore when the Completion went into or out of the trash.
+func (c *Completion) AfterUpdate(tx *pop.Connection) error {
+	if c.previousStatus != c.Status {
+		if err := c.recordTransition(tx, c.previousStatus); err != nil {
+			return err
+		}
+		if err := c.finishRun(tx); err != nil {
+			return err
+		}
+	}
+	if err := c.recordProgress(tx, c.Completions-c.previousCompletions); err != nil {
+		return err
+	}
+	if err := c.saveTagList(tx); err != nil {
+		return err
+	}
+
+	changes := diffRevisionFields(c.previousFields, c.revisionFields(c.TagList != nil))
+	action := RevisionUpdated
+	for _, change := range changes {
+		if change.Field == "deleted_at" {
+			action = RevisionRestored
+			if c.IsTrashed() {
+				action = RevisionDeleted
+			}
+		}
+	}
+	return c.recordRevision(tx, action, changes)
+}
+
+// StartRun begins the next Run of a completed or abandoned Completion, such
+// as a rewatch or replay. The Completion goes back in-progress with its
+// count reset to zero; earlier Runs keep their own dates and progress.
+func (c *Completion) StartRun(tx *pop.Connection, startedAt time.Time) (*validate.Errors, error) {
+	verrs := validate.NewErrors()
+	if c.Type == CompletionTypeEvent {
+		verrs.Add("type", "An event's attendance can't be repeated as a new run.")
+		return verrs, nil
+	}
+	if !c.Status.IsFinished() {
+		verrs.Add("status", "A new run can only start once the current one is completed or abandoned.")
+		return verrs, nil
+	}
+
+	latest, err := currentRun(tx, c.ID)
+	if err != nil {
+		return verrs, err
+	}
+	if startedAt.IsZero() {
+		startedAt = time.Now()
+	}
+	run := &Run{
+		CompletionID: c.ID,
+		Number:       latest.Number + 1,
+		StartedAt:    startedAt,
+	}
+	if verrs, err = tx.ValidateAndCreate(run); err != nil || verrs.HasAny() {
+		return verrs, err
+	}
+
+	// Restarting is the one way out of a final status, so the lifecycle
+	// checks in ValidateUpdate are deliberately bypassed.
+	before := c.revisionFields(false)
+	from := c.Status
+	c.Status = StatusInProgress
+	c.Completions = 0
+	c.previousCompletions = 0
+	err = tx.RawQuery("UPDATE completions SET status = ?, completions = 0, updated_at = ? WHERE id = ?", c.Status, time.Now(), c.ID).Exec()
+	if err != nil {
+		return verrs, err
+	}
+
+	// A TV Show's episodes are watched afresh in the new run; the earlier
+	// run's entries keep when they were watched before.
+	err = tx.RawQuery("UPDATE episodes SET watched_at = NULL, updated_at = ? WHERE season_id IN (SELECT id FROM seasons WHERE completion_id = ?)", time.Now(), c.ID).Exec()
+	if err != nil {
+		return verrs, err
+	}
+	c.Runs = append(c.Runs, *run)
+	if err := c.recordTransition(tx, from); err != nil {
+		return verrs, err
+	}
+	return verrs, c.recordRevision(tx, RevisionUpdated, diffRevisionFields(before, c.revisionFields(false)))
+}
+
+// SetTags replaces the Completion's Tags with the named ones, creating any
+// Tag that doesn't exist yet.
+func (c *Completion) SetTags(tx *pop.Connection, names []string) error {
+	tags := Tags{}
+	for _, name := range names {
+		tag, err := findOrCreateTag(tx, name)
+		if err != nil {
+			return err
+		}
+		tags = append(tags, *tag)
+	}
+
+	if err := tx.RawQuery("DELETE FROM completion_tags WHERE completion_id = ?", c.ID).Exec(); err != nil {
+		return err
+	}
+	for _, tag := range tags {
+		if err := tx.Create(&CompletionTag{CompletionID: c.ID, TagID: tag.ID}); err != nil {
+			return err
+		}
+	}
+
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Name < tags[j].Name })
+	c.Tags = tags
+	return nil
+}
+
+// TagNames lists the Completion's tag names, comma separated, for editing
+// in a form. A bound TagList is shown as it was entered.
+func (c Completion) TagNames() string {
+	if c.TagList != nil {
+		return *c.TagList
+	}
+
+	names := make([]string, 0, len(c.Tags))
+	for _, tag := range c.Tags {
+		names = append(names, tag.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// saveTagList sets the Completion's Tags from its TagList, if one was bound.
+func (c *Completion) saveTagList(tx *pop.Connection) error {
+	if c.TagList == nil {
+		return nil
+	}
+	return c.SetTags(tx, ParseTagList(*c.TagList))
+}
+
+// Trash moves the Completion to the trash. It keeps its runs, progress
+// and tags, so it can be restored as it was.
+func (c *Completion) Trash(tx *pop.Connection) error {
+	c.DeletedAt = nulls.NewTime(time.Now())
+	return tx.UpdateColumns(c, "deleted_at")
+}
+
+// Restore takes the Completion back out of the trash.
+func (c *Completion) Restore(tx *pop.Connection) error {
+	c.DeletedAt = nulls.Time{}
+	return tx.UpdateColumns(c, "deleted_at")
+}
+
+// CurrentRun loads the Completion's latest Run.
+func (c *Completion) CurrentRun(tx *pop.Connection) (*Run, error) {
+	return currentRun(tx, c.ID)
+}
+
+// EpisodeCount is the number of Episodes across a TV Show's Seasons.
+func (c *Completion) EpisodeCount(tx *pop.Connection) (int, error) {
+	return tx.Q().Join("seasons", "seasons.id = episodes.season_id").Where("seasons.completion_id = ?", c.ID).Count(&Episode{})
+}
+
+// finishRun sets the latest Run's FinishedAt to CompletedAt when the
+// Completion is finished, and clears it when the Completion is resumed.
+func (c *Completion) finishRun(tx *pop.Connection) error {
+	run, err := currentRun(tx, c.ID)
+	if err != nil {
+		return err
+	}
+	if c.Status.IsFinished() {
+		run.FinishedAt = nulls.NewTime(c.CompletedAt)
+	} else {
+		run.FinishedAt = nulls.Time{}
+	}
+	return tx.UpdateColumns(run, "finished_at", "updated_at")
+}
+
+// persisted loads the Completion as currently stored.
+func (c *Completion) persisted(tx *pop.Connection) (*Completion, error) {
+	persisted := &Completion{}
+	if err := tx.Find(persisted, c.ID); err != nil {
+		return nil, err
+	}
+	return persisted, nil
+}
+
+// recordProgress logs units of progress made as of CompletedAt. The count
+// has already been changed, so the entry is not validated against it.
+func (c *Completion) recordProgress(tx *pop.Connection, units int) error {
+	if units == 0 {
+		return nil
+	}
+
+	run, err := currentRun(tx, c.ID)
+	if err != nil {
+		return err
+	}
+
+	entry := &ProgressEntry{
+		CompletionID: c.ID,
+		RunID:        run.ID,
+		Units:        units,
+		LoggedAt:     c.CompletedAt,
+	}
+	if err := tx.Create(entry); err != nil {
+		return err
+	}
+	c.previousCompletions = c.Completions
+	return nil
+}
+
+func (c *Completion) recordTransition(tx *pop.Connection, from Status) error {
+	transition := &StatusTransition{
+		CompletionID: c.ID,
+		FromStatus:   from,
+		ToStatus:     c.Status,
+	}
+	if err := tx.Create(transition); err != nil {
+		return err
+	}
+	c.StatusTransitions = append(c.StatusTransitions, *transition)
+	c.previousStatus = c.Status
+	return nil
+}
+
+// revisionFields lists the Completion's fields as recorded in a Revision,
+// with empty values for what isn't set. Its tags are only listed when
+// withTags is true, as they aren't always loaded.
+func (c Completion) revisionFields(withTags bool) []revisionField {
+	formatTime := func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.UTC().Format(time.RFC3339)
+	}
+
+	var scheduledAt, rating, seriesID, groupID, deletedAt string
+	if c.ScheduledAt.Valid {
+		scheduledAt = formatTime(c.ScheduledAt.Time)
+	}
+	if c.Rating.Valid {
+		rating = strconv.FormatFloat(c.Rating.Float64, 'f', 1, 64)
+	}
+	if c.SeriesID.Valid {
+		seriesID = c.SeriesID.UUID.String()
+	}
+	if c.GroupID.Valid {
+		groupID = c.GroupID.UUID.String()
+	}
+	if c.DeletedAt.Valid {
+		deletedAt = formatTime(c.DeletedAt.Time)
+	}
+
+	fields := []revisionField{
+		{"name", c.Name},
+		{"type", string(c.Type)},
+		{"status", string(c.Status)},
+		{"completions", strconv.Itoa(c.Completions)},
+		{"target", strconv.Itoa(c.Target)},
+		{"completed_at", formatTime(c.CompletedAt)},
+		{"scheduled_at", scheduledAt},
+		{"venue", c.Venue},
+		{"attendance", string(c.Attendance)},
+		{"companions", c.Companions},
+		{"rating", rating},
+		{"review", c.Review},
+		{"series_id", seriesID},
+		{"series_position", strconv.Itoa(c.SeriesPosition)},
+		{"deleted_at", deletedAt},
+		{"privacy", string(c.Privacy)},
+		{"group_id", groupID},
+	}
+	if withTags {
+		names := make([]string, 0, len(c.Tags))
+		for _, tag := range c.Tags {
+			names = append(names, tag.Name)
+		}
+		sort.Strings(names)
+		fields = append(fields, revisionField{"tags", strings.Join(names, ", ")})
+	}
+	return fields
+}
+
+// recordRevision records changes to the Completion as a Revision by the
+// actor of tx. Nothing is recorded when nothing changed.
+func (c *Completion) recordRevision(tx *pop.Connection, action RevisionAction, changes FieldChanges) error {
+	if len(changes) == 0 {
+		return nil
+	}
+
+	revision := &Revision{
+		CompletionID: c.ID,
+		Action:       action,
+		Actor:        actorOf(tx),
+		Changes:      changes,
+	}
+	return tx.Create(revision)
 }
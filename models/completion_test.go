@@ -1,5 +1,206 @@
 package models
 
+import (
+	"encoding/json"
+	"encoding/xml"
+	"time"
+
+	"github.com/gobuffalo/nulls"
+)
+
 func (ms *ModelSuite) Test_Completion() {
-	ms.Fail("This test needs to be implemented!")
+	c := &Completion{
+		Name:        "Dune",
+		Type:        CompletionTypeBook,
+		Completions: 100,
+		Target:      400,
+		CompletedAt: time.Now(),
+	}
+	verrs, err := ms.DB.ValidateAndCreate(c)
+	ms.NoError(err)
+	ms.False(verrs.HasAny())
+
+	c.Completions = 401
+	verrs, err = c.Validate(ms.DB)
+	ms.NoError(err)
+	ms.True(verrs.HasAny())
+}
+
+func (ms *ModelSuite) Test_Completion_Progress() {
+	c := Completion{Type: CompletionTypeBook, Completions: 100, Target: 400}
+	ms.True(c.HasTarget())
+	ms.Equal(25, c.PercentComplete())
+	ms.Equal(300, c.Remaining())
+	ms.Equal("300 pages", c.FormatUnits(c.Remaining()))
+
+	c.Completions = 400
+	ms.Equal(100, c.PercentComplete())
+	ms.Equal(0, c.Remaining())
+
+	c.Target = 0
+	ms.False(c.HasTarget())
+	ms.Equal(0, c.PercentComplete())
+	ms.Equal(0, c.Remaining())
+
+	audioBook := Completion{Type: CompletionTypeAudioBook, Completions: 90}
+	ms.Equal("1:30", audioBook.FormatUnits(audioBook.Completions))
+
+	tvShow := Completion{Type: CompletionTypeTVShow}
+	ms.Equal("1 episode", tvShow.FormatUnits(1))
+}
+
+func (ms *ModelSuite) Test_Completion_Marshal() {
+	c := Completion{Name: "Dune", Type: CompletionTypeBook, Completions: 100, Target: 400}
+
+	b, err := json.Marshal(c)
+	ms.NoError(err)
+	ms.Contains(string(b), `"name":"Dune"`)
+	ms.Contains(string(b), `"percent_complete":25`)
+	ms.Contains(string(b), `"remaining":300`)
+
+	b, err = xml.Marshal(c)
+	ms.NoError(err)
+	ms.Contains(string(b), "<Completion>")
+	ms.Contains(string(b), "<PercentComplete>25</PercentComplete>")
+}
+
+func (ms *ModelSuite) Test_Completion_Rating() {
+	ms.True(IsValidRating(0))
+	ms.True(IsValidRating(3.5))
+	ms.True(IsValidRating(5))
+	ms.False(IsValidRating(3.2))
+	ms.False(IsValidRating(5.5))
+	ms.False(IsValidRating(-1))
+	ms.Len(GetRatings(), 11)
+
+	c := &Completion{Name: "Dune", Type: CompletionTypeBook, CompletedAt: time.Now()}
+	ms.Nil(c.Stars())
+
+	c.Rating = nulls.NewFloat64(3.5)
+	ms.Equal([]string{"full", "full", "full", "half", "empty"}, c.Stars())
+	verrs, err := c.Validate(ms.DB)
+	ms.NoError(err)
+	ms.False(verrs.HasAny())
+
+	c.Rating = nulls.NewFloat64(4.2)
+	verrs, err = c.Validate(ms.DB)
+	ms.NoError(err)
+	ms.True(verrs.HasAny())
+	ms.NotEmpty(verrs.Get("rating"))
+}
+
+func (ms *ModelSuite) Test_Completion_Review() {
+	c := Completion{Review: "A **great** read.\n\n<script>alert(1)</script>"}
+	html := string(c.ReviewHTML())
+	ms.Contains(html, "<strong>great</strong>")
+	ms.NotContains(html, "<script>")
+}
+
+func (ms *ModelSuite) Test_Completion_MarshalRating() {
+	c := Completion{Name: "Dune", Type: CompletionTypeBook}
+
+	b, err := json.Marshal(c)
+	ms.NoError(err)
+	ms.Contains(string(b), `"rating":null`)
+
+	b, err = xml.Marshal(c)
+	ms.NoError(err)
+	ms.NotContains(string(b), "<Rating>")
+
+	c.Rating = nulls.NewFloat64(4.5)
+	b, err = json.Marshal(c)
+	ms.NoError(err)
+	ms.Contains(string(b), `"rating":4.5`)
+
+	b, err = xml.Marshal(c)
+	ms.NoError(err)
+	ms.Contains(string(b), "<Rating>4.5</Rating>")
+}
+
+func (ms *ModelSuite) Test_Completion_Trash() {
+	c := &Completion{Name: "Dune", Type: CompletionTypeBook, Completions: 100, CompletedAt: time.Now()}
+	ms.NoError(ms.DB.Create(c))
+
+	ms.NoError(c.Trash(ms.DB))
+	ms.True(c.IsTrashed())
+
+	count, err := ms.DB.Scope(NotTrashed).Count(&Completion{})
+	ms.NoError(err)
+	ms.Equal(0, count)
+
+	// Trashing keeps the Completion's progress
+	entries, err := ms.DB.Where("completion_id = ?", c.ID).Count(&ProgressEntry{})
+	ms.NoError(err)
+	ms.Equal(1, entries)
+
+	ms.NoError(c.Restore(ms.DB))
+	ms.False(c.IsTrashed())
+
+	count, err = ms.DB.Scope(NotTrashed).Count(&Completion{})
+	ms.NoError(err)
+	ms.Equal(1, count)
+}
+
+func (ms *ModelSuite) Test_PurgeTrash() {
+	old := &Completion{Name: "Dune", Type: CompletionTypeBook, CompletedAt: time.Now()}
+	recent := &Completion{Name: "Emma", Type: CompletionTypeBook, CompletedAt: time.Now()}
+	kept := &Completion{Name: "Hades", Type: CompletionTypeVideoGame, CompletedAt: time.Now()}
+	ms.NoError(ms.DB.Create(old))
+	ms.NoError(ms.DB.Create(recent))
+	ms.NoError(ms.DB.Create(kept))
+
+	ms.NoError(old.Trash(ms.DB))
+	old.DeletedAt = nulls.NewTime(time.Now().Add(-48 * time.Hour))
+	ms.NoError(ms.DB.UpdateColumns(old, "deleted_at"))
+	ms.NoError(recent.Trash(ms.DB))
+
+	purged, err := PurgeTrash(ms.DB, 24*time.Hour)
+	ms.NoError(err)
+	ms.Equal(1, purged)
+
+	count, err := ms.DB.Count(&Completion{})
+	ms.NoError(err)
+	ms.Equal(2, count)
+
+	count, err = ms.DB.Scope(Trashed).Count(&Completion{})
+	ms.NoError(err)
+	ms.Equal(1, count)
+}
+
+func (ms *ModelSuite) Test_OwnedBy() {
+	u := ms.createUser("reader@example.com")
+	stranger := ms.createUser("stranger@example.com")
+
+	mine := &Completion{Name: "Dune", Type: CompletionTypeBook, CompletedAt: time.Now(), UserID: nulls.NewUUID(u.ID)}
+	theirs := &Completion{Name: "Halo", Type: CompletionTypeVideoGame, CompletedAt: time.Now(), UserID: nulls.NewUUID(stranger.ID)}
+	ms.NoError(ms.DB.Create(mine))
+	ms.NoError(ms.DB.Create(theirs))
+
+	completions := Completions{}
+	ms.NoError(ms.DB.Scope(OwnedBy(u)).All(&completions))
+	ms.Len(completions, 1)
+	ms.Equal(mine.ID, completions[0].ID)
+
+	ms.Error(ms.DB.Scope(OwnedBy(u)).Find(&Completion{}, theirs.ID))
+}
+
+func (ms *ModelSuite) Test_EmptyTrash() {
+	u := ms.createUser("reader@example.com")
+	stranger := ms.createUser("stranger@example.com")
+
+	mine := &Completion{Name: "Dune", Type: CompletionTypeBook, CompletedAt: time.Now(), UserID: nulls.NewUUID(u.ID)}
+	theirs := &Completion{Name: "Halo", Type: CompletionTypeVideoGame, CompletedAt: time.Now(), UserID: nulls.NewUUID(stranger.ID)}
+	ms.NoError(ms.DB.Create(mine))
+	ms.NoError(ms.DB.Create(theirs))
+	ms.NoError(mine.Trash(ms.DB))
+	ms.NoError(theirs.Trash(ms.DB))
+
+	purged, err := EmptyTrash(ms.DB, u)
+	ms.NoError(err)
+	ms.Equal(1, purged)
+
+	// Only the user's own trash is emptied
+	count, err := ms.DB.Scope(Trashed).Count(&Completion{})
+	ms.NoError(err)
+	ms.Equal(1, count)
 }
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Duration is a length of time in whole minutes, written as "h:mm".
+// It is used for time-based progress such as audio book listening.
+type Duration int
+
+// ParseDuration parses an "h:mm" string, or a bare number of minutes,
+// into a Duration. An empty string is a zero Duration.
+func ParseDuration(s string) (Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+
+	hours, minutes, found := strings.Cut(s, ":")
+	if !found {
+		hours, minutes = "0", s
+	}
+
+	h, err := strconv.Atoi(hours)
+	if err != nil || h < 0 {
+		return 0, fmt.Errorf("invalid duration %q: expected h:mm", s)
+	}
+	m, err := strconv.Atoi(minutes)
+	if err != nil || m < 0 || (found && m > 59) {
+		return 0, fmt.Errorf("invalid duration %q: expected h:mm", s)
+	}
+
+	return Duration(h*60 + m), nil
+}
+
+// String formats the Duration as "h:mm".
+func (d Duration) String() string {
+	return fmt.Sprintf("%d:%02d", int(d)/60, int(d)%60)
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler so a Duration can be
+// bound straight from an "h:mm" form field.
+func (d *Duration) UnmarshalText(text []byte) error {
+	parsed, err := ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
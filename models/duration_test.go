@@ -0,0 +1,27 @@
+package models
+
+func (ms *ModelSuite) Test_ParseDuration() {
+	cases := map[string]Duration{
+		"":      0,
+		"0:45":  45,
+		"1:30":  90,
+		"12:05": 725,
+		"90":    90,
+	}
+	for in, want := range cases {
+		got, err := ParseDuration(in)
+		ms.NoError(err, in)
+		ms.Equal(want, got, in)
+	}
+
+	for _, in := range []string{"1:75", "abc", "-1:00", "1:xx"} {
+		_, err := ParseDuration(in)
+		ms.Error(err, in)
+	}
+}
+
+func (ms *ModelSuite) Test_Duration_String() {
+	ms.Equal("0:00", Duration(0).String())
+	ms.Equal("1:30", Duration(90).String())
+	ms.Equal("12:05", Duration(725).String())
+}
@@ -0,0 +1,178 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gobuffalo/nulls"
+	"github.com/gobuffalo/pop/v6"
+	"github.com/gobuffalo/validate/v3"
+	"github.com/gobuffalo/validate/v3/validators"
+	"github.com/gofrs/uuid"
+)
+
+// Episode is one episode of a Season. WatchedAt is set while it's watched
+// in the show's current Run.
+//
+// A TV Show's Completions is the number of watched Episodes: watching or
+// unwatching one logs a ProgressEntry of one episode, and adding or
+// removing Episodes recounts the show's Target.
+type Episode struct {
+	ID        uuid.UUID  `json:"id" db:"id"`
+	SeasonID  uuid.UUID  `json:"season_id" db:"season_id"`
+	Number    int        `json:"number" db:"number"`
+	Title     string     `json:"title" db:"title"`
+	WatchedAt nulls.Time `json:"watched_at" db:"watched_at"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
+
+	// wasWatched is whether the persisted Episode was watched before an
+	// update.
+	wasWatched bool `db:"-"`
+}
+
+// String is not required by pop and may be deleted
+func (e Episode) String() string {
+	je, _ := json.Marshal(e)
+	return string(je)
+}
+
+// IsWatched reports whether the Episode has been watched.
+func (e Episode) IsWatched() bool {
+	return e.WatchedAt.Valid
+}
+
+// Episodes is not required by pop and may be deleted
+type Episodes []Episode
+
+// String is not required by pop and may be deleted
+func (e Episodes) String() string {
+	je, _ := json.Marshal(e)
+	return string(je)
+}
+
+// Validate gets run every time you call a "pop.Validate*" (pop.ValidateAndSave, pop.ValidateAndCreate, pop.ValidateAndUpdate) method.
+// It also checks that the number isn't used by another Episode of the
+// Season.
+func (e *Episode) Validate(tx *pop.Connection) (*validate.Errors, error) {
+	verrs := validate.Validate(
+		&validators.UUIDIsPresent{Field: e.SeasonID, Name: "SeasonID"},
+		&validators.IntIsGreaterThan{Field: e.Number, Name: "Number", Compared: 0},
+	)
+
+	taken, err := tx.Where("season_id = ? AND number = ? AND id <> ?", e.SeasonID, e.Number, e.ID).Exists(&Episode{})
+	if err != nil {
+		return verrs, err
+	}
+	if taken {
+		verrs.Add("number", fmt.Sprintf("Episode %d already exists.", e.Number))
+	}
+	return verrs, nil
+}
+
+// AfterCreate counts the new Episode into the show's Target, and into its
+// progress if it was added already watched.
+func (e *Episode) AfterCreate(tx *pop.Connection) error {
+	season, err := e.season(tx)
+	if err != nil {
+		return err
+	}
+	if err := refreshEpisodeTotal(tx, season.CompletionID); err != nil {
+		return err
+	}
+	if !e.IsWatched() {
+		return nil
+	}
+	return logWatch(tx, season.CompletionID, 1, e.WatchedAt.Time, e.label(season))
+}
+
+// BeforeUpdate remembers whether the persisted Episode was watched.
+func (e *Episode) BeforeUpdate(tx *pop.Connection) error {
+	persisted := &Episode{}
+	if err := tx.Select("watched_at").Find(persisted, e.ID); err != nil {
+		return err
+	}
+	e.wasWatched = persisted.IsWatched()
+	return nil
+}
+
+// AfterUpdate logs a watch or unwatch of the Episode.
+func (e *Episode) AfterUpdate(tx *pop.Connection) error {
+	if e.wasWatched == e.IsWatched() {
+		return nil
+	}
+	season, err := e.season(tx)
+	if err != nil {
+		return err
+	}
+
+	e.wasWatched = e.IsWatched()
+	if e.IsWatched() {
+		return logWatch(tx, season.CompletionID, 1, e.WatchedAt.Time, e.label(season))
+	}
+	return logWatch(tx, season.CompletionID, -1, time.Now(), e.label(season)+" unwatched")
+}
+
+// AfterDestroy takes the Episode out of the show's Target and progress.
+func (e *Episode) AfterDestroy(tx *pop.Connection) error {
+	season, err := e.season(tx)
+	if err != nil {
+		return err
+	}
+	if err := refreshEpisodeTotal(tx, season.CompletionID); err != nil {
+		return err
+	}
+	if !e.IsWatched() {
+		return nil
+	}
+	return logWatch(tx, season.CompletionID, -1, time.Now(), e.label(season)+" removed")
+}
+
+func (e *Episode) season(tx *pop.Connection) (*Season, error) {
+	season := &Season{}
+	if err := tx.Find(season, e.SeasonID); err != nil {
+		return nil, err
+	}
+	return season, nil
+}
+
+// label names the Episode in progress notes, e.g. "S01E03 Pilot".
+func (e *Episode) label(season *Season) string {
+	label := fmt.Sprintf("S%02dE%02d", season.Number, e.Number)
+	if e.Title != "" {
+		label += " " + e.Title
+	}
+	return label
+}
+
+// logWatch logs units watched episodes against the current Run of a TV
+// Show. The episodes themselves are the source of the count, so the entry
+// isn't validated against it.
+func logWatch(tx *pop.Connection, completionID uuid.UUID, units int, at time.Time, note string) error {
+	if units == 0 {
+		return nil
+	}
+
+	run, err := currentRun(tx, completionID)
+	if err != nil {
+		return err
+	}
+	entry := &ProgressEntry{
+		CompletionID: completionID,
+		RunID:        run.ID,
+		Units:        units,
+		LoggedAt:     at,
+		Note:         note,
+	}
+	return tx.Create(entry)
+}
+
+// refreshEpisodeTotal sets a TV Show's Target to the number of Episodes
+// in its Seasons.
+func refreshEpisodeTotal(tx *pop.Connection, completionID uuid.UUID) error {
+	return tx.RawQuery(`UPDATE completions SET
+		target = (SELECT COUNT(*) FROM episodes JOIN seasons ON seasons.id = episodes.season_id WHERE seasons.completion_id = ?),
+		updated_at = ?
+		WHERE id = ?`, completionID, time.Now(), completionID).Exec()
+}
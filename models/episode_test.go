@@ -0,0 +1,83 @@
+package models
+
+import (
+	"time"
+
+	"github.com/gobuffalo/nulls"
+)
+
+func (ms *ModelSuite) Test_Episode_Watching() {
+	c := &Completion{
+		Name:        "Severance",
+		Type:        CompletionTypeTVShow,
+		CompletedAt: time.Now(),
+	}
+	verrs, err := ms.DB.ValidateAndCreate(c)
+	ms.NoError(err)
+	ms.False(verrs.HasAny())
+
+	season := &Season{CompletionID: c.ID, Number: 1, EpisodeCount: 3}
+	verrs, err = ms.DB.ValidateAndCreate(season)
+	ms.NoError(err)
+	ms.False(verrs.HasAny())
+	ms.Len(season.Episodes, 3)
+
+	ms.NoError(ms.DB.Reload(c))
+	ms.Equal(3, c.Target)
+	ms.Equal(0, c.Completions)
+
+	watchedAt := time.Now().Add(-time.Hour).Truncate(time.Second)
+	episode := season.Episodes[0]
+	episode.WatchedAt = nulls.NewTime(watchedAt)
+	verrs, err = ms.DB.ValidateAndUpdate(&episode)
+	ms.NoError(err)
+	ms.False(verrs.HasAny())
+
+	ms.NoError(ms.DB.Reload(c))
+	ms.Equal(1, c.Completions)
+	ms.True(watchedAt.Equal(c.CompletedAt.Truncate(time.Second)))
+
+	entry := &ProgressEntry{}
+	ms.NoError(ms.DB.Where("completion_id = ?", c.ID).First(entry))
+	ms.Equal("S01E01", entry.Note)
+
+	duplicate := &Episode{SeasonID: season.ID, Number: 1}
+	verrs, err = ms.DB.ValidateAndCreate(duplicate)
+	ms.NoError(err)
+	ms.NotEmpty(verrs.Get("number"))
+
+	ms.NoError(ms.DB.Destroy(season))
+	ms.NoError(ms.DB.Reload(c))
+	ms.Equal(0, c.Target)
+	ms.Equal(0, c.Completions)
+}
+
+func (ms *ModelSuite) Test_Episode_NewRun() {
+	c := &Completion{
+		Name:        "Severance",
+		Type:        CompletionTypeTVShow,
+		CompletedAt: time.Now(),
+	}
+	verrs, err := ms.DB.ValidateAndCreate(c)
+	ms.NoError(err)
+	ms.False(verrs.HasAny())
+
+	season := &Season{CompletionID: c.ID, Number: 1, EpisodeCount: 1}
+	ms.NoError(ms.DB.Create(season))
+	episode := season.Episodes[0]
+	episode.WatchedAt = nulls.NewTime(time.Now())
+	ms.NoError(ms.DB.Update(&episode))
+
+	ms.NoError(ms.DB.Reload(c))
+	c.Status = StatusCompleted
+	verrs, err = ms.DB.ValidateAndUpdate(c)
+	ms.NoError(err)
+	ms.False(verrs.HasAny())
+
+	verrs, err = c.StartRun(ms.DB, time.Time{})
+	ms.NoError(err)
+	ms.False(verrs.HasAny())
+
+	ms.NoError(ms.DB.Reload(&episode))
+	ms.False(episode.IsWatched())
+}
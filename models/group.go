@@ -0,0 +1,221 @@
+package models
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"time"
+
+	"github.com/gobuffalo/pop/v6"
+	"github.com/gobuffalo/validate/v3"
+	"github.com/gobuffalo/validate/v3/validators"
+	"github.com/gofrs/uuid"
+)
+
+// Group is a household or team that tracks completions together. Users
+// join it through a Membership with a Role, and a Completion is shared
+// with it through its GroupID, which lets every member see it and its
+// editors update it. The Completion still belongs to the User who added
+// it.
+type Group struct {
+	ID          uuid.UUID `json:"id" db:"id"`
+	Name        string    `json:"name" db:"name"`
+	Description string    `json:"description" db:"description"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+
+	Memberships Memberships  `json:"members,omitempty" db:"-" has_many:"memberships" order_by:"created_at asc"`
+	Invites     GroupInvites `json:"invites,omitempty" db:"-" has_many:"group_invites" order_by:"created_at asc"`
+	Completions Completions  `json:"completions,omitempty" db:"-" has_many:"completions" order_by:"completed_at desc, name asc"`
+}
+
+// GroupTotal sums up a Group's completions of one type.
+type GroupTotal struct {
+	Type       CompletionType `json:"type"`
+	Count      int            `json:"count"`
+	InProgress int            `json:"in_progress"`
+	Completed  int            `json:"completed"`
+	Progress   int            `json:"progress"`
+}
+
+// MemberTotal sums up the completions one member shares with a Group.
+type MemberTotal struct {
+	UserID    uuid.UUID `json:"user_id"`
+	Email     string    `json:"email"`
+	Role      Role      `json:"role"`
+	Count     int       `json:"count"`
+	Completed int       `json:"completed"`
+}
+
+// groupAlias has Group's fields without its methods, so it can be
+// marshaled without recursing into MarshalJSON or MarshalXML.
+type groupAlias Group
+
+// groupView is the JSON and XML representation of a Group, including the
+// totals derived from its loaded Completions.
+type groupView struct {
+	groupAlias
+	Finished        int           `json:"finished"`
+	PercentComplete int           `json:"percent_complete"`
+	Totals          []GroupTotal  `json:"totals"`
+	MemberTotals    []MemberTotal `json:"member_totals"`
+}
+
+func (g Group) view() groupView {
+	return groupView{
+		groupAlias:      groupAlias(g),
+		Finished:        g.Finished(),
+		PercentComplete: g.PercentComplete(),
+		Totals:          g.Totals(),
+		MemberTotals:    g.MemberTotals(),
+	}
+}
+
+// MarshalJSON includes the Group's totals in the JSON output.
+func (g Group) MarshalJSON() ([]byte, error) {
+	return json.Marshal(g.view())
+}
+
+// MarshalXML includes the Group's totals in the XML output.
+func (g Group) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return e.EncodeElement(g.view(), start)
+}
+
+// String is not required by pop and may be deleted
+func (g Group) String() string {
+	jg, _ := json.Marshal(g)
+	return string(jg)
+}
+
+// Groups is not required by pop and may be deleted
+type Groups []Group
+
+// String is not required by pop and may be deleted
+func (g Groups) String() string {
+	jg, _ := json.Marshal(g)
+	return string(jg)
+}
+
+// Validate gets run every time you call a "pop.Validate*" (pop.ValidateAndSave, pop.ValidateAndCreate, pop.ValidateAndUpdate) method.
+// This method is not required and may be deleted.
+func (g *Group) Validate(tx *pop.Connection) (*validate.Errors, error) {
+	return validate.Validate(
+		&validators.StringIsPresent{Field: g.Name, Name: "Name"},
+	), nil
+}
+
+// Create adds the Group with owner as its first member.
+func (g *Group) Create(tx *pop.Connection, owner *User) (*validate.Errors, error) {
+	verrs, err := tx.ValidateAndCreate(g)
+	if err != nil || verrs.HasAny() {
+		return verrs, err
+	}
+	return tx.ValidateAndCreate(&Membership{GroupID: g.ID, UserID: owner.ID, Role: RoleOwner})
+}
+
+// LoadCompletions loads the Completions shared with the Group, the most
+// recently completed first, leaving out any in the trash.
+func (g *Group) LoadCompletions(tx *pop.Connection) error {
+	g.Completions = Completions{}
+	return tx.Scope(NotTrashed).Where("group_id = ?", g.ID).Order("completed_at desc, name asc").All(&g.Completions)
+}
+
+// Finished is how many of the Group's loaded Completions are completed.
+func (g Group) Finished() int {
+	finished := 0
+	for _, completion := range g.Completions {
+		if completion.Status == StatusCompleted {
+			finished++
+		}
+	}
+	return finished
+}
+
+// PercentComplete returns the share of the Group's loaded Completions that
+// are completed as a whole percentage. It is 0 for an empty Group.
+func (g Group) PercentComplete() int {
+	if len(g.Completions) == 0 {
+		return 0
+	}
+	return g.Finished() * 100 / len(g.Completions)
+}
+
+// Totals sums up the Group's loaded Completions by type, in the order the
+// types are listed in.
+func (g Group) Totals() []GroupTotal {
+	var order []CompletionType
+	for _, t := range GetCompletionTypes() {
+		order = append(order, t.Name)
+	}
+
+	byType := map[CompletionType]*GroupTotal{}
+	for _, c := range g.Completions {
+		total, ok := byType[c.Type]
+		if !ok {
+			total = &GroupTotal{Type: c.Type}
+			byType[c.Type] = total
+			if !containsType(order, c.Type) {
+				order = append(order, c.Type)
+			}
+		}
+		total.Count++
+		total.Progress += c.Completions
+		switch c.Status {
+		case StatusInProgress:
+			total.InProgress++
+		case StatusCompleted:
+			total.Completed++
+		}
+	}
+
+	totals := []GroupTotal{}
+	for _, t := range order {
+		if total, ok := byType[t]; ok {
+			totals = append(totals, *total)
+		}
+	}
+	return totals
+}
+
+// MemberTotals sums up the Group's loaded Completions by the member who
+// shared them, in the order of its loaded Memberships. Their Users must be
+// loaded too.
+func (g Group) MemberTotals() []MemberTotal {
+	totals := make([]MemberTotal, 0, len(g.Memberships))
+	for _, m := range g.Memberships {
+		total := MemberTotal{UserID: m.UserID, Role: m.Role}
+		if m.User != nil {
+			total.Email = m.User.Email
+		}
+		for _, c := range g.Completions {
+			if c.UserID.Valid && c.UserID.UUID == m.UserID {
+				total.Count++
+				if c.Status == StatusCompleted {
+					total.Completed++
+				}
+			}
+		}
+		totals = append(totals, total)
+	}
+	return totals
+}
+
+// SharedBy is the email of the member who shared c with the Group, from
+// its loaded Memberships and their Users.
+func (g Group) SharedBy(c Completion) string {
+	for _, m := range g.Memberships {
+		if c.UserID.Valid && c.UserID.UUID == m.UserID && m.User != nil {
+			return m.User.Email
+		}
+	}
+	return ""
+}
+
+// SelectValue lets a Group be offered in a select tag.
+func (g Group) SelectValue() interface{} {
+	return g.ID
+}
+
+// SelectLabel lets a Group be offered in a select tag.
+func (g Group) SelectLabel() string {
+	return g.Name
+}
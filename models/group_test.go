@@ -0,0 +1,208 @@
+package models
+
+import (
+	"time"
+
+	"github.com/gobuffalo/nulls"
+)
+
+// createGroup creates a Group owned by owner, with members joining in the
+// given roles.
+func (ms *ModelSuite) createGroup(owner *User, members map[*User]Role) *Group {
+	g := &Group{Name: "Our house"}
+	verrs, err := g.Create(ms.DB, owner)
+	ms.NoError(err)
+	ms.False(verrs.HasAny())
+
+	for u, role := range members {
+		ms.NoError(ms.DB.Create(&Membership{GroupID: g.ID, UserID: u.ID, Role: role}))
+	}
+	return g
+}
+
+// shareWith records a completion of u's shared with g.
+func (ms *ModelSuite) shareWith(g *Group, u *User, name string, status Status) *Completion {
+	c := &Completion{Name: name, Type: CompletionTypeTVShow, Status: status, CompletedAt: time.Now(), UserID: nulls.NewUUID(u.ID), GroupID: nulls.NewUUID(g.ID)}
+	verrs, err := ms.DB.ValidateAndCreate(c)
+	ms.NoError(err)
+	ms.False(verrs.HasAny())
+	return c
+}
+
+func (ms *ModelSuite) Test_Group_Create() {
+	owner := ms.createUser("owner@example.com")
+
+	g := &Group{}
+	verrs, err := g.Create(ms.DB, owner)
+	ms.NoError(err)
+	ms.NotEmpty(verrs.Get("name"))
+
+	g = ms.createGroup(owner, nil)
+	m, err := FindMembership(ms.DB, g.ID, owner)
+	ms.NoError(err)
+	ms.Equal(RoleOwner, m.Role)
+
+	memberships, err := MembershipsOf(ms.DB, owner)
+	ms.NoError(err)
+	ms.Len(memberships, 1)
+	ms.Equal("Our house", memberships[0].Group.Name)
+}
+
+func (ms *ModelSuite) Test_VisibleTo_EditableBy() {
+	owner := ms.createUser("owner@example.com")
+	editor := ms.createUser("editor@example.com")
+	viewer := ms.createUser("viewer@example.com")
+	outsider := ms.createUser("outsider@example.com")
+	g := ms.createGroup(owner, map[*User]Role{editor: RoleEditor, viewer: RoleViewer})
+
+	shared := ms.shareWith(g, owner, "Severance", StatusInProgress)
+	private := &Completion{Name: "Diary", Type: CompletionTypeBook, CompletedAt: time.Now(), UserID: nulls.NewUUID(owner.ID)}
+	ms.NoError(ms.DB.Create(private))
+
+	for u, want := range map[*User][2]int{owner: {2, 2}, editor: {1, 1}, viewer: {1, 0}, outsider: {0, 0}} {
+		visible, err := ms.DB.Scope(VisibleTo(u)).Count(&Completion{})
+		ms.NoError(err)
+		ms.Equal(want[0], visible, u.Email)
+
+		editable, err := ms.DB.Scope(EditableBy(u)).Count(&Completion{})
+		ms.NoError(err)
+		ms.Equal(want[1], editable, u.Email)
+	}
+
+	found := &Completion{}
+	ms.NoError(ms.DB.Scope(VisibleTo(viewer)).Find(found, shared.ID))
+	ms.Error(ms.DB.Scope(VisibleTo(viewer)).Find(found, private.ID))
+}
+
+func (ms *ModelSuite) Test_GroupInvite_Accept() {
+	owner := ms.createUser("owner@example.com")
+	invited := ms.createUser("invited@example.com")
+	stranger := ms.createUser("stranger@example.com")
+	g := ms.createGroup(owner, nil)
+
+	invite := &GroupInvite{GroupID: g.ID, Email: " Invited@Example.com ", Role: RoleEditor}
+	verrs, err := ms.DB.ValidateAndCreate(invite)
+	ms.NoError(err)
+	ms.False(verrs.HasAny())
+	ms.Equal("invited@example.com", invite.Email)
+
+	// Nobody is invited twice, or once they are a member
+	verrs, err = ms.DB.ValidateAndCreate(&GroupInvite{GroupID: g.ID, Email: "invited@example.com", Role: RoleViewer})
+	ms.NoError(err)
+	ms.NotEmpty(verrs.Get("email"))
+	verrs, err = ms.DB.ValidateAndCreate(&GroupInvite{GroupID: g.ID, Email: "owner@example.com", Role: RoleViewer})
+	ms.NoError(err)
+	ms.NotEmpty(verrs.Get("email"))
+	verrs, err = ms.DB.ValidateAndCreate(&GroupInvite{GroupID: g.ID, Email: "new@example.com", Role: "admin"})
+	ms.NoError(err)
+	ms.NotEmpty(verrs.Get("role"))
+
+	invites, err := InvitesFor(ms.DB, invited)
+	ms.NoError(err)
+	ms.Len(invites, 1)
+	ms.Equal(g.Name, invites[0].Group.Name)
+
+	_, err = invite.Accept(ms.DB, stranger)
+	ms.ErrorIs(err, ErrNotInvited)
+
+	m, err := invite.Accept(ms.DB, invited)
+	ms.NoError(err)
+	ms.Equal(RoleEditor, m.Role)
+
+	invites, err = InvitesFor(ms.DB, invited)
+	ms.NoError(err)
+	ms.Len(invites, 0)
+}
+
+func (ms *ModelSuite) Test_Membership_LastOwner() {
+	owner := ms.createUser("owner@example.com")
+	member := ms.createUser("member@example.com")
+	g := ms.createGroup(owner, map[*User]Role{member: RoleEditor})
+
+	m, err := FindMembership(ms.DB, g.ID, owner)
+	ms.NoError(err)
+
+	// The only owner can neither step down nor leave
+	m.Role = RoleEditor
+	verrs, err := ms.DB.ValidateAndUpdate(m)
+	ms.NoError(err)
+	ms.NotEmpty(verrs.Get("role"))
+
+	verrs, err = m.Remove(ms.DB)
+	ms.NoError(err)
+	ms.NotEmpty(verrs.Get("role"))
+
+	// Once someone else owns it they can
+	other, err := FindMembership(ms.DB, g.ID, member)
+	ms.NoError(err)
+	other.Role = RoleOwner
+	verrs, err = ms.DB.ValidateAndUpdate(other)
+	ms.NoError(err)
+	ms.False(verrs.HasAny())
+
+	verrs, err = ms.DB.ValidateAndUpdate(m)
+	ms.NoError(err)
+	ms.False(verrs.HasAny())
+}
+
+func (ms *ModelSuite) Test_Membership_Remove() {
+	owner := ms.createUser("owner@example.com")
+	member := ms.createUser("member@example.com")
+	g := ms.createGroup(owner, map[*User]Role{member: RoleEditor})
+
+	kept := ms.shareWith(g, owner, "Severance", StatusInProgress)
+	taken := ms.shareWith(g, member, "Andor", StatusInProgress)
+
+	m, err := FindMembership(ms.DB, g.ID, member)
+	ms.NoError(err)
+	verrs, err := m.Remove(ms.DB)
+	ms.NoError(err)
+	ms.False(verrs.HasAny())
+
+	// Their completions are no longer shared with the group
+	ms.NoError(ms.DB.Reload(taken))
+	ms.False(taken.GroupID.Valid)
+	ms.NoError(ms.DB.Reload(kept))
+	ms.True(kept.GroupID.Valid)
+
+	_, err = FindMembership(ms.DB, g.ID, member)
+	ms.Error(err)
+}
+
+func (ms *ModelSuite) Test_Group_Totals() {
+	owner := ms.createUser("owner@example.com")
+	member := ms.createUser("member@example.com")
+	g := ms.createGroup(owner, map[*User]Role{member: RoleViewer})
+
+	ms.shareWith(g, owner, "Severance", StatusInProgress)
+	ms.shareWith(g, owner, "Andor", StatusCompleted)
+	ms.shareWith(g, member, "Shogun", StatusPlanned)
+	book := &Completion{Name: "Dune", Type: CompletionTypeBook, Status: StatusCompleted, CompletedAt: time.Now(), UserID: nulls.NewUUID(member.ID), GroupID: nulls.NewUUID(g.ID)}
+	ms.NoError(ms.DB.Create(book))
+	trashed := ms.shareWith(g, owner, "Gone", StatusCompleted)
+	ms.NoError(trashed.Trash(ms.DB))
+
+	ms.NoError(ms.DB.Load(g, "Memberships.User"))
+	ms.NoError(g.LoadCompletions(ms.DB))
+	ms.Len(g.Completions, 4)
+	ms.Equal(2, g.Finished())
+	ms.Equal(50, g.PercentComplete())
+
+	totals := g.Totals()
+	ms.Len(totals, 2)
+	ms.Equal(CompletionTypeTVShow, totals[0].Type)
+	ms.Equal(3, totals[0].Count)
+	ms.Equal(1, totals[0].InProgress)
+	ms.Equal(1, totals[0].Completed)
+	ms.Equal(CompletionTypeBook, totals[1].Type)
+
+	members := g.MemberTotals()
+	ms.Len(members, 2)
+	ms.Equal("owner@example.com", members[0].Email)
+	ms.Equal(RoleOwner, members[0].Role)
+	ms.Equal(2, members[0].Count)
+	ms.Equal(1, members[0].Completed)
+	ms.Equal("member@example.com", members[1].Email)
+	ms.Equal(2, members[1].Count)
+	ms.Equal("member@example.com", g.SharedBy(*book))
+}
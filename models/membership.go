@@ -0,0 +1,211 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gobuffalo/pop/v6"
+	"github.com/gobuffalo/validate/v3"
+	"github.com/gobuffalo/validate/v3/validators"
+	"github.com/gofrs/uuid"
+)
+
+// ErrNotInvited is returned by GroupInvite.Accept for a User whose email
+// the invite wasn't sent to.
+var ErrNotInvited = errors.New("the invite is for someone else")
+
+// Membership makes a User a member of a Group with a Role. Every Group
+// keeps at least one owner.
+type Membership struct {
+	ID        uuid.UUID `json:"id" db:"id"`
+	GroupID   uuid.UUID `json:"group_id" db:"group_id"`
+	UserID    uuid.UUID `json:"user_id" db:"user_id"`
+	Role      Role      `json:"role" db:"role"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+
+	User  *User  `json:"user,omitempty" db:"-" belongs_to:"users"`
+	Group *Group `json:"group,omitempty" db:"-" belongs_to:"groups"`
+}
+
+// String is not required by pop and may be deleted
+func (m Membership) String() string {
+	jm, _ := json.Marshal(m)
+	return string(jm)
+}
+
+// Memberships is not required by pop and may be deleted
+type Memberships []Membership
+
+// String is not required by pop and may be deleted
+func (m Memberships) String() string {
+	jm, _ := json.Marshal(m)
+	return string(jm)
+}
+
+// Validate gets run every time you call a "pop.Validate*" (pop.ValidateAndSave, pop.ValidateAndCreate, pop.ValidateAndUpdate) method.
+// The Role must be one of the known roles.
+func (m *Membership) Validate(tx *pop.Connection) (*validate.Errors, error) {
+	return validate.Validate(
+		&validators.FuncValidator{
+			Fn:      func() bool { return m.Role.IsValid() },
+			Field:   string(m.Role),
+			Name:    "Role",
+			Message: "%s is not a valid role.",
+		},
+	), nil
+}
+
+// ValidateUpdate gets run every time you call "pop.ValidateAndUpdate" method.
+// The last owner of a Group can't step down.
+func (m *Membership) ValidateUpdate(tx *pop.Connection) (*validate.Errors, error) {
+	verrs := validate.NewErrors()
+	if m.Role == RoleOwner {
+		return verrs, nil
+	}
+	owned, err := m.othersOwn(tx)
+	if err != nil {
+		return verrs, err
+	}
+	if !owned {
+		verrs.Add("role", "A group needs an owner, so make someone else one first.")
+	}
+	return verrs, nil
+}
+
+// othersOwn reports whether the Group has an owner besides m.
+func (m *Membership) othersOwn(tx *pop.Connection) (bool, error) {
+	return tx.Where("group_id = ? AND role = ? AND id <> ?", m.GroupID, RoleOwner, m.ID).Exists(&Membership{})
+}
+
+// Remove takes the member out of the Group, unless they are its last
+// owner. The completions they shared with it go back to being only
+// theirs.
+func (m *Membership) Remove(tx *pop.Connection) (*validate.Errors, error) {
+	verrs := validate.NewErrors()
+	owned, err := m.othersOwn(tx)
+	if err != nil {
+		return verrs, err
+	}
+	if !owned {
+		verrs.Add("role", "A group needs an owner, so make someone else one first.")
+		return verrs, nil
+	}
+
+	err = tx.RawQuery("UPDATE completions SET group_id = NULL WHERE group_id = ? AND user_id = ?", m.GroupID, m.UserID).Exec()
+	if err != nil {
+		return verrs, err
+	}
+	return verrs, tx.Destroy(m)
+}
+
+// FindMembership loads user's Membership of the Group with groupID.
+func FindMembership(tx *pop.Connection, groupID interface{}, user *User) (*Membership, error) {
+	m := &Membership{}
+	if err := tx.Where("group_id = ? AND user_id = ?", groupID, user.ID).First(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// MembershipsOf loads user's Memberships with their Groups, by group name.
+func MembershipsOf(tx *pop.Connection, user *User) (Memberships, error) {
+	memberships := Memberships{}
+	err := tx.Eager("Group").
+		Where("memberships.user_id = ?", user.ID).
+		Join("groups", "groups.id = memberships.group_id").
+		Order("groups.name asc").
+		All(&memberships)
+	return memberships, err
+}
+
+// GroupInvite invites whoever signs in with Email to join a Group with a
+// Role. Accepting it makes them a member.
+type GroupInvite struct {
+	ID        uuid.UUID `json:"id" db:"id"`
+	GroupID   uuid.UUID `json:"group_id" db:"group_id"`
+	Email     string    `json:"email" db:"email"`
+	Role      Role      `json:"role" db:"role"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+
+	Group *Group `json:"group,omitempty" db:"-" belongs_to:"groups"`
+}
+
+// String is not required by pop and may be deleted
+func (i GroupInvite) String() string {
+	ji, _ := json.Marshal(i)
+	return string(ji)
+}
+
+// GroupInvites is not required by pop and may be deleted
+type GroupInvites []GroupInvite
+
+// String is not required by pop and may be deleted
+func (i GroupInvites) String() string {
+	ji, _ := json.Marshal(i)
+	return string(ji)
+}
+
+// Validate gets run every time you call a "pop.Validate*" (pop.ValidateAndSave, pop.ValidateAndCreate, pop.ValidateAndUpdate) method.
+// Nobody can be invited twice, or once they are a member.
+func (i *GroupInvite) Validate(tx *pop.Connection) (*validate.Errors, error) {
+	verrs := validate.Validate(
+		&validators.EmailIsPresent{Field: i.Email, Name: "Email"},
+		&validators.FuncValidator{
+			Fn:      func() bool { return i.Role.IsValid() },
+			Field:   string(i.Role),
+			Name:    "Role",
+			Message: "%s is not a valid role.",
+		},
+	)
+
+	invited, err := tx.Where("group_id = ? AND email = ? AND id <> ?", i.GroupID, i.Email, i.ID).Exists(&GroupInvite{})
+	if err != nil {
+		return verrs, err
+	}
+	if invited {
+		verrs.Add("email", "They have already been invited.")
+	}
+
+	member, err := tx.Where("memberships.group_id = ? AND users.email = ?", i.GroupID, i.Email).
+		Join("users", "users.id = memberships.user_id").
+		Exists(&Membership{})
+	if err != nil {
+		return verrs, err
+	}
+	if member {
+		verrs.Add("email", "They are already a member.")
+	}
+	return verrs, nil
+}
+
+// BeforeValidate normalizes the Email, which is matched case-insensitively
+// against the email of whoever accepts the invite.
+func (i *GroupInvite) BeforeValidate(tx *pop.Connection) error {
+	i.Email = strings.ToLower(strings.TrimSpace(i.Email))
+	return nil
+}
+
+// Accept makes user a member of the Group with the invite's Role, provided
+// the invite was sent to their email. The invite is used up.
+func (i *GroupInvite) Accept(tx *pop.Connection, user *User) (*Membership, error) {
+	if user.Email != i.Email {
+		return nil, ErrNotInvited
+	}
+
+	m := &Membership{GroupID: i.GroupID, UserID: user.ID, Role: i.Role}
+	if err := tx.Create(m); err != nil {
+		return nil, err
+	}
+	return m, tx.Destroy(i)
+}
+
+// InvitesFor loads the invites waiting for user, with their Groups.
+func InvitesFor(tx *pop.Connection, user *User) (GroupInvites, error) {
+	invites := GroupInvites{}
+	err := tx.Eager("Group").Where("email = ?", user.Email).Order("created_at asc").All(&invites)
+	return invites, err
+}
@@ -11,6 +11,22 @@ type ModelSuite struct {
 	*suite.Model
 }
 
+// SetupTest clears the database, then puts back the built-in completion
+// types that the migrations seed.
+func (ms *ModelSuite) SetupTest() {
+	ms.Model.SetupTest()
+	ms.NoError(SeedCompletionTypes(ms.DB))
+}
+
+// createUser signs up a User with email.
+func (ms *ModelSuite) createUser(email string) *User {
+	u := &User{Email: email, Password: "correct horse", PasswordConfirmation: "correct horse"}
+	verrs, err := ms.DB.ValidateAndCreate(u)
+	ms.NoError(err)
+	ms.False(verrs.HasAny())
+	return u
+}
+
 func Test_ModelSuite(t *testing.T) {
 	model, err := suite.NewModelWithFixtures(os.DirFS("../fixtures"))
 	if err != nil {
@@ -0,0 +1,47 @@
+package models
+
+import "github.com/gobuffalo/pop/v6"
+
+// Privacy is who besides its owner can see a Completion
+type Privacy string
+
+const (
+	// PrivacyPrivate completions are only ever shown to their owner.
+	PrivacyPrivate Privacy = "private"
+	// PrivacyUnlisted completions can be seen by anyone with the link,
+	// but aren't listed on the owner's profile.
+	PrivacyUnlisted Privacy = "unlisted"
+	// PrivacyPublic completions are listed on the owner's profile.
+	PrivacyPublic Privacy = "public"
+)
+
+// GetPrivacyLevels returns all available privacy levels, most private first
+func GetPrivacyLevels() []Privacy {
+	return []Privacy{
+		PrivacyPrivate,
+		PrivacyUnlisted,
+		PrivacyPublic,
+	}
+}
+
+// IsValid reports whether p is one of the known privacy levels.
+func (p Privacy) IsValid() bool {
+	for _, level := range GetPrivacyLevels() {
+		if p == level {
+			return true
+		}
+	}
+	return false
+}
+
+// PubliclyListed scopes a query to the completions listed on their
+// owners' profiles: public ones that are not in the trash.
+func PubliclyListed(q *pop.Query) *pop.Query {
+	return q.Where("completions.privacy = ?", PrivacyPublic).Where("completions.deleted_at IS NULL")
+}
+
+// PubliclyVisible scopes a query to the completions anyone may see by
+// their link: public and unlisted ones that are not in the trash.
+func PubliclyVisible(q *pop.Query) *pop.Query {
+	return q.Where("completions.privacy IN (?, ?)", PrivacyPublic, PrivacyUnlisted).Where("completions.deleted_at IS NULL")
+}
@@ -0,0 +1,138 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/gobuffalo/pop/v6"
+	"github.com/gofrs/uuid"
+)
+
+// PublicCompletion is what anyone can see of a Completion that has been
+// shared. It leaves out who owns it and everything else that is only the
+// owner's business.
+type PublicCompletion struct {
+	ID              uuid.UUID      `json:"id"`
+	Name            string         `json:"name"`
+	Type            CompletionType `json:"type"`
+	Status          Status         `json:"status"`
+	Completions     int            `json:"completions"`
+	Target          int            `json:"target"`
+	PercentComplete int            `json:"percent_complete"`
+	CompletedAt     time.Time      `json:"completed_at"`
+	Rating          *float64       `json:"rating"`
+	Review          string         `json:"review"`
+
+	completion Completion
+}
+
+// Public is the shared view of the Completion.
+func (c Completion) Public() PublicCompletion {
+	p := PublicCompletion{
+		ID:              c.ID,
+		Name:            c.Name,
+		Type:            c.Type,
+		Status:          c.Status,
+		Completions:     c.Completions,
+		Target:          c.Target,
+		PercentComplete: c.PercentComplete(),
+		CompletedAt:     c.CompletedAt,
+		Review:          c.Review,
+		completion:      c,
+	}
+	if c.Rating.Valid {
+		rating := c.Rating.Float64
+		p.Rating = &rating
+	}
+	return p
+}
+
+// Completion is the Completion p shows, for the helpers its templates use.
+func (p PublicCompletion) Completion() Completion {
+	return p.completion
+}
+
+// CompletionGroup is the completions of one type on a Profile.
+type CompletionGroup struct {
+	Type        CompletionType     `json:"type"`
+	Completions []PublicCompletion `json:"completions"`
+}
+
+// Profile is a User's public page: their public completions grouped by
+// type, with the built-in types first and the most recent first in each.
+type Profile struct {
+	Username string            `json:"username"`
+	Year     int               `json:"year,omitempty"`
+	Groups   []CompletionGroup `json:"groups"`
+}
+
+// LoadProfile loads the Profile of the User with username, keeping to the
+// completions completed in year unless it is zero. Nothing but public
+// completions is ever loaded.
+func LoadProfile(tx *pop.Connection, username string, year int) (*Profile, error) {
+	// Users who haven't chosen a username don't have a profile
+	if username == "" {
+		return nil, sql.ErrNoRows
+	}
+
+	u := &User{}
+	if err := tx.Where("username = ?", username).First(u); err != nil {
+		return nil, err
+	}
+
+	q := tx.Scope(PubliclyListed).Where("completions.user_id = ?", u.ID)
+	if year != 0 {
+		q = q.Where("EXTRACT(YEAR FROM completions.completed_at) = ?", year)
+	}
+
+	completions := Completions{}
+	if err := q.Order("completed_at desc, name asc").All(&completions); err != nil {
+		return nil, err
+	}
+
+	// Group them in the order the types are listed in, followed by any
+	// whose type has since been removed.
+	var order []CompletionType
+	for _, t := range GetCompletionTypes() {
+		order = append(order, t.Name)
+	}
+	byType := map[CompletionType][]PublicCompletion{}
+	for _, c := range completions {
+		if _, ok := byType[c.Type]; !ok && !containsType(order, c.Type) {
+			order = append(order, c.Type)
+		}
+		byType[c.Type] = append(byType[c.Type], c.Public())
+	}
+
+	profile := &Profile{Username: u.Username, Year: year, Groups: []CompletionGroup{}}
+	for _, t := range order {
+		if len(byType[t]) > 0 {
+			profile.Groups = append(profile.Groups, CompletionGroup{Type: t, Completions: byType[t]})
+		}
+	}
+	return profile, nil
+}
+
+// containsType reports whether types includes t.
+func containsType(types []CompletionType, t CompletionType) bool {
+	for _, name := range types {
+		if name == t {
+			return true
+		}
+	}
+	return false
+}
+
+// FindSharedCompletion loads the Completion with id of the User with
+// username, provided it is public or unlisted.
+func FindSharedCompletion(tx *pop.Connection, username string, id string) (*Completion, error) {
+	c := &Completion{}
+	err := tx.Scope(PubliclyVisible).
+		Join("users", "users.id = completions.user_id").
+		Where("users.username = ?", username).
+		Find(c, id)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
@@ -0,0 +1,100 @@
+package models
+
+import (
+	"time"
+
+	"github.com/gobuffalo/nulls"
+)
+
+// createShared records a completion of u's with privacy.
+func (ms *ModelSuite) createShared(u *User, name string, t CompletionType, privacy Privacy, completedAt time.Time) *Completion {
+	c := &Completion{Name: name, Type: t, CompletedAt: completedAt, Privacy: privacy, UserID: nulls.NewUUID(u.ID)}
+	verrs, err := ms.DB.ValidateAndCreate(c)
+	ms.NoError(err)
+	ms.False(verrs.HasAny())
+	return c
+}
+
+func (ms *ModelSuite) Test_Completion_Privacy() {
+	c := &Completion{Name: "Dune", Type: CompletionTypeBook, CompletedAt: time.Now()}
+	verrs, err := ms.DB.ValidateAndCreate(c)
+	ms.NoError(err)
+	ms.False(verrs.HasAny())
+	ms.Equal(PrivacyPrivate, c.Privacy)
+
+	c.Privacy = "friends"
+	verrs, err = ms.DB.ValidateAndUpdate(c)
+	ms.NoError(err)
+	ms.NotEmpty(verrs.Get("privacy"))
+}
+
+func (ms *ModelSuite) Test_LoadProfile() {
+	u := ms.createUser("reader@example.com")
+	u.Username = "reader"
+	ms.NoError(ms.DB.Update(u))
+
+	thisYear := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	lastYear := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	ms.createShared(u, "Dune", CompletionTypeBook, PrivacyPublic, thisYear)
+	ms.createShared(u, "Emma", CompletionTypeBook, PrivacyPublic, lastYear)
+	ms.createShared(u, "Hades", CompletionTypeVideoGame, PrivacyPublic, thisYear)
+	ms.createShared(u, "Diary", CompletionTypeBook, PrivacyPrivate, thisYear)
+	ms.createShared(u, "Draft", CompletionTypeBook, PrivacyUnlisted, thisYear)
+	trashed := ms.createShared(u, "Gone", CompletionTypeBook, PrivacyPublic, thisYear)
+	ms.NoError(trashed.Trash(ms.DB))
+
+	profile, err := LoadProfile(ms.DB, "reader", 0)
+	ms.NoError(err)
+	ms.Equal("reader", profile.Username)
+	ms.Len(profile.Groups, 2)
+
+	// Only public completions are listed, grouped by type with the
+	// built-in types in order and the latest first
+	ms.Equal(CompletionTypeVideoGame, profile.Groups[0].Type)
+	ms.Equal(CompletionTypeBook, profile.Groups[1].Type)
+	ms.Len(profile.Groups[1].Completions, 2)
+	ms.Equal("Dune", profile.Groups[1].Completions[0].Name)
+	ms.Equal("Emma", profile.Groups[1].Completions[1].Name)
+
+	profile, err = LoadProfile(ms.DB, "reader", 2024)
+	ms.NoError(err)
+	ms.Len(profile.Groups, 1)
+	ms.Len(profile.Groups[0].Completions, 1)
+	ms.Equal("Emma", profile.Groups[0].Completions[0].Name)
+
+	_, err = LoadProfile(ms.DB, "nobody", 0)
+	ms.Error(err)
+
+	// Users without a username have no profile
+	ms.createUser("stranger@example.com")
+	_, err = LoadProfile(ms.DB, "", 0)
+	ms.Error(err)
+}
+
+func (ms *ModelSuite) Test_FindSharedCompletion() {
+	u := ms.createUser("reader@example.com")
+	u.Username = "reader"
+	ms.NoError(ms.DB.Update(u))
+
+	public := ms.createShared(u, "Dune", CompletionTypeBook, PrivacyPublic, time.Now())
+	unlisted := ms.createShared(u, "Draft", CompletionTypeBook, PrivacyUnlisted, time.Now())
+	private := ms.createShared(u, "Diary", CompletionTypeBook, PrivacyPrivate, time.Now())
+
+	found, err := FindSharedCompletion(ms.DB, "reader", public.ID.String())
+	ms.NoError(err)
+	ms.Equal(public.ID, found.ID)
+
+	found, err = FindSharedCompletion(ms.DB, "reader", unlisted.ID.String())
+	ms.NoError(err)
+	ms.Equal(unlisted.ID, found.ID)
+
+	_, err = FindSharedCompletion(ms.DB, "reader", private.ID.String())
+	ms.Error(err)
+
+	// A completion is only found under its owner's username
+	stranger := ms.createUser("stranger@example.com")
+	stranger.Username = "stranger"
+	ms.NoError(ms.DB.Update(stranger))
+	_, err = FindSharedCompletion(ms.DB, "stranger", public.ID.String())
+	ms.Error(err)
+}
@@ -0,0 +1,156 @@
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gobuffalo/pop/v6"
+	"github.com/gobuffalo/validate/v3"
+	"github.com/gobuffalo/validate/v3/validators"
+	"github.com/gofrs/uuid"
+)
+
+// ProgressEntry logs Units of progress made during one Run of a Completion
+// at LoggedAt, in the unit of the Completion's type. Units may be negative
+// to correct an earlier entry.
+//
+// Saving or destroying an entry recalculates its Run's Completions, and
+// the Completion's Completions and CompletedAt from its latest Run.
+type ProgressEntry struct {
+	ID           uuid.UUID `json:"id" db:"id"`
+	CompletionID uuid.UUID `json:"completion_id" db:"completion_id"`
+	RunID        uuid.UUID `json:"run_id" db:"run_id"`
+	Units        int       `json:"units" db:"units"`
+	LoggedAt     time.Time `json:"logged_at" db:"logged_at"`
+	Note         string    `json:"note" db:"note"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// String is not required by pop and may be deleted
+func (p ProgressEntry) String() string {
+	jp, _ := json.Marshal(p)
+	return string(jp)
+}
+
+// ProgressEntries is not required by pop and may be deleted
+type ProgressEntries []ProgressEntry
+
+// String is not required by pop and may be deleted
+func (p ProgressEntries) String() string {
+	jp, _ := json.Marshal(p)
+	return string(jp)
+}
+
+// Validate gets run every time you call a "pop.Validate*" (pop.ValidateAndSave, pop.ValidateAndCreate, pop.ValidateAndUpdate) method.
+// It also checks that the entry keeps its Run's count between zero and
+// the Completion's Target.
+func (p *ProgressEntry) Validate(tx *pop.Connection) (*validate.Errors, error) {
+	verrs := validate.Validate(
+		&validators.UUIDIsPresent{Field: p.CompletionID, Name: "CompletionID"},
+		&validators.UUIDIsPresent{Field: p.RunID, Name: "RunID"},
+		&validators.TimeIsPresent{Field: p.LoggedAt, Name: "LoggedAt"},
+		&validators.FuncValidator{
+			Field:   fmt.Sprint(p.Units),
+			Name:    "Units",
+			Message: "%s can't be zero.",
+			Fn: func() bool {
+				return p.Units != 0
+			},
+		},
+	)
+	if verrs.HasAny() {
+		return verrs, nil
+	}
+
+	completion := &Completion{}
+	if err := tx.Select("target").Find(completion, p.CompletionID); err != nil {
+		return verrs, err
+	}
+	// The Run must be one of the Completion's
+	run := &Run{}
+	err := tx.Where("completion_id = ?", p.CompletionID).Find(run, p.RunID)
+	if errors.Is(err, sql.ErrNoRows) {
+		verrs.Add("run_id", "RunID is not a run of this completion.")
+		return verrs, nil
+	}
+	if err != nil {
+		return verrs, err
+	}
+
+	persisted := 0
+	if p.ID != uuid.Nil {
+		previous := &ProgressEntry{}
+		if err := tx.Select("units").Find(previous, p.ID); err != nil {
+			return verrs, err
+		}
+		persisted = previous.Units
+	}
+
+	total := run.Completions - persisted + p.Units
+	if total < 0 {
+		verrs.Add("units", "Units can't take the total below zero.")
+	}
+	if completion.HasTarget() && total > completion.Target {
+		verrs.Add("units", "Units can't take the total past the target.")
+	}
+	return verrs, nil
+}
+
+// BeforeValidate logs the entry as of now against the Completion's latest
+// Run, unless a time or Run was given.
+func (p *ProgressEntry) BeforeValidate(tx *pop.Connection) error {
+	if p.LoggedAt.IsZero() {
+		p.LoggedAt = time.Now()
+	}
+	if p.RunID == uuid.Nil && p.CompletionID != uuid.Nil {
+		run, err := currentRun(tx, p.CompletionID)
+		if err != nil {
+			return err
+		}
+		p.RunID = run.ID
+	}
+	return nil
+}
+
+// AfterCreate recalculates the Completion's progress.
+func (p *ProgressEntry) AfterCreate(tx *pop.Connection) error {
+	return refreshProgress(tx, p.RunID)
+}
+
+// AfterUpdate recalculates the Completion's progress.
+func (p *ProgressEntry) AfterUpdate(tx *pop.Connection) error {
+	return refreshProgress(tx, p.RunID)
+}
+
+// AfterDestroy recalculates the Completion's progress.
+func (p *ProgressEntry) AfterDestroy(tx *pop.Connection) error {
+	return refreshProgress(tx, p.RunID)
+}
+
+// refreshProgress sets a Run's Completions to the sum of its entries, then
+// copies the latest Run's count to its Completion along with the time of
+// that Run's latest entry. It writes the columns directly so the
+// Completion's own callbacks don't log the change a second time.
+func refreshProgress(tx *pop.Connection, runID uuid.UUID) error {
+	now := time.Now()
+	err := tx.RawQuery(`UPDATE runs SET
+		completions = (SELECT COALESCE(SUM(units), 0) FROM progress_entries WHERE run_id = ?),
+		updated_at = ?
+		WHERE id = ?`, runID, now, runID).Exec()
+	if err != nil {
+		return err
+	}
+
+	return tx.RawQuery(`UPDATE completions SET
+		completions = latest.completions,
+		completed_at = COALESCE((SELECT MAX(logged_at) FROM progress_entries WHERE run_id = latest.id), completions.completed_at),
+		updated_at = ?
+		FROM (SELECT DISTINCT ON (completion_id) id, completion_id, completions FROM runs
+			WHERE completion_id = (SELECT completion_id FROM runs WHERE id = ?)
+			ORDER BY completion_id, number DESC) AS latest
+		WHERE completions.id = latest.completion_id`, now, runID).Exec()
+}
@@ -0,0 +1,106 @@
+package models
+
+import "time"
+
+func (ms *ModelSuite) Test_ProgressEntry() {
+	c := &Completion{
+		Name:        "Dune",
+		Type:        CompletionTypeBook,
+		Completions: 10,
+		Target:      100,
+		CompletedAt: time.Now().Add(-48 * time.Hour),
+	}
+	verrs, err := ms.DB.ValidateAndCreate(c)
+	ms.NoError(err)
+	ms.False(verrs.HasAny())
+
+	entries := ProgressEntries{}
+	ms.NoError(ms.DB.Where("completion_id = ?", c.ID).All(&entries))
+	ms.Len(entries, 1)
+	ms.Equal(10, entries[0].Units)
+
+	loggedAt := time.Now().Add(-time.Hour).Truncate(time.Second)
+	entry := &ProgressEntry{CompletionID: c.ID, Units: 25, LoggedAt: loggedAt, Note: "Long flight"}
+	verrs, err = ms.DB.ValidateAndCreate(entry)
+	ms.NoError(err)
+	ms.False(verrs.HasAny())
+
+	ms.NoError(ms.DB.Reload(c))
+	ms.Equal(35, c.Completions)
+	ms.True(loggedAt.Equal(c.CompletedAt.Truncate(time.Second)))
+
+	entry.Units = 5
+	verrs, err = ms.DB.ValidateAndUpdate(entry)
+	ms.NoError(err)
+	ms.False(verrs.HasAny())
+	ms.NoError(ms.DB.Reload(c))
+	ms.Equal(15, c.Completions)
+
+	ms.NoError(ms.DB.Destroy(entry))
+	ms.NoError(ms.DB.Reload(c))
+	ms.Equal(10, c.Completions)
+}
+
+func (ms *ModelSuite) Test_ProgressEntry_Validation() {
+	c := &Completion{
+		Name:        "Dune",
+		Type:        CompletionTypeBook,
+		Completions: 10,
+		Target:      100,
+		CompletedAt: time.Now(),
+	}
+	verrs, err := ms.DB.ValidateAndCreate(c)
+	ms.NoError(err)
+	ms.False(verrs.HasAny())
+
+	entry := &ProgressEntry{CompletionID: c.ID}
+	verrs, err = ms.DB.ValidateAndCreate(entry)
+	ms.NoError(err)
+	ms.NotEmpty(verrs.Get("units"))
+	ms.False(entry.LoggedAt.IsZero())
+
+	entry.Units = 91
+	verrs, err = ms.DB.ValidateAndCreate(entry)
+	ms.NoError(err)
+	ms.NotEmpty(verrs.Get("units"))
+
+	entry.Units = -11
+	verrs, err = ms.DB.ValidateAndCreate(entry)
+	ms.NoError(err)
+	ms.NotEmpty(verrs.Get("units"))
+
+	// Nor can it be logged against a run of another completion
+	other := &Completion{Name: "Emma", Type: CompletionTypeBook, CompletedAt: time.Now()}
+	ms.NoError(ms.DB.Create(other))
+	run, err := other.CurrentRun(ms.DB)
+	ms.NoError(err)
+
+	entry.Units = 5
+	entry.RunID = run.ID
+	verrs, err = ms.DB.ValidateAndCreate(entry)
+	ms.NoError(err)
+	ms.NotEmpty(verrs.Get("run_id"))
+}
+
+func (ms *ModelSuite) Test_Completion_LogsProgress() {
+	c := &Completion{
+		Name:        "Severance",
+		Type:        CompletionTypeTVShow,
+		Completions: 2,
+		CompletedAt: time.Now(),
+	}
+	verrs, err := ms.DB.ValidateAndCreate(c)
+	ms.NoError(err)
+	ms.False(verrs.HasAny())
+
+	c.Completions = 5
+	verrs, err = ms.DB.ValidateAndUpdate(c)
+	ms.NoError(err)
+	ms.False(verrs.HasAny())
+
+	entries := ProgressEntries{}
+	ms.NoError(ms.DB.Where("completion_id = ?", c.ID).Order("created_at asc").All(&entries))
+	ms.Len(entries, 2)
+	ms.Equal(2, entries[0].Units)
+	ms.Equal(3, entries[1].Units)
+}
@@ -0,0 +1,153 @@
+package models
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gobuffalo/pop/v6"
+	"github.com/gobuffalo/validate/v3"
+	"github.com/gobuffalo/validate/v3/validators"
+	"github.com/gofrs/uuid"
+)
+
+// RevisionAction is what was done to a Completion in a Revision.
+type RevisionAction string
+
+const (
+	RevisionCreated  RevisionAction = "created"
+	RevisionUpdated  RevisionAction = "updated"
+	RevisionDeleted  RevisionAction = "deleted"
+	RevisionRestored RevisionAction = "restored"
+)
+
+// ErrRevisionImmutable is returned when a Revision would be changed or
+// deleted. Revisions only go when their Completion is purged.
+var ErrRevisionImmutable = errors.New("revisions can't be changed or deleted")
+
+// FieldChange is the value of one Completion field before and after a
+// Revision. From is empty for a field set when the Completion was
+// created, and To is empty for a field that was cleared.
+type FieldChange struct {
+	Field string `json:"field" xml:"field,attr"`
+	From  string `json:"from" xml:"From"`
+	To    string `json:"to" xml:"To"`
+}
+
+// FieldChanges is stored as JSON in a single column.
+type FieldChanges []FieldChange
+
+// Value implements driver.Valuer.
+func (f FieldChanges) Value() (driver.Value, error) {
+	if f == nil {
+		f = FieldChanges{}
+	}
+	b, err := json.Marshal(f)
+	return string(b), err
+}
+
+// Scan implements sql.Scanner.
+func (f *FieldChanges) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case nil:
+		*f = FieldChanges{}
+		return nil
+	case []byte:
+		return json.Unmarshal(src, f)
+	case string:
+		return json.Unmarshal([]byte(src), f)
+	default:
+		return fmt.Errorf("can't scan %T into FieldChanges", src)
+	}
+}
+
+// Revision is an immutable record of a Completion being created, updated,
+// moved to the trash or restored: the fields that changed and who changed
+// them. CreatedAt is when it happened.
+type Revision struct {
+	ID           uuid.UUID      `json:"id" db:"id"`
+	CompletionID uuid.UUID      `json:"completion_id" db:"completion_id"`
+	Action       RevisionAction `json:"action" db:"action"`
+	Actor        string         `json:"actor" db:"actor"`
+	Changes      FieldChanges   `json:"changes" db:"changes"`
+	CreatedAt    time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at" db:"updated_at"`
+}
+
+// String is not required by pop and may be deleted
+func (r Revision) String() string {
+	jr, _ := json.Marshal(r)
+	return string(jr)
+}
+
+// Revisions is not required by pop and may be deleted
+type Revisions []Revision
+
+// String is not required by pop and may be deleted
+func (r Revisions) String() string {
+	jr, _ := json.Marshal(r)
+	return string(jr)
+}
+
+// Validate gets run every time you call a "pop.Validate*" (pop.ValidateAndSave, pop.ValidateAndCreate, pop.ValidateAndUpdate) method.
+// This method is not required and may be deleted.
+func (r *Revision) Validate(tx *pop.Connection) (*validate.Errors, error) {
+	return validate.Validate(
+		&validators.UUIDIsPresent{Field: r.CompletionID, Name: "CompletionID"},
+		&validators.StringIsPresent{Field: string(r.Action), Name: "Action"},
+	), nil
+}
+
+// BeforeUpdate keeps a Revision from being changed once recorded.
+func (r *Revision) BeforeUpdate(tx *pop.Connection) error {
+	return ErrRevisionImmutable
+}
+
+// BeforeDestroy keeps a Revision from being deleted on its own.
+func (r *Revision) BeforeDestroy(tx *pop.Connection) error {
+	return ErrRevisionImmutable
+}
+
+// actorKey is the context key of the actor recorded on revisions.
+type actorKey struct{}
+
+// WithActor returns a copy of ctx naming who makes the changes done
+// through a connection that carries it, as in tx.WithContext(ctx).
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// actorOf returns the actor carried by tx's context, or "system" for
+// changes made outside a request, such as by a task.
+func actorOf(tx *pop.Connection) string {
+	if actor, ok := tx.Context().Value(actorKey{}).(string); ok && actor != "" {
+		return actor
+	}
+	return "system"
+}
+
+// revisionField is a Completion field as recorded in a Revision.
+type revisionField struct {
+	name  string
+	value string
+}
+
+// diffRevisionFields lists the fields whose values differ between before
+// and after, in after's order.
+func diffRevisionFields(before, after []revisionField) FieldChanges {
+	previous := make(map[string]string, len(before))
+	for _, f := range before {
+		previous[f.name] = f.value
+	}
+
+	changes := FieldChanges{}
+	for _, f := range after {
+		if from := previous[f.name]; from != f.value {
+			changes = append(changes, FieldChange{Field: f.name, From: from, To: f.value})
+		}
+	}
+	return changes
+}
@@ -0,0 +1,80 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"github.com/gobuffalo/nulls"
+)
+
+func (ms *ModelSuite) revisions(c *Completion) Revisions {
+	revisions := Revisions{}
+	ms.NoError(ms.DB.Where("completion_id = ?", c.ID).Order("created_at asc").All(&revisions))
+	return revisions
+}
+
+func (ms *ModelSuite) Test_Revision_Create() {
+	tags := "sci-fi"
+	c := &Completion{Name: "Dune", Type: CompletionTypeBook, Target: 412, CompletedAt: time.Now(), TagList: &tags}
+	ms.NoError(ms.DB.Create(c))
+
+	revisions := ms.revisions(c)
+	ms.Len(revisions, 1)
+	ms.Equal(RevisionCreated, revisions[0].Action)
+	ms.Equal("system", revisions[0].Actor)
+
+	changed := map[string]FieldChange{}
+	for _, change := range revisions[0].Changes {
+		changed[change.Field] = change
+	}
+	ms.Equal(FieldChange{Field: "name", From: "", To: "Dune"}, changed["name"])
+	ms.Equal("412", changed["target"].To)
+	ms.Equal("sci-fi", changed["tags"].To)
+	ms.NotContains(changed, "venue")
+}
+
+func (ms *ModelSuite) Test_Revision_Update() {
+	c := &Completion{Name: "Dune", Type: CompletionTypeBook, CompletedAt: time.Now()}
+	ms.NoError(ms.DB.Create(c))
+
+	tx := ms.DB.WithContext(WithActor(context.Background(), "alice"))
+	c.Name = "Dune Messiah"
+	c.Rating = nulls.NewFloat64(4.5)
+	ms.NoError(tx.Update(c))
+
+	// Saving without a change records nothing
+	ms.NoError(tx.Update(c))
+
+	revisions := ms.revisions(c)
+	ms.Len(revisions, 2)
+	ms.Equal(RevisionUpdated, revisions[1].Action)
+	ms.Equal("alice", revisions[1].Actor)
+	ms.Equal(FieldChanges{
+		{Field: "name", From: "Dune", To: "Dune Messiah"},
+		{Field: "rating", From: "", To: "4.5"},
+	}, revisions[1].Changes)
+}
+
+func (ms *ModelSuite) Test_Revision_TrashAndRestore() {
+	c := &Completion{Name: "Dune", Type: CompletionTypeBook, CompletedAt: time.Now()}
+	ms.NoError(ms.DB.Create(c))
+	ms.NoError(c.Trash(ms.DB))
+	ms.NoError(c.Restore(ms.DB))
+
+	revisions := ms.revisions(c)
+	ms.Len(revisions, 3)
+	ms.Equal(RevisionDeleted, revisions[1].Action)
+	ms.Equal("deleted_at", revisions[1].Changes[0].Field)
+	ms.Equal(RevisionRestored, revisions[2].Action)
+	ms.Equal("", revisions[2].Changes[0].To)
+}
+
+func (ms *ModelSuite) Test_Revision_Immutable() {
+	c := &Completion{Name: "Dune", Type: CompletionTypeBook, CompletedAt: time.Now()}
+	ms.NoError(ms.DB.Create(c))
+
+	revision := ms.revisions(c)[0]
+	revision.Actor = "mallory"
+	ms.ErrorIs(ms.DB.Update(&revision), ErrRevisionImmutable)
+	ms.ErrorIs(ms.DB.Destroy(&revision), ErrRevisionImmutable)
+}
@@ -0,0 +1,45 @@
+package models
+
+// Role is what a member of a Group may do with it
+type Role string
+
+const (
+	// RoleOwner members manage the Group: its name, members and invites.
+	RoleOwner Role = "owner"
+	// RoleEditor members can update the Group's completions and add
+	// their own to it.
+	RoleEditor Role = "editor"
+	// RoleViewer members can only see the Group's completions.
+	RoleViewer Role = "viewer"
+)
+
+// GetRoles returns all available roles, most powerful first
+func GetRoles() []Role {
+	return []Role{
+		RoleOwner,
+		RoleEditor,
+		RoleViewer,
+	}
+}
+
+// IsValid reports whether r is one of the known roles.
+func (r Role) IsValid() bool {
+	for _, role := range GetRoles() {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// CanEdit reports whether members with the role can update the Group's
+// completions and add their own.
+func (r Role) CanEdit() bool {
+	return r == RoleOwner || r == RoleEditor
+}
+
+// CanManage reports whether members with the role can change the Group,
+// invite members and change their roles.
+func (r Role) CanManage() bool {
+	return r == RoleOwner
+}
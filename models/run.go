@@ -0,0 +1,70 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/gobuffalo/nulls"
+	"github.com/gobuffalo/pop/v6"
+	"github.com/gobuffalo/validate/v3"
+	"github.com/gobuffalo/validate/v3/validators"
+	"github.com/gofrs/uuid"
+)
+
+// Run is one pass through a Completion: a rewatch, reread or replay.
+// Every Completion starts with run 1, and Completion.StartRun begins the
+// next. Completions is the sum of the run's ProgressEntries, and the
+// Completion's own count always reflects its latest run.
+type Run struct {
+	ID           uuid.UUID  `json:"id" db:"id"`
+	CompletionID uuid.UUID  `json:"completion_id" db:"completion_id"`
+	Number       int        `json:"number" db:"number"`
+	StartedAt    time.Time  `json:"started_at" db:"started_at"`
+	FinishedAt   nulls.Time `json:"finished_at" db:"finished_at"`
+	Completions  int        `json:"completions" db:"completions"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// String is not required by pop and may be deleted
+func (r Run) String() string {
+	jr, _ := json.Marshal(r)
+	return string(jr)
+}
+
+// IsFinished reports whether the run has a finish date.
+func (r Run) IsFinished() bool {
+	return r.FinishedAt.Valid
+}
+
+// Runs is not required by pop and may be deleted
+type Runs []Run
+
+// String is not required by pop and may be deleted
+func (r Runs) String() string {
+	jr, _ := json.Marshal(r)
+	return string(jr)
+}
+
+// Validate gets run every time you call a "pop.Validate*" (pop.ValidateAndSave, pop.ValidateAndCreate, pop.ValidateAndUpdate) method.
+// This method is not required and may be deleted.
+func (r *Run) Validate(tx *pop.Connection) (*validate.Errors, error) {
+	verrs := validate.Validate(
+		&validators.UUIDIsPresent{Field: r.CompletionID, Name: "CompletionID"},
+		&validators.IntIsGreaterThan{Field: r.Number, Name: "Number", Compared: 0},
+		&validators.TimeIsPresent{Field: r.StartedAt, Name: "StartedAt"},
+	)
+	if r.FinishedAt.Valid && r.FinishedAt.Time.Before(r.StartedAt) {
+		verrs.Add("finished_at", "FinishedAt can't be before StartedAt.")
+	}
+	return verrs, nil
+}
+
+// currentRun loads the latest Run of a Completion.
+func currentRun(tx *pop.Connection, completionID uuid.UUID) (*Run, error) {
+	run := &Run{}
+	if err := tx.Where("completion_id = ?", completionID).Order("number desc").First(run); err != nil {
+		return nil, err
+	}
+	return run, nil
+}
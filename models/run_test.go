@@ -0,0 +1,77 @@
+package models
+
+import (
+	"time"
+
+	"github.com/gobuffalo/nulls"
+)
+
+func (ms *ModelSuite) Test_Run() {
+	c := &Completion{
+		Name:        "Outer Wilds",
+		Type:        CompletionTypeVideoGame,
+		Completions: 20,
+		Target:      20,
+		Status:      StatusCompleted,
+		CompletedAt: time.Now(),
+	}
+	verrs, err := ms.DB.ValidateAndCreate(c)
+	ms.NoError(err)
+	ms.False(verrs.HasAny())
+
+	run, err := c.CurrentRun(ms.DB)
+	ms.NoError(err)
+	ms.Equal(1, run.Number)
+	ms.Equal(20, run.Completions)
+	ms.True(run.IsFinished())
+
+	verrs, err = c.StartRun(ms.DB, time.Time{})
+	ms.NoError(err)
+	ms.False(verrs.HasAny())
+	ms.Equal(StatusInProgress, c.Status)
+	ms.Equal(0, c.Completions)
+
+	ms.NoError(ms.DB.Reload(c))
+	ms.Equal(StatusInProgress, c.Status)
+	ms.Equal(0, c.Completions)
+
+	entry := &ProgressEntry{CompletionID: c.ID, Units: 5}
+	verrs, err = ms.DB.ValidateAndCreate(entry)
+	ms.NoError(err)
+	ms.False(verrs.HasAny())
+
+	ms.NoError(ms.DB.Reload(c))
+	ms.Equal(5, c.Completions)
+
+	runs := Runs{}
+	ms.NoError(ms.DB.Where("completion_id = ?", c.ID).Order("number asc").All(&runs))
+	ms.Len(runs, 2)
+	ms.Equal(20, runs[0].Completions)
+	ms.Equal(5, runs[1].Completions)
+	ms.False(runs[1].IsFinished())
+
+	verrs, err = c.StartRun(ms.DB, time.Time{})
+	ms.NoError(err)
+	ms.NotEmpty(verrs.Get("status"))
+
+	c.Status = StatusCompleted
+	c.Completions = 20
+	verrs, err = ms.DB.ValidateAndUpdate(c)
+	ms.NoError(err)
+	ms.False(verrs.HasAny())
+
+	run, err = c.CurrentRun(ms.DB)
+	ms.NoError(err)
+	ms.Equal(2, run.Number)
+	ms.Equal(20, run.Completions)
+	ms.True(run.IsFinished())
+}
+
+func (ms *ModelSuite) Test_Run_Validation() {
+	run := &Run{FinishedAt: nulls.NewTime(time.Now().Add(-time.Hour)), StartedAt: time.Now()}
+	verrs, err := run.Validate(ms.DB)
+	ms.NoError(err)
+	ms.NotEmpty(verrs.Get("completion_id"))
+	ms.NotEmpty(verrs.Get("number"))
+	ms.NotEmpty(verrs.Get("finished_at"))
+}
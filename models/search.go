@@ -0,0 +1,291 @@
+package models
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/gobuffalo/pop/v6"
+	"github.com/gofrs/uuid"
+)
+
+// SearchLimit is the most results a search returns.
+const SearchLimit = 50
+
+// searchSQL ranks the completions a User can see against a search. Names,
+// tags, reviews and progress notes are matched with full-text search, and
+// with trigram similarity so that typos still find something. Matches in
+// the name rank highest, then tags, then the rest. Each completion's tags
+// and notes are copied onto it, and its search_document built from them,
+// by the database as they change, so every match can use an index. It
+// takes the search, the User's ID twice and the limit.
+const searchSQL = `WITH input AS (
+	SELECT q, websearch_to_tsquery('english', q) AS query FROM (SELECT ?::text AS q) raw
+)
+SELECT completions.id, completions.search_tags AS tags, completions.search_notes AS notes,
+	ts_rank(completions.search_document, input.query)
+		+ word_similarity(input.q, completions.name)
+		+ word_similarity(input.q, completions.search_tags) / 2
+		+ word_similarity(input.q, completions.review || ' ' || completions.search_notes) / 4 AS rank
+FROM input, completions
+WHERE completions.deleted_at IS NULL AND ` + visibleToSQL + `
+	AND (completions.search_document @@ input.query
+		OR input.q <% completions.name
+		OR input.q <% completions.search_tags
+		OR input.q <% (completions.review || ' ' || completions.search_notes))
+ORDER BY rank DESC, completions.name ASC
+LIMIT ?`
+
+// searchMatch is a row of searchSQL.
+type searchMatch struct {
+	ID    uuid.UUID `db:"id"`
+	Tags  string    `db:"tags"`
+	Notes string    `db:"notes"`
+	Rank  float64   `db:"rank"`
+}
+
+// HighlightPart is a stretch of text that either matched a search or
+// didn't.
+type HighlightPart struct {
+	Text  string
+	Match bool
+}
+
+// Highlight is some text split into the stretches that matched a search
+// and those that didn't, so the matches can be marked up.
+type Highlight []HighlightPart
+
+// Matched reports whether any of the text matched.
+func (h Highlight) Matched() bool {
+	for _, part := range h {
+		if part.Match {
+			return true
+		}
+	}
+	return false
+}
+
+// SearchResult is a Completion found by Search, with how well it matched
+// and which of its fields did: "name", "tags", "review" or "notes". Name,
+// Tags and Excerpt are those fields highlighted for display; Excerpt is
+// the part of the review or progress notes around the first match.
+type SearchResult struct {
+	Completion Completion `json:"completion"`
+	Rank       float64    `json:"rank"`
+	Matches    []string   `json:"matches"`
+
+	Name    Highlight `json:"-" xml:"-"`
+	Tags    Highlight `json:"-" xml:"-"`
+	Excerpt Highlight `json:"-" xml:"-"`
+}
+
+// SearchResults is not required by pop and may be deleted
+type SearchResults []SearchResult
+
+// Search finds the completions user can see that match q, best first. It
+// searches their names, tags, reviews and progress notes, leaving out any
+// in the trash, and returns at most SearchLimit results. A blank q finds
+// nothing.
+func Search(tx *pop.Connection, user *User, q string) (SearchResults, error) {
+	results := SearchResults{}
+	q = strings.TrimSpace(q)
+	if q == "" {
+		return results, nil
+	}
+
+	matches := []searchMatch{}
+	if err := tx.RawQuery(searchSQL, q, user.ID, user.ID, SearchLimit).All(&matches); err != nil {
+		return results, err
+	}
+	if len(matches) == 0 {
+		return results, nil
+	}
+
+	ids := make([]interface{}, 0, len(matches))
+	for _, m := range matches {
+		ids = append(ids, m.ID)
+	}
+	completions := Completions{}
+	if err := tx.Where("id IN (?)", ids...).All(&completions); err != nil {
+		return results, err
+	}
+	byID := map[uuid.UUID]Completion{}
+	for _, c := range completions {
+		byID[c.ID] = c
+	}
+
+	terms := SearchTerms(q)
+	for _, m := range matches {
+		c, ok := byID[m.ID]
+		if !ok {
+			continue
+		}
+		result := SearchResult{
+			Completion: c,
+			Rank:       m.Rank,
+			Matches:    []string{},
+			Name:       highlight(c.Name, terms),
+			Tags:       highlight(m.Tags, terms),
+		}
+		if result.Name.Matched() {
+			result.Matches = append(result.Matches, "name")
+		}
+		if result.Tags.Matched() {
+			result.Matches = append(result.Matches, "tags")
+		}
+		if review := excerpt(c.Review, terms); review.Matched() {
+			result.Matches = append(result.Matches, "review")
+			result.Excerpt = review
+		}
+		if notes := excerpt(m.Notes, terms); notes.Matched() {
+			result.Matches = append(result.Matches, "notes")
+			if result.Excerpt == nil {
+				result.Excerpt = notes
+			}
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// SearchTerms splits a search into the lower case words it looks for,
+// leaving out those it excludes with a leading "-", the "or" between
+// alternatives and single letters.
+func SearchTerms(q string) []string {
+	var terms []string
+	for _, field := range strings.Fields(strings.ToLower(q)) {
+		if strings.HasPrefix(field, "-") || field == "or" {
+			continue
+		}
+		for _, word := range searchWords(field) {
+			if len([]rune(word)) > 1 {
+				terms = append(terms, word)
+			}
+		}
+	}
+	return terms
+}
+
+// searchWords splits text into its runs of letters and digits.
+func searchWords(text string) []string {
+	return strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// matchesTerm reports whether word is one of the terms, or starts with
+// one, or is close enough to one to be a typo of it.
+func matchesTerm(word string, terms []string) bool {
+	if len(searchWords(word)) != 1 {
+		return false
+	}
+	word = strings.ToLower(word)
+	for _, term := range terms {
+		if strings.HasPrefix(word, term) || trigramSimilarity(word, term) >= 0.5 {
+			return true
+		}
+	}
+	return false
+}
+
+// trigramSimilarity measures how alike two words are the way pg_trgm
+// does: the share of their three letter sequences, padded with spaces,
+// that they have in common.
+func trigramSimilarity(a, b string) float64 {
+	ta, tb := trigrams(a), trigrams(b)
+	shared := 0
+	for t := range ta {
+		if tb[t] {
+			shared++
+		}
+	}
+	total := len(ta) + len(tb) - shared
+	if total == 0 {
+		return 0
+	}
+	return float64(shared) / float64(total)
+}
+
+func trigrams(word string) map[string]bool {
+	runes := []rune("  " + word + " ")
+	set := map[string]bool{}
+	for i := 0; i+3 <= len(runes); i++ {
+		set[string(runes[i:i+3])] = true
+	}
+	return set
+}
+
+// tokens splits text into alternating runs of word and non-word runes,
+// which join back into text.
+func tokens(text string) []string {
+	var out []string
+	start := 0
+	inWord := false
+	for i, r := range text {
+		isWord := unicode.IsLetter(r) || unicode.IsDigit(r)
+		if i > 0 && isWord != inWord {
+			out = append(out, text[start:i])
+			start = i
+		}
+		inWord = isWord
+	}
+	if start < len(text) {
+		out = append(out, text[start:])
+	}
+	return out
+}
+
+// highlightTokens marks the words among toks that match terms, merging
+// the stretches in between.
+func highlightTokens(toks []string, terms []string) Highlight {
+	h := Highlight{}
+	for _, tok := range toks {
+		match := matchesTerm(tok, terms)
+		if n := len(h); n > 0 && !match && !h[n-1].Match {
+			h[n-1].Text += tok
+			continue
+		}
+		h = append(h, HighlightPart{Text: tok, Match: match})
+	}
+	return h
+}
+
+// highlight marks the words of text that match terms.
+func highlight(text string, terms []string) Highlight {
+	return highlightTokens(tokens(text), terms)
+}
+
+// excerptTokens is how much of the text around its first match an excerpt
+// keeps before and after it, counting words and the gaps between them.
+const excerptTokens = 24
+
+// excerpt highlights the part of text around its first match, with an
+// ellipsis where it has been cut short. It is nil when nothing matched.
+func excerpt(text string, terms []string) Highlight {
+	toks := tokens(text)
+	first := -1
+	for i, tok := range toks {
+		if matchesTerm(tok, terms) {
+			first = i
+			break
+		}
+	}
+	if first < 0 {
+		return nil
+	}
+
+	start, end := first-excerptTokens, first+excerptTokens
+	if start < 0 {
+		start = 0
+	}
+	if end > len(toks) {
+		end = len(toks)
+	}
+	h := highlightTokens(toks[start:end], terms)
+	if start > 0 {
+		h = append(Highlight{{Text: "…"}}, h...)
+	}
+	if end < len(toks) {
+		h = append(h, HighlightPart{Text: "…"})
+	}
+	return h
+}
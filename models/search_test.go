@@ -0,0 +1,88 @@
+package models
+
+import (
+	"time"
+
+	"github.com/gobuffalo/nulls"
+)
+
+func (ms *ModelSuite) Test_SearchTerms() {
+	ms.Equal([]string{"the", "hobbit", "tolkien"}, SearchTerms(`"The Hobbit" or -movie Tolkien a`))
+	ms.Empty(SearchTerms("  "))
+}
+
+func (ms *ModelSuite) Test_Highlight() {
+	h := highlight("The Hobbit, again", []string{"hobit"})
+	ms.Equal(Highlight{{Text: "The "}, {Text: "Hobbit", Match: true}, {Text: ", again"}}, h)
+	ms.False(highlight("Dune", []string{"hobbit"}).Matched())
+
+	// Long text is cut down to the words around the first match
+	e := excerpt("one two three four five six seven eight nine ten eleven twelve thirteen fourteen fifteen dragon sixteen", []string{"dragon"})
+	ms.True(e.Matched())
+	ms.Equal("…", e[0].Text)
+	ms.Nil(excerpt("no match here", []string{"dragon"}))
+}
+
+func (ms *ModelSuite) Test_Search() {
+	u := ms.createUser("reader@example.com")
+	add := func(name, review string, tags ...string) *Completion {
+		c := &Completion{Name: name, Type: CompletionTypeBook, Review: review, CompletedAt: time.Now(), UserID: nulls.NewUUID(u.ID)}
+		verrs, err := ms.DB.ValidateAndCreate(c)
+		ms.NoError(err)
+		ms.False(verrs.HasAny())
+		ms.NoError(c.SetTags(ms.DB, tags))
+		return c
+	}
+
+	hobbit := add("The Hobbit", "", "fantasy")
+	dune := add("Dune", "A desert planet and a hobbit-free story.", "sci-fi")
+	emma := add("Emma", "")
+	ms.NoError(ms.DB.Create(&ProgressEntry{CompletionID: dune.ID, Units: 10, Note: "Read on the train"}))
+
+	stranger := ms.createUser("stranger@example.com")
+	ms.NoError(ms.DB.Create(&Completion{Name: "The Hobbit", Type: CompletionTypeBook, CompletedAt: time.Now(), UserID: nulls.NewUUID(stranger.ID)}))
+
+	// A typo still finds it, and a match in the name ranks above one in a
+	// review
+	results, err := Search(ms.DB, u, "hobit")
+	ms.NoError(err)
+	ms.Len(results, 2)
+	ms.Equal(hobbit.ID, results[0].Completion.ID)
+	ms.Equal([]string{"name"}, results[0].Matches)
+	ms.Equal([]string{"review"}, results[1].Matches)
+
+	results, err = Search(ms.DB, u, "fantasy")
+	ms.NoError(err)
+	ms.Len(results, 1)
+	ms.Equal([]string{"tags"}, results[0].Matches)
+
+	results, err = Search(ms.DB, u, "trains")
+	ms.NoError(err)
+	ms.Len(results, 1)
+	ms.Equal(dune.ID, results[0].Completion.ID)
+	ms.Equal([]string{"notes"}, results[0].Matches)
+
+	// Tags and notes are found as soon as they change
+	ms.NoError(ms.DB.RawQuery("UPDATE tags SET name = 'epic' WHERE name = 'fantasy'").Exec())
+	results, err = Search(ms.DB, u, "epic")
+	ms.NoError(err)
+	ms.Len(results, 1)
+	ms.Equal(hobbit.ID, results[0].Completion.ID)
+
+	ms.NoError(ms.DB.Create(&ProgressEntry{CompletionID: emma.ID, Units: 5, Note: "Finished at the beach"}))
+	results, err = Search(ms.DB, u, "beach")
+	ms.NoError(err)
+	ms.Len(results, 1)
+	ms.Equal(emma.ID, results[0].Completion.ID)
+
+	// Nothing in the trash is found
+	ms.NoError(ms.DB.RawQuery("UPDATE completions SET deleted_at = now() WHERE id = ?", hobbit.ID).Exec())
+	results, err = Search(ms.DB, u, "hobbit")
+	ms.NoError(err)
+	ms.Len(results, 1)
+	ms.Equal(dune.ID, results[0].Completion.ID)
+
+	results, err = Search(ms.DB, u, " ")
+	ms.NoError(err)
+	ms.Empty(results)
+}
@@ -0,0 +1,101 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gobuffalo/pop/v6"
+	"github.com/gobuffalo/validate/v3"
+	"github.com/gobuffalo/validate/v3/validators"
+	"github.com/gofrs/uuid"
+)
+
+// Season groups the Episodes of a TV Show completion. Creating a Season
+// with an EpisodeCount adds that many numbered Episodes to it.
+type Season struct {
+	ID           uuid.UUID `json:"id" db:"id"`
+	CompletionID uuid.UUID `json:"completion_id" db:"completion_id"`
+	Number       int       `json:"number" db:"number"`
+	Title        string    `json:"title" db:"title"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+
+	Episodes Episodes `json:"episodes,omitempty" db:"-" has_many:"episodes" order_by:"number asc"`
+
+	// EpisodeCount is only read when the Season is created.
+	EpisodeCount int `json:"episode_count,omitempty" db:"-" form:"EpisodeCount"`
+}
+
+// String is not required by pop and may be deleted
+func (s Season) String() string {
+	js, _ := json.Marshal(s)
+	return string(js)
+}
+
+// WatchedCount is how many of the Season's loaded Episodes are watched.
+func (s Season) WatchedCount() int {
+	watched := 0
+	for _, episode := range s.Episodes {
+		if episode.IsWatched() {
+			watched++
+		}
+	}
+	return watched
+}
+
+// Seasons is not required by pop and may be deleted
+type Seasons []Season
+
+// String is not required by pop and may be deleted
+func (s Seasons) String() string {
+	js, _ := json.Marshal(s)
+	return string(js)
+}
+
+// Validate gets run every time you call a "pop.Validate*" (pop.ValidateAndSave, pop.ValidateAndCreate, pop.ValidateAndUpdate) method.
+// It also checks that the number isn't used by another Season of the show.
+func (s *Season) Validate(tx *pop.Connection) (*validate.Errors, error) {
+	verrs := validate.Validate(
+		&validators.UUIDIsPresent{Field: s.CompletionID, Name: "CompletionID"},
+		&validators.IntIsGreaterThan{Field: s.Number, Name: "Number", Compared: 0},
+		&validators.IntIsGreaterThan{Field: s.EpisodeCount, Name: "EpisodeCount", Compared: -1},
+	)
+
+	taken, err := tx.Where("completion_id = ? AND number = ? AND id <> ?", s.CompletionID, s.Number, s.ID).Exists(&Season{})
+	if err != nil {
+		return verrs, err
+	}
+	if taken {
+		verrs.Add("number", fmt.Sprintf("Season %d already exists.", s.Number))
+	}
+	return verrs, nil
+}
+
+// AfterCreate adds the Season's first EpisodeCount Episodes.
+func (s *Season) AfterCreate(tx *pop.Connection) error {
+	for number := 1; number <= s.EpisodeCount; number++ {
+		episode := &Episode{SeasonID: s.ID, Number: number}
+		if err := tx.Create(episode); err != nil {
+			return err
+		}
+		s.Episodes = append(s.Episodes, *episode)
+	}
+	return nil
+}
+
+// BeforeDestroy takes the Season's watched Episodes off the show's count,
+// since the database removes them without running their callbacks.
+func (s *Season) BeforeDestroy(tx *pop.Connection) error {
+	watched, err := tx.Where("season_id = ? AND watched_at IS NOT NULL", s.ID).Count(&Episode{})
+	if err != nil {
+		return err
+	}
+	note := fmt.Sprintf("Season %d removed", s.Number)
+	return logWatch(tx, s.CompletionID, -watched, time.Now(), note)
+}
+
+// AfterDestroy recounts the show's episodes.
+func (s *Season) AfterDestroy(tx *pop.Connection) error {
+	return refreshEpisodeTotal(tx, s.CompletionID)
+}
@@ -0,0 +1,146 @@
+package models
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"time"
+
+	"github.com/gobuffalo/nulls"
+	"github.com/gobuffalo/pop/v6"
+	"github.com/gobuffalo/validate/v3"
+	"github.com/gobuffalo/validate/v3/validators"
+	"github.com/gofrs/uuid"
+)
+
+// Series groups related completions, such as the books of a series, the
+// games of a franchise or the shows of a universe, in reading or playing
+// order. A Completion joins a Series through its SeriesID and is ordered
+// by its SeriesPosition.
+//
+// Every Series belongs to the User who created it, and only they see it
+// or can put their completions in it.
+type Series struct {
+	ID          uuid.UUID `json:"id" db:"id"`
+	Name        string    `json:"name" db:"name"`
+	Description string    `json:"description" db:"description"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+
+	// UserID is the User the Series belongs to. Like a Completion's, it
+	// is only empty for series made before there were accounts.
+	UserID nulls.UUID `json:"user_id" db:"user_id"`
+
+	Completions Completions `json:"completions,omitempty" db:"-" has_many:"completions" fk_id:"series_id" order_by:"series_position asc, name asc"`
+}
+
+// seriesAlias has Series' fields without its methods, so it can be
+// marshaled without recursing into MarshalJSON or MarshalXML.
+type seriesAlias Series
+
+// seriesView is the JSON and XML representation of a Series, including
+// the progress through it derived from its loaded Completions.
+type seriesView struct {
+	seriesAlias
+	Finished        int         `json:"finished"`
+	Total           int         `json:"total"`
+	PercentComplete int         `json:"percent_complete"`
+	Next            *Completion `json:"next"`
+}
+
+func (s Series) view() seriesView {
+	return seriesView{
+		seriesAlias:     seriesAlias(s),
+		Finished:        s.Finished(),
+		Total:           len(s.Completions),
+		PercentComplete: s.PercentComplete(),
+		Next:            s.Next(),
+	}
+}
+
+// MarshalJSON includes the progress through the Series in the JSON output.
+func (s Series) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.view())
+}
+
+// MarshalXML includes the progress through the Series in the XML output.
+func (s Series) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return e.EncodeElement(s.view(), start)
+}
+
+// String is not required by pop and may be deleted
+func (s Series) String() string {
+	js, _ := json.Marshal(s)
+	return string(js)
+}
+
+// SeriesOwnedBy scopes a query to the series that belong to user.
+func SeriesOwnedBy(user *User) pop.ScopeFunc {
+	return func(q *pop.Query) *pop.Query {
+		return q.Where("series.user_id = ?", user.ID)
+	}
+}
+
+// LoadCompletions loads user's Completions in the Series in series order,
+// leaving out any in the trash.
+func (s *Series) LoadCompletions(tx *pop.Connection, user *User) error {
+	s.Completions = Completions{}
+	return tx.Scope(OwnedBy(user)).Scope(NotTrashed).Where("series_id = ?", s.ID).Order("series_position asc, name asc").All(&s.Completions)
+}
+
+// Finished is how many of the Series' loaded Completions are completed.
+func (s Series) Finished() int {
+	finished := 0
+	for _, completion := range s.Completions {
+		if completion.Status == StatusCompleted {
+			finished++
+		}
+	}
+	return finished
+}
+
+// PercentComplete returns the share of the Series that is completed as a
+// whole percentage. It is 0 for an empty Series.
+func (s Series) PercentComplete() int {
+	if len(s.Completions) == 0 {
+		return 0
+	}
+	return s.Finished() * 100 / len(s.Completions)
+}
+
+// Next returns the first Completion in series order that is neither
+// completed nor abandoned, or nil when the Series is done.
+func (s Series) Next() *Completion {
+	for i := range s.Completions {
+		if !s.Completions[i].Status.IsFinished() {
+			return &s.Completions[i]
+		}
+	}
+	return nil
+}
+
+// SeriesList is not required by pop and may be deleted
+type SeriesList []Series
+
+// String is not required by pop and may be deleted
+func (s SeriesList) String() string {
+	js, _ := json.Marshal(s)
+	return string(js)
+}
+
+// Validate gets run every time you call a "pop.Validate*" (pop.ValidateAndSave, pop.ValidateAndCreate, pop.ValidateAndUpdate) method.
+// This method is not required and may be deleted.
+func (s *Series) Validate(tx *pop.Connection) (*validate.Errors, error) {
+	return validate.Validate(
+		&validators.StringIsPresent{Field: s.Name, Name: "Name"},
+	), nil
+}
+
+// SelectValue lets a Series be offered in a select tag.
+func (s Series) SelectValue() interface{} {
+	return s.ID
+}
+
+// SelectLabel lets a Series be offered in a select tag.
+func (s Series) SelectLabel() string {
+	return s.Name
+}
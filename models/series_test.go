@@ -0,0 +1,69 @@
+package models
+
+import (
+	"time"
+
+	"github.com/gobuffalo/nulls"
+)
+
+func (ms *ModelSuite) Test_Series_Progress() {
+	series := &Series{Name: "Dune Chronicles"}
+	verrs, err := ms.DB.ValidateAndCreate(series)
+	ms.NoError(err)
+	ms.False(verrs.HasAny())
+
+	books := []struct {
+		name     string
+		position int
+		status   Status
+	}{
+		{"Dune", 1, StatusCompleted},
+		{"Dune Messiah", 2, StatusCompleted},
+		{"Children of Dune", 3, StatusPlanned},
+		{"God Emperor of Dune", 4, StatusPlanned},
+	}
+	for _, b := range books {
+		c := &Completion{
+			Name:           b.name,
+			Type:           CompletionTypeBook,
+			Status:         b.status,
+			CompletedAt:    time.Now(),
+			SeriesID:       nulls.NewUUID(series.ID),
+			SeriesPosition: b.position,
+		}
+		verrs, err := ms.DB.ValidateAndCreate(c)
+		ms.NoError(err)
+		ms.False(verrs.HasAny())
+	}
+
+	ms.NoError(ms.DB.Eager("Completions").Find(series, series.ID))
+	ms.Len(series.Completions, 4)
+	ms.Equal(2, series.Finished())
+	ms.Equal(50, series.PercentComplete())
+	ms.Equal("Children of Dune", series.Next().Name)
+}
+
+func (ms *ModelSuite) Test_Series_Validation() {
+	series := &Series{}
+	verrs, err := ms.DB.ValidateAndCreate(series)
+	ms.NoError(err)
+	ms.NotEmpty(verrs.Get("name"))
+
+	ms.Equal(0, series.PercentComplete())
+	ms.Nil(series.Next())
+}
+
+func (ms *ModelSuite) Test_Completion_SeriesPosition() {
+	series := &Series{Name: "Halo"}
+	ms.NoError(ms.DB.Create(series))
+
+	c := &Completion{
+		Name:        "Halo 3",
+		Type:        CompletionTypeVideoGame,
+		CompletedAt: time.Now(),
+		SeriesID:    nulls.NewUUID(series.ID),
+	}
+	verrs, err := ms.DB.ValidateAndCreate(c)
+	ms.NoError(err)
+	ms.NotEmpty(verrs.Get("series_position"))
+}
@@ -0,0 +1,59 @@
+package models
+
+// Status is where a Completion is in its lifecycle
+type Status string
+
+const (
+	StatusPlanned    Status = "planned"
+	StatusInProgress Status = "in-progress"
+	StatusPaused     Status = "paused"
+	StatusCompleted  Status = "completed"
+	StatusAbandoned  Status = "abandoned"
+)
+
+// GetStatuses returns all available statuses
+func GetStatuses() []Status {
+	return []Status{
+		StatusPlanned,
+		StatusInProgress,
+		StatusPaused,
+		StatusCompleted,
+		StatusAbandoned,
+	}
+}
+
+// statusTransitions lists the statuses each status may move to.
+// Completed is final for the current Run, and only Completion.StartRun
+// moves on from it; anything abandoned can be picked up again.
+var statusTransitions = map[Status][]Status{
+	StatusPlanned:    {StatusInProgress, StatusCompleted, StatusAbandoned},
+	StatusInProgress: {StatusPaused, StatusCompleted, StatusAbandoned},
+	StatusPaused:     {StatusInProgress, StatusAbandoned},
+	StatusCompleted:  {},
+	StatusAbandoned:  {StatusPlanned, StatusInProgress},
+}
+
+// IsValid reports whether s is one of the known statuses.
+func (s Status) IsValid() bool {
+	_, ok := statusTransitions[s]
+	return ok
+}
+
+// IsFinished reports whether s ends a Run.
+func (s Status) IsFinished() bool {
+	return s == StatusCompleted || s == StatusAbandoned
+}
+
+// CanTransitionTo reports whether a Completion may move from s to next.
+// Staying in the same status is always allowed.
+func (s Status) CanTransitionTo(next Status) bool {
+	if s == next {
+		return true
+	}
+	for _, allowed := range statusTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
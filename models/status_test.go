@@ -0,0 +1,22 @@
+package models
+
+func (ms *ModelSuite) Test_Status_CanTransitionTo() {
+	ms.True(StatusPlanned.CanTransitionTo(StatusInProgress))
+	ms.True(StatusInProgress.CanTransitionTo(StatusPaused))
+	ms.True(StatusPaused.CanTransitionTo(StatusInProgress))
+	ms.True(StatusAbandoned.CanTransitionTo(StatusPlanned))
+	ms.True(StatusCompleted.CanTransitionTo(StatusCompleted))
+
+	ms.False(StatusPlanned.CanTransitionTo(StatusPaused))
+	ms.False(StatusPaused.CanTransitionTo(StatusCompleted))
+	ms.False(StatusCompleted.CanTransitionTo(StatusInProgress))
+
+	ms.False(Status("finished").IsValid())
+}
+
+func (ms *ModelSuite) Test_Status_IsFinished() {
+	ms.True(StatusCompleted.IsFinished())
+	ms.True(StatusAbandoned.IsFinished())
+	ms.False(StatusPaused.IsFinished())
+	ms.False(StatusPlanned.IsFinished())
+}
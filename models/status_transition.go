@@ -0,0 +1,47 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/gobuffalo/pop/v6"
+	"github.com/gobuffalo/validate/v3"
+	"github.com/gobuffalo/validate/v3/validators"
+	"github.com/gofrs/uuid"
+)
+
+// StatusTransition records a Completion moving from one Status to another.
+// FromStatus is empty for the status a Completion was created with, and
+// CreatedAt is when the transition happened.
+type StatusTransition struct {
+	ID           uuid.UUID `json:"id" db:"id"`
+	CompletionID uuid.UUID `json:"completion_id" db:"completion_id"`
+	FromStatus   Status    `json:"from_status" db:"from_status"`
+	ToStatus     Status    `json:"to_status" db:"to_status"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// String is not required by pop and may be deleted
+func (s StatusTransition) String() string {
+	js, _ := json.Marshal(s)
+	return string(js)
+}
+
+// StatusTransitions is not required by pop and may be deleted
+type StatusTransitions []StatusTransition
+
+// String is not required by pop and may be deleted
+func (s StatusTransitions) String() string {
+	js, _ := json.Marshal(s)
+	return string(js)
+}
+
+// Validate gets run every time you call a "pop.Validate*" (pop.ValidateAndSave, pop.ValidateAndCreate, pop.ValidateAndUpdate) method.
+// This method is not required and may be deleted.
+func (s *StatusTransition) Validate(tx *pop.Connection) (*validate.Errors, error) {
+	return validate.Validate(
+		&validators.UUIDIsPresent{Field: s.CompletionID, Name: "CompletionID"},
+		&validators.StringIsPresent{Field: string(s.ToStatus), Name: "ToStatus"},
+	), nil
+}
@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+func (ms *ModelSuite) Test_StatusTransition() {
+	c := &Completion{
+		Name:        "Dune",
+		Type:        CompletionTypeBook,
+		Completions: 10,
+		CompletedAt: time.Now(),
+	}
+	verrs, err := ms.DB.ValidateAndCreate(c)
+	ms.NoError(err)
+	ms.False(verrs.HasAny())
+	ms.Equal(StatusPlanned, c.Status)
+
+	c.Status = StatusInProgress
+	verrs, err = ms.DB.ValidateAndUpdate(c)
+	ms.NoError(err)
+	ms.False(verrs.HasAny())
+
+	c.Status = StatusPaused
+	verrs, err = ms.DB.ValidateAndUpdate(c)
+	ms.NoError(err)
+	ms.False(verrs.HasAny())
+
+	c.Status = StatusCompleted
+	verrs, err = ms.DB.ValidateAndUpdate(c)
+	ms.NoError(err)
+	ms.True(verrs.HasAny())
+	ms.NotEmpty(verrs.Get("status"))
+
+	transitions := StatusTransitions{}
+	ms.NoError(ms.DB.Where("completion_id = ?", c.ID).Order("created_at asc").All(&transitions))
+	ms.Len(transitions, 3)
+	ms.Equal(Status(""), transitions[0].FromStatus)
+	ms.Equal(StatusPlanned, transitions[0].ToStatus)
+	ms.Equal(StatusInProgress, transitions[2].FromStatus)
+	ms.Equal(StatusPaused, transitions[2].ToStatus)
+}
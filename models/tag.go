@@ -0,0 +1,151 @@
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/gobuffalo/pop/v6"
+	"github.com/gobuffalo/validate/v3"
+	"github.com/gobuffalo/validate/v3/validators"
+	"github.com/gofrs/uuid"
+)
+
+// Tag is a free-form label, such as "co-op", "sci-fi" or "book club",
+// that completions of any type can share. Tags are matched by Slug, so
+// names that differ only in case or punctuation are the same Tag.
+type Tag struct {
+	ID        uuid.UUID `json:"id" db:"id"`
+	Name      string    `json:"name" db:"name"`
+	Slug      string    `json:"slug" db:"slug"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+
+	Completions Completions `json:"completions,omitempty" db:"-" many_to_many:"completion_tags"`
+}
+
+// String is not required by pop and may be deleted
+func (t Tag) String() string {
+	jt, _ := json.Marshal(t)
+	return string(jt)
+}
+
+// Tags is not required by pop and may be deleted
+type Tags []Tag
+
+// String is not required by pop and may be deleted
+func (t Tags) String() string {
+	jt, _ := json.Marshal(t)
+	return string(jt)
+}
+
+// Validate gets run every time you call a "pop.Validate*" (pop.ValidateAndSave, pop.ValidateAndCreate, pop.ValidateAndUpdate) method.
+// This method is not required and may be deleted.
+func (t *Tag) Validate(tx *pop.Connection) (*validate.Errors, error) {
+	return validate.Validate(
+		&validators.StringIsPresent{Field: t.Name, Name: "Name"},
+		&validators.StringIsPresent{Field: t.Slug, Name: "Slug"},
+	), nil
+}
+
+// BeforeValidate derives the Slug from the Name.
+func (t *Tag) BeforeValidate(tx *pop.Connection) error {
+	t.Slug = Slugify(t.Name)
+	return nil
+}
+
+// CompletionTag joins a Completion to one of its Tags.
+type CompletionTag struct {
+	ID           uuid.UUID `json:"id" db:"id"`
+	CompletionID uuid.UUID `json:"completion_id" db:"completion_id"`
+	TagID        uuid.UUID `json:"tag_id" db:"tag_id"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// TagCount is a Tag with the number of completions that carry it.
+type TagCount struct {
+	ID    uuid.UUID `json:"id" db:"id"`
+	Name  string    `json:"name" db:"name"`
+	Slug  string    `json:"slug" db:"slug"`
+	Count int       `json:"count" db:"count"`
+}
+
+// TagCounts is not required by pop and may be deleted
+type TagCounts []TagCount
+
+// CountTags loads the Tags on the completions user can see, their own and
+// those shared with their groups, with how many of them carry each, most
+// used first. Completions in the trash are not counted, and Tags only
+// other users' completions carry are left out.
+func CountTags(tx *pop.Connection, user *User) (TagCounts, error) {
+	counts := TagCounts{}
+	err := tx.RawQuery(`SELECT tags.id, tags.name, tags.slug, COUNT(completions.id) AS count
+		FROM tags JOIN completion_tags ON completion_tags.tag_id = tags.id
+		JOIN completions ON completions.id = completion_tags.completion_id AND completions.deleted_at IS NULL AND `+visibleToSQL+`
+		GROUP BY tags.id ORDER BY count DESC, tags.name ASC`, user.ID, user.ID).All(&counts)
+	return counts, err
+}
+
+// Slugify turns a tag name into its slug: lower case letters and digits,
+// with each run of anything else replaced by a single dash. "Book Club!"
+// becomes "book-club".
+func Slugify(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			dash = true
+			continue
+		}
+		if dash && b.Len() > 0 {
+			b.WriteByte('-')
+		}
+		dash = false
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// ParseTagList splits a comma separated list of tag names, trimming each
+// and dropping blanks and names that repeat an earlier one's slug.
+func ParseTagList(list string) []string {
+	var names []string
+	seen := map[string]bool{}
+	for _, name := range strings.Split(list, ",") {
+		name = strings.Join(strings.Fields(name), " ")
+		slug := Slugify(name)
+		if slug == "" || seen[slug] {
+			continue
+		}
+		seen[slug] = true
+		names = append(names, name)
+	}
+	return names
+}
+
+// findOrCreateTag loads the Tag with name's slug, creating it with name if
+// there isn't one yet.
+func findOrCreateTag(tx *pop.Connection, name string) (*Tag, error) {
+	tag := &Tag{}
+	err := tx.Where("slug = ?", Slugify(name)).First(tag)
+	if err == nil {
+		return tag, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	tag.Name = name
+	verrs, err := tx.ValidateAndCreate(tag)
+	if err != nil {
+		return nil, err
+	}
+	if verrs.HasAny() {
+		return nil, verrs
+	}
+	return tag, nil
+}
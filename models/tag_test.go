@@ -0,0 +1,86 @@
+package models
+
+import (
+	"time"
+
+	"github.com/gobuffalo/nulls"
+)
+
+func (ms *ModelSuite) Test_Slugify() {
+	ms.Equal("book-club", Slugify("Book Club!"))
+	ms.Equal("co-op", Slugify("  Co-op "))
+	ms.Equal("sci-fi", Slugify("Sci--Fi"))
+	ms.Equal("", Slugify("!!"))
+}
+
+func (ms *ModelSuite) Test_ParseTagList() {
+	ms.Equal([]string{"co-op", "book club", "Sci-Fi"}, ParseTagList("co-op,  book   club , , Sci-Fi, CO-OP"))
+	ms.Empty(ParseTagList(" , "))
+}
+
+func (ms *ModelSuite) Test_Completion_Tags() {
+	u := ms.createUser("reader@example.com")
+
+	list := "sci-fi, Book Club"
+	c := &Completion{
+		Name:        "Dune",
+		Type:        CompletionTypeBook,
+		CompletedAt: time.Now(),
+		TagList:     &list,
+		UserID:      nulls.NewUUID(u.ID),
+	}
+	verrs, err := ms.DB.ValidateAndCreate(c)
+	ms.NoError(err)
+	ms.False(verrs.HasAny())
+	ms.Len(c.Tags, 2)
+
+	other := &Completion{Name: "Halo", Type: CompletionTypeVideoGame, CompletedAt: time.Now(), UserID: nulls.NewUUID(u.ID)}
+	ms.NoError(ms.DB.Create(other))
+	ms.NoError(other.SetTags(ms.DB, []string{"Sci-Fi", "co-op"}))
+
+	// Someone else's completions aren't counted
+	stranger := ms.createUser("stranger@example.com")
+	theirs := &Completion{Name: "Solaris", Type: CompletionTypeBook, CompletedAt: time.Now(), UserID: nulls.NewUUID(stranger.ID)}
+	ms.NoError(ms.DB.Create(theirs))
+	ms.NoError(theirs.SetTags(ms.DB, []string{"sci-fi", "Russian"}))
+
+	// Tags are shared by slug, whatever the case they were entered in
+	count, err := ms.DB.Count(&Tag{})
+	ms.NoError(err)
+	ms.Equal(4, count)
+
+	loaded := &Completion{}
+	ms.NoError(ms.DB.Eager("Tags").Find(loaded, c.ID))
+	ms.Equal("Book Club, sci-fi", loaded.TagNames())
+
+	counts, err := CountTags(ms.DB, u)
+	ms.NoError(err)
+	ms.Len(counts, 3)
+	ms.Equal("sci-fi", counts[0].Slug)
+	ms.Equal(2, counts[0].Count)
+	for _, tc := range counts {
+		ms.NotEqual("russian", tc.Slug)
+	}
+
+	// Those shared with the user's groups are
+	friend := ms.createUser("friend@example.com")
+	shared := ms.shareWith(ms.createGroup(friend, map[*User]Role{u: RoleViewer}), friend, "Severance", StatusInProgress)
+	ms.NoError(shared.SetTags(ms.DB, []string{"mystery"}))
+
+	counts, err = CountTags(ms.DB, u)
+	ms.NoError(err)
+	ms.Len(counts, 4)
+	ms.Equal("mystery", counts[2].Slug)
+	ms.Equal(1, counts[2].Count)
+
+	// Saving without a TagList leaves the tags alone; an empty one clears them
+	loaded.Name = "Dune (1965)"
+	ms.NoError(ms.DB.Update(loaded))
+	ms.NoError(ms.DB.Load(loaded, "Tags"))
+	ms.Len(loaded.Tags, 2)
+
+	empty := ""
+	loaded.TagList = &empty
+	ms.NoError(ms.DB.Update(loaded))
+	ms.Empty(loaded.Tags)
+}
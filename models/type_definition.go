@@ -0,0 +1,228 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gobuffalo/pop/v6"
+	"github.com/gobuffalo/validate/v3"
+	"github.com/gobuffalo/validate/v3/validators"
+	"github.com/gofrs/uuid"
+)
+
+// TypeDefinition is a row of the completion_types table: a kind of thing
+// that can be completed, with the unit its progress is counted in and an
+// icon to show beside it. A Completion's Type is the Name of one.
+//
+// The built-in types are the ones with pages of their own, such as TV
+// shows and books. They are seeded by the migrations and can't be renamed
+// or removed. Any other type, such as movies or podcasts, can be added at
+// runtime and is tracked through the general completions pages.
+type TypeDefinition struct {
+	ID           uuid.UUID      `json:"id" db:"id"`
+	Name         CompletionType `json:"name" db:"name"`
+	Slug         string         `json:"slug" db:"slug"`
+	UnitSingular string         `json:"unit_singular" db:"unit_singular"`
+	UnitPlural   string         `json:"unit_plural" db:"unit_plural"`
+	Icon         string         `json:"icon" db:"icon"`
+	BuiltIn      bool           `json:"built_in" db:"built_in"`
+	CreatedAt    time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at" db:"updated_at"`
+}
+
+// TableName overrides the table name used by Pop.
+func (d TypeDefinition) TableName() string {
+	return "completion_types"
+}
+
+// String is not required by pop and may be deleted
+func (d TypeDefinition) String() string {
+	jd, _ := json.Marshal(d)
+	return string(jd)
+}
+
+// SelectValue lets a TypeDefinition be offered in a select tag.
+func (d TypeDefinition) SelectValue() interface{} {
+	return string(d.Name)
+}
+
+// SelectLabel lets a TypeDefinition be offered in a select tag.
+func (d TypeDefinition) SelectLabel() string {
+	return d.Name.Label()
+}
+
+// TypeDefinitions is not required by pop and may be deleted
+type TypeDefinitions []TypeDefinition
+
+// TableName overrides the table name used by Pop.
+func (d TypeDefinitions) TableName() string {
+	return "completion_types"
+}
+
+// String is not required by pop and may be deleted
+func (d TypeDefinitions) String() string {
+	jd, _ := json.Marshal(d)
+	return string(jd)
+}
+
+// BuiltInTypeDefinitions returns the completion types that the
+// type-specific pages are built around.
+func BuiltInTypeDefinitions() TypeDefinitions {
+	return TypeDefinitions{
+		{Name: CompletionTypeTVShow, Slug: "tv-show", UnitSingular: "episode", UnitPlural: "episodes", Icon: "📺", BuiltIn: true},
+		{Name: CompletionTypeVideoGame, Slug: "video-game", UnitSingular: "hour", UnitPlural: "hours", Icon: "🎮", BuiltIn: true},
+		{Name: CompletionTypeBook, Slug: "book", UnitSingular: "page", UnitPlural: "pages", Icon: "📚", BuiltIn: true},
+		{Name: CompletionTypeAudioBook, Slug: "audio-book", UnitSingular: "minute", UnitPlural: "minutes", Icon: "🎧", BuiltIn: true},
+		{Name: CompletionTypeEvent, Slug: "event", UnitSingular: "event", UnitPlural: "events", Icon: "📅", BuiltIn: true},
+	}
+}
+
+// SeedCompletionTypes adds any built-in completion type that is missing
+// from the database, and then loads them all.
+func SeedCompletionTypes(tx *pop.Connection) error {
+	for _, d := range BuiltInTypeDefinitions() {
+		exists, err := tx.Where("name = ?", d.Name).Exists(&TypeDefinition{})
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+		if err := tx.Create(&d); err != nil {
+			return err
+		}
+	}
+	return LoadCompletionTypes(tx)
+}
+
+var (
+	typeDefinitionsMu sync.RWMutex
+	typeDefinitions   = map[CompletionType]TypeDefinition{}
+
+	// typeDefinitionsLoaded is when typeDefinitions were last loaded, and
+	// is zero until they have been.
+	typeDefinitionsLoaded time.Time
+)
+
+// CompletionTypesMaxAge is how long the loaded completion types are kept
+// before EnsureCompletionTypes loads them again, which picks up changes
+// made by other instances of the app.
+const CompletionTypesMaxAge = time.Minute
+
+// EnsureCompletionTypes loads the completion types unless they were loaded
+// within CompletionTypesMaxAge.
+func EnsureCompletionTypes(tx *pop.Connection) error {
+	typeDefinitionsMu.RLock()
+	loaded := typeDefinitionsLoaded
+	typeDefinitionsMu.RUnlock()
+
+	if !loaded.IsZero() && time.Since(loaded) < CompletionTypesMaxAge {
+		return nil
+	}
+	return LoadCompletionTypes(tx)
+}
+
+// LoadCompletionTypes refreshes the completion types known to
+// GetCompletionTypes and CompletionType's methods from the database.
+func LoadCompletionTypes(tx *pop.Connection) error {
+	defs := TypeDefinitions{}
+	if err := tx.All(&defs); err != nil {
+		return err
+	}
+
+	loaded := make(map[CompletionType]TypeDefinition, len(defs))
+	for _, d := range defs {
+		loaded[d.Name] = d
+	}
+
+	typeDefinitionsMu.Lock()
+	typeDefinitions = loaded
+	typeDefinitionsLoaded = time.Now()
+	typeDefinitionsMu.Unlock()
+	return nil
+}
+
+// lookupTypeDefinition finds the loaded TypeDefinition of t.
+func lookupTypeDefinition(t CompletionType) (TypeDefinition, bool) {
+	typeDefinitionsMu.RLock()
+	defer typeDefinitionsMu.RUnlock()
+	d, ok := typeDefinitions[t]
+	return d, ok
+}
+
+// Validate gets run every time you call a "pop.Validate*" (pop.ValidateAndSave, pop.ValidateAndCreate, pop.ValidateAndUpdate) method.
+// Names and slugs must be unique.
+func (d *TypeDefinition) Validate(tx *pop.Connection) (*validate.Errors, error) {
+	verrs := validate.Validate(
+		&validators.StringIsPresent{Field: string(d.Name), Name: "Name"},
+		&validators.StringIsPresent{Field: d.Slug, Name: "Slug"},
+		&validators.StringIsPresent{Field: d.UnitSingular, Name: "UnitSingular"},
+		&validators.StringIsPresent{Field: d.UnitPlural, Name: "UnitPlural"},
+	)
+
+	taken := []struct {
+		column, field string
+		value         interface{}
+	}{
+		{"name", "name", d.Name},
+		{"slug", "slug", d.Slug},
+	}
+	for _, t := range taken {
+		exists, err := tx.Where(t.column+" = ? AND id <> ?", t.value, d.ID).Exists(&TypeDefinition{})
+		if err != nil {
+			return verrs, err
+		}
+		if exists {
+			verrs.Add(t.field, fmt.Sprintf("There is already a completion type with the %s %q.", t.column, t.value))
+		}
+	}
+	return verrs, nil
+}
+
+// ValidateUpdate gets run every time you call "pop.ValidateAndUpdate" method.
+// It keeps a built-in type's name and slug, which the type-specific pages
+// depend on.
+func (d *TypeDefinition) ValidateUpdate(tx *pop.Connection) (*validate.Errors, error) {
+	verrs := validate.NewErrors()
+
+	persisted := &TypeDefinition{}
+	if err := tx.Find(persisted, d.ID); err != nil {
+		return verrs, err
+	}
+	if persisted.BuiltIn && (persisted.Name != d.Name || persisted.Slug != d.Slug) {
+		verrs.Add("name", fmt.Sprintf("%s is a built-in type and can't be renamed.", persisted.Name))
+	}
+	d.BuiltIn = persisted.BuiltIn
+	return verrs, nil
+}
+
+// ValidateDestroy checks that the type can be removed: it must not be
+// built in, nor still be the type of any Completion.
+func (d *TypeDefinition) ValidateDestroy(tx *pop.Connection) (*validate.Errors, error) {
+	verrs := validate.NewErrors()
+	if d.BuiltIn {
+		verrs.Add("name", fmt.Sprintf("%s is a built-in type and can't be removed.", d.Name))
+		return verrs, nil
+	}
+
+	count, err := tx.Where("type = ?", d.Name).Count(&Completion{})
+	if err != nil {
+		return verrs, err
+	}
+	if count > 0 {
+		verrs.Add("name", fmt.Sprintf("%s is the type of %d completions and can't be removed.", d.Name, count))
+	}
+	return verrs, nil
+}
+
+// BeforeValidate derives a missing Slug from the Name.
+func (d *TypeDefinition) BeforeValidate(tx *pop.Connection) error {
+	if d.Slug == "" {
+		d.Slug = Slugify(string(d.Name))
+	} else {
+		d.Slug = Slugify(d.Slug)
+	}
+	return nil
+}
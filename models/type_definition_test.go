@@ -0,0 +1,83 @@
+package models
+
+import (
+	"time"
+)
+
+func (ms *ModelSuite) Test_TypeDefinition() {
+	movie := &TypeDefinition{Name: "Movie", UnitSingular: "minute", UnitPlural: "minutes", Icon: "🎬"}
+	verrs, err := ms.DB.ValidateAndCreate(movie)
+	ms.NoError(err)
+	ms.False(verrs.HasAny())
+	ms.Equal("movie", movie.Slug)
+
+	// Names and slugs are unique
+	verrs, err = ms.DB.ValidateAndCreate(&TypeDefinition{Name: "Movie", Slug: "film", UnitSingular: "minute", UnitPlural: "minutes"})
+	ms.NoError(err)
+	ms.True(verrs.HasAny())
+
+	ms.NoError(LoadCompletionTypes(ms.DB))
+	ms.Len(GetCompletionTypes(), 6)
+	ms.Equal(CompletionType("Movie"), GetCompletionTypes()[5].Name)
+
+	c := &Completion{Name: "Arrival", Type: "Movie", Completions: 90, CompletedAt: time.Now()}
+	verrs, err = ms.DB.ValidateAndCreate(c)
+	ms.NoError(err)
+	ms.False(verrs.HasAny())
+	ms.Equal("90 minutes", c.FormatUnits(c.Completions))
+	ms.Equal("🎬 Movie", c.Type.Label())
+
+	// A type that is in use can't be removed
+	verrs, err = movie.ValidateDestroy(ms.DB)
+	ms.NoError(err)
+	ms.True(verrs.HasAny())
+
+	// Renaming a type renames it on its completions
+	movie.Name = "Film"
+	verrs, err = ms.DB.ValidateAndUpdate(movie)
+	ms.NoError(err)
+	ms.False(verrs.HasAny())
+	ms.NoError(ms.DB.Reload(c))
+	ms.Equal(CompletionType("Film"), c.Type)
+}
+
+func (ms *ModelSuite) Test_EnsureCompletionTypes() {
+	ms.NoError(LoadCompletionTypes(ms.DB))
+	ms.Len(GetCompletionTypes(), 5)
+
+	// The loaded types are kept rather than reloaded for every use
+	ms.NoError(ms.DB.Create(&TypeDefinition{Name: "Opera", UnitSingular: "act", UnitPlural: "acts"}))
+	ms.NoError(EnsureCompletionTypes(ms.DB))
+	ms.Len(GetCompletionTypes(), 5)
+
+	// until they are loaded again
+	ms.NoError(LoadCompletionTypes(ms.DB))
+	ms.Len(GetCompletionTypes(), 6)
+}
+
+func (ms *ModelSuite) Test_TypeDefinition_BuiltIn() {
+	book := &TypeDefinition{}
+	ms.NoError(ms.DB.Where("name = ?", CompletionTypeBook).First(book))
+	ms.True(book.BuiltIn)
+
+	book.Icon = "📖"
+	verrs, err := ms.DB.ValidateAndUpdate(book)
+	ms.NoError(err)
+	ms.False(verrs.HasAny())
+
+	book.Name = "Novel"
+	verrs, err = ms.DB.ValidateAndUpdate(book)
+	ms.NoError(err)
+	ms.True(verrs.HasAny())
+
+	verrs, err = book.ValidateDestroy(ms.DB)
+	ms.NoError(err)
+	ms.True(verrs.HasAny())
+}
+
+func (ms *ModelSuite) Test_Completion_UnknownType() {
+	c := &Completion{Name: "Serial", Type: "Podcast", CompletedAt: time.Now()}
+	verrs, err := c.Validate(ms.DB)
+	ms.NoError(err)
+	ms.NotEmpty(verrs.Get("type"))
+}
@@ -0,0 +1,258 @@
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gobuffalo/nulls"
+	"github.com/gobuffalo/pop/v6"
+	"github.com/gobuffalo/validate/v3"
+	"github.com/gobuffalo/validate/v3/validators"
+	"github.com/gofrs/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// MinPasswordLength is the shortest password a User can choose.
+const MinPasswordLength = 8
+
+// ErrInvalidCredentials is returned by AuthenticateUser when the email or
+// password is wrong. It doesn't say which, so as not to reveal who has an
+// account.
+var ErrInvalidCredentials = errors.New("invalid email or password")
+
+// ErrEmailTaken is returned by SignInWithOIDC when an identity provider
+// signs in someone new whose email already has an account. The two are
+// only linked by the account's owner, once they have signed in.
+var ErrEmailTaken = errors.New("there is already an account with that email")
+
+// ErrEmailUnverified is returned by SignInWithOIDC when an identity
+// provider signs in someone new without vouching for their email, so no
+// account can be made for it.
+var ErrEmailUnverified = errors.New("the identity provider hasn't verified that email")
+
+// ErrIdentityTaken is returned by LinkOIDC when the identity is already
+// linked to another account.
+var ErrIdentityTaken = errors.New("that identity is linked to another account")
+
+// User is someone who can sign in. Only a bcrypt hash of the password is
+// stored; Password and PasswordConfirmation are bound from the signup or
+// sign in form and are only hashed, never saved.
+//
+// Username is optional, and names the User's public profile once chosen.
+//
+// An Admin can manage what is shared by everyone, such as the completion
+// types. Only the first User to sign up is one to begin with.
+//
+// A User who signs in through single sign-on is linked to their identity
+// at the provider by OIDCIssuer and OIDCSubject. One created that way has
+// no password until they choose one.
+type User struct {
+	ID           uuid.UUID `json:"id" db:"id"`
+	Email        string    `json:"email" db:"email"`
+	Username     string    `json:"username" db:"username"`
+	PasswordHash string    `json:"-" xml:"-" form:"-" db:"password_hash"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+
+	Admin bool `json:"admin" form:"-" db:"admin"`
+
+	OIDCIssuer  nulls.String `json:"-" xml:"-" form:"-" db:"oidc_issuer"`
+	OIDCSubject nulls.String `json:"-" xml:"-" form:"-" db:"oidc_subject"`
+
+	Password             string `json:"password,omitempty" xml:"-" db:"-"`
+	PasswordConfirmation string `json:"password_confirmation,omitempty" xml:"-" db:"-"`
+}
+
+// String is not required by pop and may be deleted
+func (u User) String() string {
+	ju, _ := json.Marshal(u)
+	return string(ju)
+}
+
+// Users is not required by pop and may be deleted
+type Users []User
+
+// String is not required by pop and may be deleted
+func (u Users) String() string {
+	ju, _ := json.Marshal(u)
+	return string(ju)
+}
+
+// Validate gets run every time you call a "pop.Validate*" (pop.ValidateAndSave, pop.ValidateAndCreate, pop.ValidateAndUpdate) method.
+// Emails and usernames must be unique.
+func (u *User) Validate(tx *pop.Connection) (*validate.Errors, error) {
+	verrs := validate.Validate(
+		&validators.EmailIsPresent{Field: u.Email, Name: "Email"},
+	)
+
+	taken, err := tx.Where("email = ? AND id <> ?", u.Email, u.ID).Exists(&User{})
+	if err != nil {
+		return verrs, err
+	}
+	if taken {
+		verrs.Add("email", "There is already an account with that email.")
+	}
+
+	if u.Username == "" {
+		return verrs, nil
+	}
+	if !usernamePattern.MatchString(u.Username) {
+		verrs.Add("username", "Username must be 3 to 30 lower case letters, digits, dashes or underscores.")
+		return verrs, nil
+	}
+	taken, err = tx.Where("username = ? AND id <> ?", u.Username, u.ID).Exists(&User{})
+	if err != nil {
+		return verrs, err
+	}
+	if taken {
+		verrs.Add("username", "That username is taken.")
+	}
+	return verrs, nil
+}
+
+// usernamePattern is what a username may look like, so that it can be
+// used in a profile's path as it is.
+var usernamePattern = regexp.MustCompile(`^[a-z0-9_-]{3,30}$`)
+
+// ValidateCreate gets run every time you call "pop.ValidateAndCreate" method.
+// It checks the password chosen on signup, which single sign-on users
+// don't need.
+func (u *User) ValidateCreate(tx *pop.Connection) (*validate.Errors, error) {
+	if u.OIDCSubject.Valid && u.Password == "" {
+		return validate.NewErrors(), nil
+	}
+	return validate.Validate(
+		&validators.StringLengthInRange{Field: u.Password, Name: "Password", Min: MinPasswordLength},
+		&validators.StringsMatch{Field: u.Password, Field2: u.PasswordConfirmation, Name: "PasswordConfirmation", Message: "Password confirmation doesn't match the password."},
+	), nil
+}
+
+// BeforeValidate normalizes the Email and Username, which are matched
+// case-insensitively.
+func (u *User) BeforeValidate(tx *pop.Connection) error {
+	u.Email = strings.ToLower(strings.TrimSpace(u.Email))
+	u.Username = strings.ToLower(strings.TrimSpace(u.Username))
+	return nil
+}
+
+// BeforeCreate hashes the Password. A User without one is left with an
+// empty hash, which no password matches. The first User to sign up is
+// made an admin, so that someone can manage the app.
+func (u *User) BeforeCreate(tx *pop.Connection) error {
+	anyone, err := tx.Q().Exists(&User{})
+	if err != nil {
+		return err
+	}
+	u.Admin = !anyone
+
+	if u.Password == "" {
+		return nil
+	}
+	return u.SetPassword(u.Password)
+}
+
+// AfterCreate gives the new User any completions and series that don't
+// belong to anyone, which were recorded before there were accounts. Only
+// the first User to sign up finds any.
+func (u *User) AfterCreate(tx *pop.Connection) error {
+	if err := tx.RawQuery("UPDATE completions SET user_id = ? WHERE user_id IS NULL", u.ID).Exec(); err != nil {
+		return err
+	}
+	return tx.RawQuery("UPDATE series SET user_id = ? WHERE user_id IS NULL", u.ID).Exec()
+}
+
+// SetPassword replaces the PasswordHash with a hash of password.
+func (u *User) SetPassword(password string) error {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	u.PasswordHash = string(hash)
+	return nil
+}
+
+// AuthenticateUser loads the User with email, provided password is theirs.
+func AuthenticateUser(tx *pop.Connection, email, password string) (*User, error) {
+	u := &User{}
+	err := tx.Where("email = ?", strings.ToLower(strings.TrimSpace(email))).First(u)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(password)); err != nil {
+		return nil, ErrInvalidCredentials
+	}
+	return u, nil
+}
+
+// OIDCIdentity is who an OpenID Connect provider says signed in.
+type OIDCIdentity struct {
+	Issuer        string
+	Subject       string
+	Email         string
+	EmailVerified bool
+}
+
+// SignInWithOIDC loads the User linked to identity, or creates one for it
+// the first time it signs in, provided the provider has verified its
+// email. An identity is never linked to an existing account by its email,
+// however the provider vouches for it; the account's owner links it with
+// LinkOIDC once they have signed in.
+func SignInWithOIDC(tx *pop.Connection, identity OIDCIdentity) (*User, error) {
+	u := &User{}
+	err := tx.Where("oidc_issuer = ? AND oidc_subject = ?", identity.Issuer, identity.Subject).First(u)
+	if err == nil {
+		return u, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	email := strings.ToLower(strings.TrimSpace(identity.Email))
+	taken, err := tx.Where("email = ?", email).Exists(&User{})
+	switch {
+	case err != nil:
+		return nil, err
+	case taken:
+		return nil, ErrEmailTaken
+	case !identity.EmailVerified:
+		return nil, ErrEmailUnverified
+	}
+
+	u = &User{
+		Email:       email,
+		OIDCIssuer:  nulls.NewString(identity.Issuer),
+		OIDCSubject: nulls.NewString(identity.Subject),
+	}
+	verrs, err := tx.ValidateAndCreate(u)
+	if err != nil {
+		return nil, err
+	}
+	if verrs.HasAny() {
+		return nil, verrs
+	}
+	return u, nil
+}
+
+// LinkOIDC links identity to u, who has signed in some other way, so that
+// from then on it signs in to their account.
+func LinkOIDC(tx *pop.Connection, u *User, identity OIDCIdentity) error {
+	taken, err := tx.Where("oidc_issuer = ? AND oidc_subject = ? AND id <> ?", identity.Issuer, identity.Subject, u.ID).Exists(&User{})
+	if err != nil {
+		return err
+	}
+	if taken {
+		return ErrIdentityTaken
+	}
+
+	u.OIDCIssuer = nulls.NewString(identity.Issuer)
+	u.OIDCSubject = nulls.NewString(identity.Subject)
+	return tx.UpdateColumns(u, "oidc_issuer", "oidc_subject")
+}
@@ -0,0 +1,129 @@
+package models
+
+import (
+	"time"
+
+	"github.com/gobuffalo/nulls"
+)
+
+func (ms *ModelSuite) Test_User_Create() {
+	u := &User{Email: " Reader@Example.com ", Password: "correct horse", PasswordConfirmation: "correct horse"}
+	verrs, err := ms.DB.ValidateAndCreate(u)
+	ms.NoError(err)
+	ms.False(verrs.HasAny())
+	ms.Equal("reader@example.com", u.Email)
+	ms.NotEmpty(u.PasswordHash)
+	ms.NotEqual("correct horse", u.PasswordHash)
+
+	dup := &User{Email: "reader@example.com", Password: "correct horse", PasswordConfirmation: "correct horse"}
+	verrs, err = ms.DB.ValidateAndCreate(dup)
+	ms.NoError(err)
+	ms.True(verrs.HasAny())
+	ms.NotEmpty(verrs.Get("email"))
+}
+
+func (ms *ModelSuite) Test_User_ValidatePassword() {
+	u := &User{Email: "reader@example.com", Password: "short", PasswordConfirmation: "short"}
+	verrs, err := ms.DB.ValidateAndCreate(u)
+	ms.NoError(err)
+	ms.NotEmpty(verrs.Get("password"))
+
+	u = &User{Email: "reader@example.com", Password: "correct horse", PasswordConfirmation: "battery staple"}
+	verrs, err = ms.DB.ValidateAndCreate(u)
+	ms.NoError(err)
+	ms.NotEmpty(verrs.Get("password_confirmation"))
+}
+
+func (ms *ModelSuite) Test_AuthenticateUser() {
+	u := &User{Email: "reader@example.com", Password: "correct horse", PasswordConfirmation: "correct horse"}
+	verrs, err := ms.DB.ValidateAndCreate(u)
+	ms.NoError(err)
+	ms.False(verrs.HasAny())
+
+	found, err := AuthenticateUser(ms.DB, "READER@example.com", "correct horse")
+	ms.NoError(err)
+	ms.Equal(u.ID, found.ID)
+
+	_, err = AuthenticateUser(ms.DB, "reader@example.com", "wrong")
+	ms.ErrorIs(err, ErrInvalidCredentials)
+
+	_, err = AuthenticateUser(ms.DB, "nobody@example.com", "correct horse")
+	ms.ErrorIs(err, ErrInvalidCredentials)
+}
+
+func (ms *ModelSuite) Test_User_ClaimsUnownedCompletions() {
+	unowned := &Completion{Name: "Dune", Type: CompletionTypeBook, CompletedAt: time.Now()}
+	ms.NoError(ms.DB.Create(unowned))
+
+	u := ms.createUser("reader@example.com")
+	ms.NoError(ms.DB.Reload(unowned))
+	ms.Equal(nulls.NewUUID(u.ID), unowned.UserID)
+
+	// Later users have nothing left to claim
+	ms.createUser("stranger@example.com")
+	ms.NoError(ms.DB.Reload(unowned))
+	ms.Equal(nulls.NewUUID(u.ID), unowned.UserID)
+}
+
+func (ms *ModelSuite) Test_SignInWithOIDC() {
+	identity := OIDCIdentity{Issuer: "https://id.example.com", Subject: "42", Email: "New@Example.com", EmailVerified: true}
+
+	// The first sign in creates a User without a password
+	u, err := SignInWithOIDC(ms.DB, identity)
+	ms.NoError(err)
+	ms.Equal("new@example.com", u.Email)
+	ms.Empty(u.PasswordHash)
+	_, err = AuthenticateUser(ms.DB, "new@example.com", "")
+	ms.ErrorIs(err, ErrInvalidCredentials)
+
+	// and later ones find it
+	again, err := SignInWithOIDC(ms.DB, identity)
+	ms.NoError(err)
+	ms.Equal(u.ID, again.ID)
+
+	// An email that already has an account isn't linked to it, even when
+	// it is verified
+	reader := ms.createUser("reader@example.com")
+	readerIdentity := OIDCIdentity{Issuer: "https://id.example.com", Subject: "7", Email: "reader@example.com", EmailVerified: true}
+	_, err = SignInWithOIDC(ms.DB, readerIdentity)
+	ms.ErrorIs(err, ErrEmailTaken)
+
+	// until the account's owner links it
+	ms.NoError(LinkOIDC(ms.DB, reader, readerIdentity))
+	linked, err := SignInWithOIDC(ms.DB, readerIdentity)
+	ms.NoError(err)
+	ms.Equal(reader.ID, linked.ID)
+
+	// An identity can only be linked to one account
+	ms.ErrorIs(LinkOIDC(ms.DB, u, readerIdentity), ErrIdentityTaken)
+
+	// and no account is made for an unverified email
+	_, err = SignInWithOIDC(ms.DB, OIDCIdentity{Issuer: "https://id.example.com", Subject: "8", Email: "other@example.com"})
+	ms.ErrorIs(err, ErrEmailUnverified)
+}
+
+func (ms *ModelSuite) Test_User_Username() {
+	u := ms.createUser("reader@example.com")
+
+	u.Username = " Reader_1 "
+	verrs, err := ms.DB.ValidateAndUpdate(u)
+	ms.NoError(err)
+	ms.False(verrs.HasAny())
+	ms.Equal("reader_1", u.Username)
+
+	for _, username := range []string{"ab", "no spaces", "slash/es"} {
+		u.Username = username
+		verrs, err = ms.DB.ValidateAndUpdate(u)
+		ms.NoError(err)
+		ms.NotEmpty(verrs.Get("username"), username)
+	}
+
+	// Usernames are unique, but any number of users can go without one
+	other := ms.createUser("other@example.com")
+	other.Username = "reader_1"
+	verrs, err = ms.DB.ValidateAndUpdate(other)
+	ms.NoError(err)
+	ms.NotEmpty(verrs.Get("username"))
+
+	ms.createUser("third@example.com")
+}